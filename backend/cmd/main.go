@@ -1,27 +1,237 @@
-package main
-
-import (
-	"log/slog"
-	"net/http"
-	"os"
-
-	"github.com/fazamuttaqien/calendly/database"
-	"github.com/fazamuttaqien/calendly/internal/presenter"
-	"github.com/fazamuttaqien/calendly/internal/router"
-)
-
-func main() {
-	dbUrl := os.Getenv("POSTGRES_URL")
-	db, err := database.New(dbUrl)
-	if err != nil {
-		slog.Error("Failed to connect to database", "error", err)
-		return
-	}
-	defer db.Close()
-
-	presenter := presenter.New(db.DB)
-	router := router.New(presenter)
-
-	slog.Info("Starting server on :8000...")
-	http.ListenAndServe(":8000", router)
-}
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/presenter"
+	"github.com/fazamuttaqien/calendly/internal/router"
+	"github.com/fazamuttaqien/calendly/middleware"
+	"github.com/fazamuttaqien/calendly/pkg/mailer"
+	"github.com/fazamuttaqien/calendly/pkg/scheduler"
+	"github.com/fazamuttaqien/calendly/pkg/tracing"
+)
+
+const (
+	// How often expired entries are removed from revoked_tokens
+	revokedTokenCleanupInterval = 1 * time.Hour
+	// How often expired entries are removed from idempotency_keys
+	idempotencyKeyCleanupInterval = 1 * time.Hour
+	// How often deleted accounts past their grace period are erased
+	deletedUserCleanupInterval = 6 * time.Hour
+	// How long a deleted account is kept before its data is erased
+	deletedUserGracePeriod = 30 * 24 * time.Hour
+	// How often the database connection is checked in the background
+	dbHealthCheckInterval = 1 * time.Minute
+	// How often connection pool statistics are logged
+	dbStatsInterval = 60 * time.Second
+	// How often hosts are checked for a due daily digest, the send hour is DAILY_DIGEST_HOUR
+	dailyDigestInterval = 10 * time.Minute
+	// How long in-flight requests get to finish after a shutdown signal
+	shutdownTimeout = 30 * time.Second
+)
+
+func main() {
+	shutdownTracing, err := tracing.Init(context.Background(), "calendly-backend")
+	if err != nil {
+		slog.Error("Failed to initialize tracing", "error", err)
+		return
+	}
+
+	// Debug log of every query with its duration, off by default as it costs time on each query
+	if helper.GetEnvBool("LOG_SQL_QUERIES", false) {
+		database.SetQueryLogging(true)
+	}
+
+	dbUrl := os.Getenv("POSTGRES_URL")
+	// Optional read replica for read-heavy queries, reads go to the primary when unset
+	dbReadUrl := os.Getenv("POSTGRES_READ_URL")
+	db, err := database.New(dbUrl, dbReadUrl)
+	if err != nil {
+		slog.Error("Failed to connect to database", "error", err)
+		return
+	}
+
+	// Bring the schema up to date before serving requests
+	if err := database.RunMigrations(db.DB, database.Migrations); err != nil {
+		slog.Error("Failed to run database migrations", "error", err)
+		return
+	}
+	if err := database.EncryptLegacyTokens(db.DB); err != nil {
+		slog.Error("Failed to encrypt legacy integration tokens", "error", err)
+		return
+	}
+
+	// Keep the revoked token blacklist small
+	go purgeRevokedTokens(db, revokedTokenCleanupInterval)
+	go purgeIdempotencyKeys(db, idempotencyKeyCleanupInterval)
+	go purgeDeletedUsers(db, deletedUserCleanupInterval)
+
+	// Surface connection issues before they affect requests
+	go checkDatabaseConnection(db, dbUrl, dbReadUrl, dbHealthCheckInterval)
+	go logDatabaseStats(db, dbStatsInterval)
+
+	// Email hosts their meetings of the next day
+	digestScheduler := scheduler.New(dailyDigestInterval, func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		if err := scheduler.SendDailyDigests(ctx, db.DB, mailer.NewFromEnv()); err != nil {
+			slog.Error("Failed to send daily digests", "error", err)
+		}
+	})
+	go digestScheduler.Start()
+
+	presenter := presenter.New(db)
+	// Admin endpoints are only served to these networks, e.g. "10.0.0.0/8,192.168.1.10/32"
+	router := router.New(presenter, helper.GetEnvList("ADMIN_ALLOWED_CIDRS"))
+
+	server := &http.Server{
+		Addr:    ":8000",
+		Handler: router,
+		// The router's per-request timeout only starts once headers are read,
+		// slow clients trickling in headers (slowloris) are cut off here
+		ReadHeaderTimeout: helper.GetEnvSeconds("SERVER_READ_HEADER_TIMEOUT_SECONDS", 5*time.Second),
+		ReadTimeout:       helper.GetEnvSeconds("SERVER_READ_TIMEOUT_SECONDS", 30*time.Second),
+		// Above the longest router timeout, the data export, so slow handlers still get to write their error
+		WriteTimeout: helper.GetEnvSeconds("SERVER_WRITE_TIMEOUT_SECONDS", middleware.DataExportRequestTimeout+15*time.Second),
+		IdleTimeout:  helper.GetEnvSeconds("SERVER_IDLE_TIMEOUT_SECONDS", 120*time.Second),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server on :8000...")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		slog.Error("Server failed", "error", err)
+	case sig := <-stop:
+		slog.Info("Shutting down server", "signal", sig.String())
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		if err := server.Shutdown(ctx); err != nil {
+			slog.Error("Server did not shut down gracefully", "error", err)
+		}
+		cancel()
+	}
+
+	// Let a digest run in progress finish its current email before the database goes away
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := digestScheduler.Stop(stopCtx); err != nil {
+		slog.Error("Daily digest scheduler did not stop in time", "error", err)
+	}
+	stopCancel()
+
+	// Only close the database once in-flight requests are drained
+	if err := db.Close(); err != nil {
+		slog.Error("Failed to close database connection", "error", err)
+	}
+
+	// Flush spans recorded while draining
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	cancel()
+	slog.Info("Server stopped")
+}
+
+// checkDatabaseConnection periodically verifies the database is reachable.
+func checkDatabaseConnection(db *database.DB, url, readURL string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := db.CheckConnection(url, readURL); err != nil {
+			slog.Error("Database connection check failed", "error", err)
+		}
+	}
+}
+
+// logDatabaseStats periodically logs the connection pool statistics.
+func logDatabaseStats(db *database.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		stats := db.Stats()
+		slog.Info("Database pool stats",
+			"openConnections", stats.OpenConnections,
+			"inUse", stats.InUse,
+			"idle", stats.Idle,
+			"waitCount", stats.WaitCount,
+			"waitDuration", stats.WaitDuration.String(),
+			"maxIdleTimeClosed", stats.MaxIdleTimeClosed,
+			"maxLifetimeClosed", stats.MaxLifetimeClosed,
+		)
+	}
+}
+
+// purgeRevokedTokens periodically deletes revoked tokens past their expiry.
+func purgeRevokedTokens(db *database.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		deleted, err := db.PurgeRevokedTokens(ctx)
+		cancel()
+
+		if err != nil {
+			slog.Error("Failed to purge revoked tokens", "error", err)
+			continue
+		}
+		slog.Info("Purged expired revoked tokens", "deleted", deleted)
+	}
+}
+
+// purgeIdempotencyKeys periodically deletes stored booking responses past their 24h TTL.
+func purgeIdempotencyKeys(db *database.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		deleted, err := db.PurgeIdempotencyKeys(ctx)
+		cancel()
+
+		if err != nil {
+			slog.Error("Failed to purge idempotency keys", "error", err)
+			continue
+		}
+		slog.Info("Purged expired idempotency keys", "deleted", deleted)
+	}
+}
+
+// purgeDeletedUsers periodically erases the data of accounts deleted more than deletedUserGracePeriod ago.
+func purgeDeletedUsers(db *database.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		purged, err := db.PurgeDeletedUsers(ctx, deletedUserGracePeriod)
+		cancel()
+
+		if err != nil {
+			slog.Error("Failed to purge some deleted users", "purged", purged, "error", err)
+			continue
+		}
+		slog.Info("Purged deleted users", "purged", purged)
+	}
+}
@@ -1,155 +1,333 @@
-package database
-
-import (
-	"context"
-	"errors"
-	"log/slog"
-	"math/rand/v2"
-	"time"
-
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-)
-
-// Retry configuration parameters
-const (
-	initialBackoff = 1 * time.Second
-	maxBackoff     = 30 * time.Second
-	backoffFactor  = 2.0
-	jitterFactor   = 0.2
-)
-
-// DB represents the database connection
-type DB struct {
-	*sqlx.DB
-}
-
-// New creates a new database connection with retry mechanism
-func New(url string) (*DB, error) {
-	if url == "" {
-		return nil, errors.New("database URL must be provided")
-	}
-
-	slog.Info("Establishing database connection")
-
-	var db *sqlx.DB
-	var err error
-	attempt := 1
-	backoff := initialBackoff
-
-	for {
-		slog.Info("Attempting to connect to PostgreSQL", slog.Int("attempt", attempt))
-
-		db, err = sqlx.Connect("postgres", url)
-		if err != nil {
-			slog.Error("Failed to connect to database", slog.String("error", err.Error()), slog.Int("attempt", attempt))
-
-			time.Sleep(calculateBackoff(backoff))
-			backoff = min(time.Duration(float64(backoff)*backoffFactor), maxBackoff)
-			attempt++
-
-			continue
-		}
-
-		// Configure connection pooling
-		db.SetMaxOpenConns(25)
-		db.SetMaxIdleConns(10)
-		db.SetConnMaxLifetime(5 * time.Minute)
-		db.SetConnMaxIdleTime(1 * time.Minute)
-
-		// Test connection with timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		pingErr := db.PingContext(ctx)
-		cancel()
-
-		if pingErr != nil {
-			slog.Error("Failed to ping database", slog.String("pingErr", pingErr.Error()), slog.Int("attempt", attempt))
-
-			db.Close() // Close failed connection
-
-			time.Sleep(calculateBackoff(backoff))
-			backoff = min(time.Duration(float64(backoff)*backoffFactor), maxBackoff)
-			attempt++
-
-			continue
-		}
-
-		// Connection successful
-		slog.Info("Successfully connected to PostgreSQL", slog.Int("attempt", attempt))
-		return &DB{db}, nil
-	}
-}
-
-// calculateBackoff adds jitter to avoid the thundering herd problem
-func calculateBackoff(backoff time.Duration) time.Duration {
-	jitter := float64(backoff) * jitterFactor
-	return backoff + time.Duration(rand.Float64()*jitter)
-}
-
-// CheckConnection verifies the database connection is still alive and attempts to reconnect if needed
-func (db *DB) CheckConnection(url string) error {
-	if db.DB == nil {
-		newDB, err := New(url)
-		if err != nil {
-			return err
-		}
-		*db = *newDB
-		return nil
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	err := db.PingContext(ctx)
-	cancel()
-
-	if err != nil {
-		slog.Warn("Database connection lost, trying to reconnect", slog.String("error", err.Error()))
-		db.Close() // Close problematic connection
-
-		newDB, err := New(url)
-		if err != nil {
-			return err
-		}
-		*db = *newDB
-	}
-
-	return nil
-}
-
-// GetContext retrieves a single row and scans it into dest
-func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.DB.GetContext(ctx, dest, query, args...)
-}
-
-// SelectContext retrieves multiple rows and scans them into dest
-func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.DB.SelectContext(ctx, dest, query, args...)
-}
-
-// ExecContext executes a query without returning any rows
-// func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sqlx.Result, error) {
-// 	return db.DB.NamedExecContext(ctx, query, args...)
-// }
-
-// Transaction executes a function within a transaction
-func (db *DB) Transaction(ctx context.Context, fn func(*sqlx.Tx) error) error {
-	tx, err := db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p) // re-throw panic after rollback
-		}
-	}()
-
-	if err := fn(tx); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return errors.Join(err, rbErr)
-		}
-		return err
-	}
-
-	return tx.Commit()
-}
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/pkg/tracing"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Retry configuration parameters
+const (
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 30 * time.Second
+	backoffFactor  = 2.0
+	jitterFactor   = 0.2
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations holds the schema migrations applied on startup by RunMigrations
+var Migrations, _ = fs.Sub(embeddedMigrations, "migrations")
+
+// queryLogger writes the debug log of every query and its duration, nil when disabled, see SetQueryLogging
+var queryLogger *slog.Logger
+
+// SetQueryLogging turns query logging on or off, it must be called before the database is used.
+// Queries are logged at debug level by a logger of their own, so the level of the default logger is left as is.
+func SetQueryLogging(enabled bool) {
+	if !enabled {
+		queryLogger = nil
+		return
+	}
+	queryLogger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// DB represents the database connection
+type DB struct {
+	*sqlx.DB
+	// ReadDB serves read-only queries, it is the primary connection when no replica is configured
+	ReadDB *sqlx.DB
+}
+
+// New creates a new database connection with retry mechanism.
+// readURL optionally points at a read replica, leave it empty to read from the primary.
+func New(url, readURL string) (*DB, error) {
+	if url == "" {
+		return nil, errors.New("database URL must be provided")
+	}
+
+	slog.Info("Establishing database connection")
+	db := connect(url)
+
+	if readURL == "" {
+		return &DB{DB: db, ReadDB: db}, nil
+	}
+
+	slog.Info("Establishing read replica connection")
+	return &DB{DB: db, ReadDB: connect(readURL)}, nil
+}
+
+// connect opens a connection pool to url, retrying with backoff until the database answers
+func connect(url string) *sqlx.DB {
+	var db *sqlx.DB
+	var err error
+	attempt := 1
+	backoff := initialBackoff
+
+	for {
+		slog.Info("Attempting to connect to PostgreSQL", slog.Int("attempt", attempt))
+
+		db, err = sqlx.Connect("postgres", url)
+		if err != nil {
+			slog.Error("Failed to connect to database", slog.String("error", err.Error()), slog.Int("attempt", attempt))
+
+			time.Sleep(calculateBackoff(backoff))
+			backoff = min(time.Duration(float64(backoff)*backoffFactor), maxBackoff)
+			attempt++
+
+			continue
+		}
+
+		// Configure connection pooling
+		db.SetMaxOpenConns(helper.GetEnvInt("DB_MAX_OPEN_CONNS", 25))
+		db.SetMaxIdleConns(helper.GetEnvInt("DB_MAX_IDLE_CONNS", 10))
+		db.SetConnMaxLifetime(helper.GetEnvSeconds("DB_CONN_MAX_LIFETIME_SECONDS", 5*time.Minute))
+		db.SetConnMaxIdleTime(helper.GetEnvSeconds("DB_CONN_MAX_IDLE_TIME_SECONDS", 1*time.Minute))
+
+		// Test connection with timeout
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		pingErr := db.PingContext(ctx)
+		cancel()
+
+		if pingErr != nil {
+			slog.Error("Failed to ping database", slog.String("pingErr", pingErr.Error()), slog.Int("attempt", attempt))
+
+			db.Close() // Close failed connection
+
+			time.Sleep(calculateBackoff(backoff))
+			backoff = min(time.Duration(float64(backoff)*backoffFactor), maxBackoff)
+			attempt++
+
+			continue
+		}
+
+		// Connection successful
+		slog.Info("Successfully connected to PostgreSQL", slog.Int("attempt", attempt))
+		return db
+	}
+}
+
+// calculateBackoff adds jitter to avoid the thundering herd problem
+func calculateBackoff(backoff time.Duration) time.Duration {
+	jitter := float64(backoff) * jitterFactor
+	return backoff + time.Duration(rand.Float64()*jitter)
+}
+
+// Reader returns the database to run read-only queries on, the read replica when one is configured.
+// Writes through it fail on a replica, so it must only be used for reads.
+func (db *DB) Reader() *DB {
+	return &DB{DB: db.ReadDB, ReadDB: db.ReadDB}
+}
+
+// Close closes the primary connection and the read replica connection, if any
+func (db *DB) Close() error {
+	var replicaErr error
+	if db.ReadDB != nil && db.ReadDB != db.DB {
+		replicaErr = db.ReadDB.Close()
+	}
+	return errors.Join(db.DB.Close(), replicaErr)
+}
+
+// CheckConnection verifies the database connection is still alive, connecting first if there is none yet
+func (db *DB) CheckConnection(url, readURL string) error {
+	if db.DB == nil {
+		newDB, err := New(url, readURL)
+		if err != nil {
+			return err
+		}
+		*db = *newDB
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	err := db.PingContext(ctx)
+	cancel()
+
+	if err != nil {
+		// The pool is shared with the handlers and re-dials on its own,
+		// so report the failure instead of closing and replacing it
+		slog.Warn("Database connection check failed", slog.String("error", err.Error()))
+		return err
+	}
+
+	return nil
+}
+
+// Stats returns the connection pool statistics
+func (db *DB) Stats() sql.DBStats {
+	return db.DB.Stats()
+}
+
+// GetContext retrieves a single row and scans it into dest
+func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "db.get", dbSpanAttributes(query)...)
+	defer func() { tracing.End(span, ignoreNoRows(err)) }()
+	defer logQuery(ctx, "db.query", query, time.Now())
+
+	return db.DB.GetContext(ctx, dest, query, args...)
+}
+
+// SelectContext retrieves multiple rows and scans them into dest
+func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "db.select", dbSpanAttributes(query)...)
+	defer func() { tracing.End(span, ignoreNoRows(err)) }()
+	defer logQuery(ctx, "db.query", query, time.Now())
+
+	return db.DB.SelectContext(ctx, dest, query, args...)
+}
+
+// dbSpanAttributes describes a query on its span, arguments are left out as they may hold user data
+func dbSpanAttributes(query string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", strings.TrimSpace(query)),
+	}
+}
+
+// logQuery writes a debug record of a query that started at start when query logging is enabled.
+// The request and trace IDs of ctx tie the query to the request that issued it.
+func logQuery(ctx context.Context, msg, query string, start time.Time) {
+	if queryLogger == nil {
+		return
+	}
+
+	attrs := []any{"duration_ms", time.Since(start).Milliseconds()}
+	if query != "" {
+		attrs = append(attrs, "sql", strings.TrimSpace(query))
+	}
+	if requestID := chiMiddleware.GetReqID(ctx); requestID != "" {
+		attrs = append(attrs, "request_id", requestID)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		attrs = append(attrs, "trace_id", spanContext.TraceID().String())
+	}
+	queryLogger.DebugContext(ctx, msg, attrs...)
+}
+
+// ignoreNoRows keeps "not found" lookups from being reported as failed spans
+func ignoreNoRows(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+// ExecContext executes a query without returning any rows
+// func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sqlx.Result, error) {
+// 	return db.DB.NamedExecContext(ctx, query, args...)
+// }
+
+// Transaction executes a function within a transaction
+func (db *DB) Transaction(ctx context.Context, fn func(*sqlx.Tx) error) error {
+	defer logQuery(ctx, "db.transaction", "", time.Now())
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after rollback
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PurgeRevokedTokens deletes revoked tokens that have expired anyway and returns how many were removed
+func (db *DB) PurgeRevokedTokens(ctx context.Context) (int64, error) {
+	result, err := db.DB.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// userErasureQueries remove every row of a user, dependents before the rows they reference.
+// Each takes the user ID as $1.
+var userErasureQueries = []string{
+	`DELETE FROM booking_answers WHERE meeting_id IN (SELECT m.id FROM meetings m JOIN events e ON m.event_id = e.id WHERE e.user_id = $1);`,
+	`DELETE FROM meetings WHERE event_id IN (SELECT id FROM events WHERE user_id = $1);`,
+	`DELETE FROM idempotency_keys WHERE split_part(key, ':', 1) IN (SELECT id::TEXT FROM events WHERE user_id = $1);`,
+	`DELETE FROM event_questions WHERE event_id IN (SELECT id FROM events WHERE user_id = $1);`,
+	`DELETE FROM waitlist WHERE event_id IN (SELECT id FROM events WHERE user_id = $1);`,
+	`DELETE FROM events WHERE user_id = $1;`,
+	`DELETE FROM day_availability WHERE availability_id IN (SELECT id FROM availability WHERE user_id = $1);`,
+	`DELETE FROM availability_exceptions WHERE availability_id IN (SELECT id FROM availability WHERE user_id = $1);`,
+	`DELETE FROM availability WHERE user_id = $1;`,
+	`DELETE FROM vacation_blocks WHERE user_id = $1;`,
+	`DELETE FROM webhooks WHERE user_id = $1;`,
+	`DELETE FROM slack_webhooks WHERE user_id = $1;`,
+	`DELETE FROM organization_invitations WHERE invited_by = $1;`,
+	`DELETE FROM round_robin_event_hosts WHERE user_id = $1;`,
+	`DELETE FROM organization_members WHERE user_id = $1;`,
+	`DELETE FROM organizations WHERE owner_id = $1;`,
+	`DELETE FROM integrations WHERE user_id = $1;`,
+	`DELETE FROM audit_logs WHERE user_id = $1;`,
+	`DELETE FROM users WHERE id = $1;`,
+}
+
+// PurgeDeletedUsers erases accounts soft deleted more than gracePeriod ago and returns how many were removed.
+// Each account is erased in its own transaction, so one failure does not hold back the others.
+func (db *DB) PurgeDeletedUsers(ctx context.Context, gracePeriod time.Duration) (int64, error) {
+	var userIDs []string
+	query := `SELECT id FROM users WHERE deleted_at IS NOT NULL AND deleted_at < $1;`
+	if err := db.DB.SelectContext(ctx, &userIDs, query, time.Now().Add(-gracePeriod)); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	var errs []error
+	for _, userID := range userIDs {
+		err := db.Transaction(ctx, func(tx *sqlx.Tx) error {
+			for _, query := range userErasureQueries {
+				if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// PurgeIdempotencyKeys deletes idempotency keys past their expiry and returns how many were removed
+func (db *DB) PurgeIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := db.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fazamuttaqien/calendly/pkg/crypto"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// migrationLockID serializes migration runs of several instances starting at once
+const migrationLockID = 7_206_301
+
+// migration is a single up-migration file named "<version>_<description>.sql"
+type migration struct {
+	version int
+	name    string
+}
+
+// RunMigrations applies the SQL files of migrationsDir that are not yet recorded in
+// schema_migrations, in version order. Each file runs in its own transaction together
+// with its schema_migrations row, so already-applied versions are skipped on the next run.
+func RunMigrations(db *sqlx.DB, migrationsDir fs.FS) error {
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INT PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	migrations, err := listMigrations(migrationsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		applied, err := applyMigration(ctx, db, migrationsDir, m)
+		if err != nil {
+			return err
+		}
+		if applied {
+			slog.Info("Applied database migration", slog.Int("version", m.version), slog.String("file", m.name))
+		}
+	}
+
+	return nil
+}
+
+// EncryptLegacyTokens encrypts integration tokens stored in plaintext before token encryption
+// was introduced, so every token can be read with crypto.DecryptString. Already encrypted rows
+// are left alone, which makes it safe to run on every startup.
+func EncryptLegacyTokens(db *sqlx.DB) error {
+	ctx := context.Background()
+
+	// Only rows that are not standard base64 can hold plaintext, crypto.IsEncrypted has the final say
+	var rows []struct {
+		ID           string         `db:"id"`
+		AccessToken  sql.NullString `db:"access_token"`
+		RefreshToken sql.NullString `db:"refresh_token"`
+	}
+	query := `
+		SELECT id, access_token, refresh_token FROM integrations
+		WHERE access_token !~ '^[A-Za-z0-9+/]*={0,2}$' OR refresh_token !~ '^[A-Za-z0-9+/]*={0,2}$';
+	`
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return fmt.Errorf("failed to find plaintext integration tokens: %w", err)
+	}
+
+	encrypt := func(token sql.NullString) (sql.NullString, error) {
+		if !token.Valid || token.String == "" || crypto.IsEncrypted(token.String) {
+			return token, nil
+		}
+		encrypted, err := crypto.EncryptString(token.String)
+		return sql.NullString{String: encrypted, Valid: true}, err
+	}
+
+	for _, row := range rows {
+		accessToken, err := encrypt(row.AccessToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt access token of integration %s: %w", row.ID, err)
+		}
+		refreshToken, err := encrypt(row.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt refresh token of integration %s: %w", row.ID, err)
+		}
+
+		// Matching on the old values skips rows a concurrent token refresh already rewrote
+		update := `
+			UPDATE integrations SET access_token = $1, refresh_token = $2
+			WHERE id = $3 AND access_token IS NOT DISTINCT FROM $4 AND refresh_token IS NOT DISTINCT FROM $5;
+		`
+		_, err = db.ExecContext(ctx, update, accessToken, refreshToken, row.ID, row.AccessToken, row.RefreshToken)
+		if err != nil {
+			return fmt.Errorf("failed to store encrypted tokens of integration %s: %w", row.ID, err)
+		}
+	}
+
+	if len(rows) > 0 {
+		slog.Info("Encrypted plaintext integration tokens", slog.Int("integrations", len(rows)))
+	}
+	return nil
+}
+
+// listMigrations returns the migration files of fsys sorted by version
+func listMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	migrations := make([]migration, 0, len(names))
+	seen := make(map[int]string, len(names))
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(path.Base(name), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s does not start with a numeric version", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, name, version)
+		}
+		seen[version] = name
+		migrations = append(migrations, migration{version: version, name: name})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// applyMigration runs m unless its version is already recorded and reports whether it ran
+func applyMigration(ctx context.Context, db *sqlx.DB, fsys fs.FS, m migration) (bool, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// Held until the transaction ends, another instance waits here and then sees the version applied
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1);", migrationLockID); err != nil {
+		return false, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	var applied bool
+	err = tx.GetContext(ctx, &applied, "SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1);", m.version)
+	if err != nil {
+		return false, fmt.Errorf("failed to check migration %d: %w", m.version, err)
+	}
+	if applied {
+		return false, nil
+	}
+
+	script, err := fs.ReadFile(fsys, m.name)
+	if err != nil {
+		return false, fmt.Errorf("failed to read migration %s: %w", m.name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return false, fmt.Errorf("failed to apply migration %s: %w", m.name, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1);", m.version); err != nil {
+		return false, fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+-- Baseline schema. IF NOT EXISTS keeps it safe to apply on databases created before migrations were tracked,
+-- everything added to the schema since then lives in later migrations.
+
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+CREATE TABLE IF NOT EXISTS users (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name       VARCHAR(255) NOT NULL,
+    username   VARCHAR(255) NOT NULL UNIQUE,
+    email      VARCHAR(255) NOT NULL UNIQUE,
+    password   VARCHAR(255) NOT NULL,
+    image_url  TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS availability (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    time_gap   INT NOT NULL DEFAULT 30,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS day_availability (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    availability_id UUID NOT NULL REFERENCES availability (id) ON DELETE CASCADE,
+    day             VARCHAR(10) NOT NULL,
+    start_time      TIME NOT NULL,
+    end_time        TIME NOT NULL,
+    is_available    BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS events (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    title         VARCHAR(255) NOT NULL,
+    description   TEXT,
+    duration      INT NOT NULL DEFAULT 30,
+    slug          VARCHAR(255) NOT NULL,
+    is_private    BOOLEAN NOT NULL DEFAULT FALSE,
+    location_type VARCHAR(50) NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS meetings (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id           UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_id          UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    guest_name        VARCHAR(255) NOT NULL,
+    guest_email       VARCHAR(255) NOT NULL,
+    additional_info   TEXT,
+    start_time        TIMESTAMPTZ NOT NULL,
+    end_time          TIMESTAMPTZ NOT NULL,
+    meet_link         TEXT NOT NULL DEFAULT '',
+    calendar_event_id VARCHAR(255) NOT NULL DEFAULT '',
+    calendar_app_type VARCHAR(50) NOT NULL DEFAULT '',
+    status            VARCHAR(20) NOT NULL DEFAULT 'SCHEDULED',
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS integrations (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      VARCHAR(50) NOT NULL,
+    category      VARCHAR(50) NOT NULL,
+    app_type      VARCHAR(50) NOT NULL,
+    access_token  TEXT,
+    refresh_token TEXT,
+    expiry_date   BIGINT,
+    metadata      JSONB NOT NULL DEFAULT '{}',
+    is_connected  BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
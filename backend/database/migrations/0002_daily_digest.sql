@@ -0,0 +1,5 @@
+-- Daily digest of the next day's meetings, sent in the host's timezone unless they opted out.
+-- last_digest_sent_on keeps a host from getting the same day's digest twice.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS digest_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_digest_sent_on DATE;
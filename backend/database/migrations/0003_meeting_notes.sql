@@ -0,0 +1,2 @@
+-- Internal notes of the host about a booking, never shown to the guest
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS notes TEXT;
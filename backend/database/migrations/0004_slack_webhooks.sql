@@ -0,0 +1,9 @@
+-- Slack incoming webhook of a host, notified when meetings are booked or cancelled
+CREATE TABLE IF NOT EXISTS slack_webhooks (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id          UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    webhook_url      TEXT NOT NULL,
+    notify_on_create BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_on_cancel BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
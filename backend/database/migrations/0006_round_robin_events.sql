@@ -0,0 +1,15 @@
+-- Round-robin events spread the bookings of an organization event across several hosts
+CREATE TABLE IF NOT EXISTS round_robin_events (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id     UUID NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    event_id   UUID NOT NULL UNIQUE REFERENCES events (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Hosts taking turns on a round-robin event, last_assigned_at breaks ties between equally busy hosts
+CREATE TABLE IF NOT EXISTS round_robin_event_hosts (
+    round_robin_event_id UUID NOT NULL REFERENCES round_robin_events (id) ON DELETE CASCADE,
+    user_id              UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    last_assigned_at     TIMESTAMPTZ,
+    PRIMARY KEY (round_robin_event_id, user_id)
+);
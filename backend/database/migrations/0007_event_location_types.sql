@@ -0,0 +1,12 @@
+-- Events can offer several location types, the guest picks one of them when booking
+ALTER TABLE events ADD COLUMN IF NOT EXISTS location_types TEXT[];
+UPDATE events SET location_types = ARRAY[location_type] WHERE location_types IS NULL;
+ALTER TABLE events ALTER COLUMN location_types SET NOT NULL;
+ALTER TABLE events ADD CONSTRAINT events_location_types_not_empty CHECK (cardinality(location_types) >= 1);
+
+-- The location type chosen for a meeting, existing meetings used the only type their event had
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS location_type VARCHAR(50);
+UPDATE meetings m SET location_type = e.location_type FROM events e WHERE m.event_id = e.id AND m.location_type IS NULL;
+ALTER TABLE meetings ALTER COLUMN location_type SET NOT NULL;
+
+ALTER TABLE events DROP COLUMN IF EXISTS location_type;
@@ -0,0 +1,6 @@
+-- PKCE code verifiers of pending integration connections, keyed by a hash of the OAuth state
+CREATE TABLE IF NOT EXISTS oauth_pkce_verifiers (
+    state_hash    VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,4 @@
+-- Slugs an event had before its slug was regenerated, so shared links keep resolving
+ALTER TABLE events ADD COLUMN IF NOT EXISTS slug_aliases TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_events_slug_aliases ON events USING GIN (slug_aliases);
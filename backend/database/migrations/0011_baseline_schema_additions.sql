@@ -0,0 +1,121 @@
+-- Columns, tables and constraints added to the baseline schema before migrations were tracked.
+-- ADD COLUMN IF NOT EXISTS brings databases created from the old baseline up to date.
+
+-- Users
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS google_id VARCHAR(255) UNIQUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_invalid_before TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Availability overrides
+CREATE TABLE IF NOT EXISTS availability_exceptions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    availability_id UUID NOT NULL REFERENCES availability (id) ON DELETE CASCADE,
+    exception_date  DATE NOT NULL,
+    reason          VARCHAR(255),
+    is_available    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (availability_id, exception_date)
+);
+
+CREATE TABLE IF NOT EXISTS vacation_blocks (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    reason     VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+-- Events
+ALTER TABLE events ADD COLUMN IF NOT EXISTS color VARCHAR(20) NOT NULL DEFAULT 'BLUE';
+ALTER TABLE events ADD COLUMN IF NOT EXISTS max_bookings INT;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS enable_waitlist BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS min_notice_hours INT NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS max_notice_days INT NOT NULL DEFAULT 60;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- Postgres has no ADD CONSTRAINT IF NOT EXISTS
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'events_user_id_slug_key') THEN
+        ALTER TABLE events ADD CONSTRAINT events_user_id_slug_key UNIQUE (user_id, slug);
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_events_search ON events
+USING GIN (to_tsvector('english', title || ' ' || COALESCE(description, '')));
+
+CREATE TABLE IF NOT EXISTS event_questions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id    UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    label       VARCHAR(255) NOT NULL,
+    field_type  VARCHAR(20) NOT NULL,
+    options     JSONB,
+    is_required BOOLEAN NOT NULL DEFAULT FALSE,
+    sort_order  INT NOT NULL DEFAULT 0
+);
+
+-- Meetings, the volatile default gives every existing meeting its own cancellation token
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE meetings ADD COLUMN IF NOT EXISTS cancellation_token UUID NOT NULL UNIQUE DEFAULT gen_random_uuid();
+
+CREATE INDEX IF NOT EXISTS idx_meetings_user_start ON meetings (user_id, start_time);
+CREATE INDEX IF NOT EXISTS idx_meetings_event ON meetings (event_id);
+
+CREATE TABLE IF NOT EXISTS booking_answers (
+    meeting_id  UUID NOT NULL REFERENCES meetings (id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES event_questions (id) ON DELETE CASCADE,
+    answer      TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (meeting_id, question_id)
+);
+
+CREATE TABLE IF NOT EXISTS waitlist (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id        UUID NOT NULL REFERENCES events (id) ON DELETE CASCADE,
+    guest_name      VARCHAR(255) NOT NULL,
+    guest_email     VARCHAR(255) NOT NULL,
+    additional_info TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    notified_at     TIMESTAMPTZ,
+    UNIQUE (event_id, guest_email)
+);
+
+-- Outgoing webhooks, auditing and token bookkeeping
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url        TEXT NOT NULL,
+    events     TEXT[] NOT NULL,
+    secret     VARCHAR(255) NOT NULL,
+    is_active  BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID REFERENCES users (id) ON DELETE SET NULL,
+    action      VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id   VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address  VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT '',
+    metadata    JSONB,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_created ON audit_logs (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti        VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           VARCHAR(255) PRIMARY KEY,
+    response_body JSONB NOT NULL,
+    status_code   INT NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Questions removed from an event are archived once guests answered them,
+-- deleting them would cascade to the answers of past bookings
+ALTER TABLE event_questions ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
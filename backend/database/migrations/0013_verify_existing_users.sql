@@ -0,0 +1,3 @@
+-- Accounts created before email verification existed never got a verification email,
+-- they are treated as verified so they can still log in
+UPDATE users SET is_verified = TRUE WHERE is_verified = FALSE;
@@ -0,0 +1,13 @@
+-- Reconnecting used to insert another row, keep the most recently updated one per app
+DELETE FROM integrations i
+USING integrations newer
+WHERE i.user_id = newer.user_id
+  AND i.app_type = newer.app_type
+  AND (i.updated_at, i.id) < (newer.updated_at, newer.id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'integrations_user_id_app_type_key') THEN
+        ALTER TABLE integrations ADD CONSTRAINT integrations_user_id_app_type_key UNIQUE (user_id, app_type);
+    END IF;
+END $$;
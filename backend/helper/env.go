@@ -0,0 +1,92 @@
+package helper
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetEnv returns the value of the environment variable key, or fallback when it is unset or empty.
+func GetEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// GetEnvSeconds reads key as a whole number of seconds, falling back when it is unset or invalid.
+func GetEnvSeconds(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		slog.Warn("Invalid duration in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// GetEnvInt reads key as an integer, falling back when it is unset or invalid.
+func GetEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return n
+}
+
+// GetEnvTime reads key as an RFC 3339 timestamp, falling back when it is unset or invalid.
+func GetEnvTime(key string, fallback time.Time) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		slog.Warn("Invalid timestamp in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return t
+}
+
+// GetEnvBool reads key as a boolean (1, t, true, 0, f, false, ...), falling back when it is unset or invalid.
+func GetEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		slog.Warn("Invalid boolean in environment, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+
+	return b
+}
+
+// GetEnvList reads key as a comma-separated list, trimming entries and dropping empty ones.
+func GetEnvList(key string) []string {
+	var list []string
+	for item := range strings.SplitSeq(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,85 @@
+package helper
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ParseFields returns the JSON keys requested through ?fields=a,b,c, nil when the parameter is absent.
+func ParseFields(r *http.Request) []string {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil
+	}
+
+	var fields []string
+	for field := range strings.SplitSeq(raw, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// FilterFields returns the JSON keys of the struct (or map with string keys) data, keeping only those in fields.
+// Keys are taken from the json tags, fields of embedded structs are promoted like encoding/json does.
+// An empty fields list keeps every key, unknown names are ignored.
+func FilterFields(data any, fields []string) map[string]any {
+	keep := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		keep[field] = true
+	}
+
+	result := make(map[string]any)
+	collectFields(reflect.ValueOf(data), keep, result)
+	return result
+}
+
+// collectFields adds the JSON keys of v that are in keep (or all when keep is empty) to result.
+func collectFields(v reflect.Value, keep map[string]bool, result map[string]any) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if key := iter.Key().String(); len(keep) == 0 || keep[key] {
+				result[key] = iter.Value().Interface()
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			sf := t.Field(i)
+			tag := sf.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+
+			// Untagged embedded structs have their fields promoted
+			if sf.Anonymous && name == "" {
+				collectFields(v.Field(i), keep, result)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = sf.Name
+			}
+			if len(keep) == 0 || keep[name] {
+				result[name] = v.Field(i).Interface()
+			}
+		}
+	}
+}
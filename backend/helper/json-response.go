@@ -1,40 +1,159 @@
-package helper
-
-import (
-	"encoding/json"
-	"log"
-	"net/http"
-)
-
-// ErrorResponse defines the standard JSON error structure.
-type ErrorResponse struct {
-	Error  string `json:"error"`
-	Detail any    `json:"detail,omitempty"`
-}
-
-func ResponseJson(w http.ResponseWriter, code int, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	if data != nil {
-		if err := json.NewEncoder(w).Encode(data); err != nil {
-			log.Fatalf("Error encoding JSON response: %v\n", err)
-		}
-	}
-}
-
-func ResponseErrorJson(w http.ResponseWriter, code int, message string, detail any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	response := ErrorResponse{Error: message}
-	if detail != nil {
-		response.Detail = detail
-	}
-	if encodeErr := json.NewEncoder(w).Encode(response); encodeErr != nil {
-		log.Fatalf("Error encoding JSON error response: %v\n", encodeErr)
-	}
-}
-
-// SimpleMessage is a basic struct for simple JSON responses.
-type SimpleMessage struct {
-	Message string `json:"message"`
-}
+package helper
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ErrorResponse defines the standard JSON error structure.
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"` // Machine-readable application error code, e.g. RESOURCE_NOT_FOUND
+	Detail    any    `json:"detail,omitempty"`
+	RequestID string `json:"requestId,omitempty"` // Correlates the response with the server logs
+}
+
+func ResponseJson(w http.ResponseWriter, code int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if data != nil {
+		if err := json.NewEncoder(w).Encode(data); err != nil {
+			slog.Error("Failed to encode JSON response", "error", err)
+		}
+	}
+}
+
+func ResponseErrorJson(w http.ResponseWriter, code int, message string, detail any) {
+	ResponseErrorJsonWithRequestID(w, code, message, detail, "")
+}
+
+// ResponseErrorJsonWithRequestID writes an error response carrying requestID, omitted when empty.
+func ResponseErrorJsonWithRequestID(w http.ResponseWriter, code int, message string, detail any, requestID string) {
+	ResponseErrorJsonWithCode(w, code, "", message, detail, requestID)
+}
+
+// ResponseErrorJsonWithCode writes an error response carrying the application errorCode and requestID,
+// each omitted when empty.
+func ResponseErrorJsonWithCode(w http.ResponseWriter, code int, errorCode string, message string, detail any, requestID string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	response := ErrorResponse{Error: message, Code: errorCode, RequestID: requestID}
+	if detail != nil {
+		response.Detail = detail
+	}
+	if encodeErr := json.NewEncoder(w).Encode(response); encodeErr != nil {
+		slog.Error("Failed to encode JSON error response", "error", encodeErr)
+	}
+}
+
+// SimpleMessage is a basic struct for simple JSON responses.
+type SimpleMessage struct {
+	Message string `json:"message"`
+}
+
+// Pagination describes which slice of a collection a list response contains.
+type Pagination struct {
+	Total      int     `json:"total"`
+	Limit      int     `json:"limit"`
+	Offset     int     `json:"offset"`
+	NextCursor *string `json:"nextCursor,omitempty"` // Set by keyset-paginated endpoints only
+}
+
+// PaginationLinks are absolute URLs of the current, next and previous pages, nil where there is no such page.
+type PaginationLinks struct {
+	Self *string `json:"self"`
+	Next *string `json:"next"`
+	Prev *string `json:"prev"`
+}
+
+// PaginatedResponse is the standard envelope for list endpoints.
+type PaginatedResponse[T any] struct {
+	Message    string           `json:"message"`
+	Data       []T              `json:"data"`
+	Pagination Pagination       `json:"pagination"`
+	Links      *PaginationLinks `json:"links,omitempty"`
+}
+
+// BuildPaginationLinks returns the links of an offset-paginated list, keeping the other query parameters of r.
+func BuildPaginationLinks(r *http.Request, limit, offset, total int) PaginationLinks {
+	links := PaginationLinks{Self: pageURL(r, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(offset),
+	})}
+	if limit <= 0 {
+		return links
+	}
+
+	if offset+limit < total {
+		links.Next = pageURL(r, map[string]string{
+			"limit":  strconv.Itoa(limit),
+			"offset": strconv.Itoa(offset + limit),
+		})
+	}
+	if offset > 0 {
+		links.Prev = pageURL(r, map[string]string{
+			"limit":  strconv.Itoa(limit),
+			"offset": strconv.Itoa(max(offset-limit, 0)),
+		})
+	}
+	return links
+}
+
+// BuildCursorLinks returns the links of a keyset-paginated list. Cursors only lead forward, so Prev is always nil.
+func BuildCursorLinks(r *http.Request, nextCursor *string) PaginationLinks {
+	links := PaginationLinks{Self: pageURL(r, nil)}
+	if nextCursor != nil {
+		links.Next = pageURL(r, map[string]string{"cursor": *nextCursor})
+	}
+	return links
+}
+
+// pageURL rebuilds the absolute URL of r with the given query parameters replaced.
+func pageURL(r *http.Request, params map[string]string) *string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	// Behind a TLS-terminating proxy the request itself arrives over plain HTTP
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+
+	query := r.URL.Query()
+	for key, value := range params {
+		query.Set(key, value)
+	}
+
+	u := url.URL{
+		Scheme:   scheme,
+		Host:     r.Host,
+		Path:     r.URL.Path,
+		RawQuery: query.Encode(),
+	}
+	link := u.String()
+	return &link
+}
+
+func ResponsePaginated[T any](w http.ResponseWriter, code int, message string, data []T, total, limit, offset int) {
+	ResponseJson(w, code, NewPaginatedResponse(message, data, total, limit, offset))
+}
+
+// NewPaginatedResponse builds the envelope so callers can set extra pagination fields before writing it.
+func NewPaginatedResponse[T any](message string, data []T, total, limit, offset int) PaginatedResponse[T] {
+	if data == nil {
+		// Encode empty lists as [] rather than null
+		data = []T{}
+	}
+	return PaginatedResponse[T]{
+		Message: message,
+		Data:    data,
+		Pagination: Pagination{
+			Total:  total,
+			Limit:  limit,
+			Offset: offset,
+		},
+	}
+}
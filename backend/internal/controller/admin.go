@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/go-chi/chi/v5"
+)
+
+// adminUserSortColumns maps the allowed sort_by values of ListUsers to SQL columns.
+var adminUserSortColumns = map[string]string{
+	"created_at": "created_at",
+	"name":       "name",
+	"email":      "email",
+	"username":   "username",
+}
+
+// GET /admin/users
+func (a *Controller) ListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.AdminUserListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	limit := dto.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// 1. Build optional search filter
+	whereClause := ""
+	args := []any{}
+	if dto.Q != "" {
+		args = append(args, "%"+escapeLikePattern(dto.Q)+"%")
+		whereClause = fmt.Sprintf(" WHERE email ILIKE $%[1]d OR name ILIKE $%[1]d OR username ILIKE $%[1]d", len(args))
+	}
+
+	// 2. Count total matches
+	var total int
+	countQuery := "SELECT COUNT(*) FROM users" + whereClause + ";"
+	if err := a.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count users", err))
+		return
+	}
+
+	// Column and direction come from allowlists, never from the raw query string
+	orderColumn, ok := adminUserSortColumns[dto.SortBy]
+	if !ok {
+		orderColumn = adminUserSortColumns["created_at"]
+	}
+	sortOrder := "DESC"
+	if dto.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+
+	// 3. Fetch the requested page
+	users := []model.User{}
+	pageArgs := append(args, limit, dto.Offset)
+	listQuery := `
+		SELECT id, name, email, username, image_url, is_verified, role, created_at, updated_at
+		FROM users` + whereClause +
+		fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d;", orderColumn, sortOrder, sortOrder, len(pageArgs)-1, len(pageArgs))
+
+	if err := a.db.SelectContext(ctx, &users, listQuery, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to list users", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Users fetched successfully",
+		"users":   users,
+		"pagination": map[string]any{
+			"limit":  limit,
+			"offset": dto.Offset,
+			"total":  total,
+		},
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /admin/users/{userId}/role
+func (a *Controller) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	adminID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateUserRoleDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	userID := chi.URLParam(r, "userId")
+	if userID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing userId in path", nil))
+		return
+	}
+
+	// Keeps at least the acting admin in place
+	if userID == adminID && dto.Role != enum.RoleAdmin {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Admins cannot demote themselves", nil))
+		return
+	}
+
+	// Existing tokens carry the old role claim, so they are revoked
+	var user model.User
+	query := `
+		UPDATE users
+		SET role = $1, tokens_invalid_before = date_trunc('second', NOW()), updated_at = NOW()
+		WHERE id = $2
+		RETURNING id, name, email, username, image_url, is_verified, role, created_at, updated_at;
+	`
+	err := a.db.GetContext(ctx, &user, query, dto.Role, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("User", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update user role", err))
+		return
+	}
+
+	a.recordAudit(r, audit.AuditEntry{
+		UserID:     adminID,
+		Action:     audit.ActionUserRoleChange,
+		EntityType: audit.EntityUser,
+		EntityID:   user.ID,
+		Metadata:   map[string]any{"role": user.Role},
+	})
+
+	response := map[string]any{
+		"message": "User role updated successfully",
+		"user":    user,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+)
+
+// GET /admin/audit-logs
+func (a *Controller) GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.AuditLogQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	page := dto.Page
+	if page == 0 {
+		page = 1
+	}
+	limit := dto.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	// 1. Build optional filters
+	whereClause := " WHERE 1 = 1"
+	args := []any{}
+
+	if dto.UserID != "" {
+		args = append(args, dto.UserID)
+		whereClause += fmt.Sprintf(" AND user_id = $%d", len(args))
+	}
+	if dto.Action != "" {
+		args = append(args, dto.Action)
+		whereClause += fmt.Sprintf(" AND action = $%d", len(args))
+	}
+
+	// 2. Count total matches for pagination
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_logs" + whereClause + ";"
+	if err := a.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count audit logs", err))
+		return
+	}
+
+	// 3. Fetch the requested page, newest first
+	logs := []model.AuditLog{}
+	pageArgs := append(args, limit, (page-1)*limit)
+	listQuery := `
+		SELECT id, user_id, action, entity_type, entity_id, ip_address, user_agent, metadata, created_at
+		FROM audit_logs` + whereClause +
+		fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d;", len(pageArgs)-1, len(pageArgs))
+
+	if err := a.db.SelectContext(ctx, &logs, listQuery, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch audit logs", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":   "Audit logs fetched successfully",
+		"auditLogs": logs,
+		"pagination": map[string]any{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// recordAudit writes an audit entry for a successful write. Failures are logged
+// and never fail the request that triggered them.
+func (a *Controller) recordAudit(r *http.Request, entry audit.AuditEntry) {
+	entry.IPAddress, entry.UserAgent = audit.FromRequest(r)
+
+	if err := audit.Log(r.Context(), a.db.DB, entry); err != nil {
+		log.Printf("Warning: Failed to write audit log (Action: %s): %v\n", entry.Action, err)
+	}
+}
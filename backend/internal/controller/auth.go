@@ -1,234 +1,574 @@
-package controller
-
-import (
-	"context"
-	"database/sql"
-	"net/http"
-	"regexp"
-	"strings"
-
-	"github.com/fazamuttaqien/calendly/helper"
-	"github.com/fazamuttaqien/calendly/internal/dto"
-	"github.com/fazamuttaqien/calendly/internal/model"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	pkgJwt "github.com/fazamuttaqien/calendly/pkg/jwt"
-	"github.com/fazamuttaqien/calendly/pkg/validator"
-	"github.com/google/uuid"
-)
-
-// POST /auth/register
-func (h *Controller) Register(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	dto, ok := validator.GetValidatedDTOFromContext[dto.RegisterDto](ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
-		return
-	}
-
-	// 1. Check if user already exists
-	var exists bool
-	err := h.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)", dto.Email)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check user existence", err))
-		return
-	}
-	if exists {
-		appError.WriteError(w, appError.NewAppError(enum.AuthEmailAlreadyExists, "User with this email already exists", nil))
-		return
-	}
-
-	// 2. Hash password
-	hashedPassword, err := helper.HashPassword(dto.Password)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to hash password", err))
-		return
-	}
-
-	// 3. Generate unique username
-	username, err := h.generateUsername(ctx, dto.Name)
-	if err != nil {
-		appError.WriteError(w, err)
-		return
-	}
-
-	// 4. Start Transaction
-	tx, err := h.db.BeginTxx(ctx, nil)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
-		return
-	}
-	// Defer rollback/commit logic
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				err = appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err)
-			}
-		}
-	}()
-
-	// 5. Insert User
-	var createdUser model.User
-	userInsertQuery := `
-		INSERT INTO users (name, email, username, password, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
-		RETURNING id, name, email, username, image_url, created_at, updated_at; -- Do NOT return password hash
-	`
-
-	if err := tx.GetContext(ctx, &createdUser, userInsertQuery, dto.Name, dto.Email, username, hashedPassword); err != nil {
-		// Could check for unique constraint violation on username if generateUsername had race condition
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert user", err))
-		return
-	}
-
-	// 6. Create Availability Record
-	var availabilityID string
-	availInsertQuery := `
-		INSERT INTO availability (user_id, time_gap, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW()) RETURNING id;
-	`
-	err = tx.GetContext(ctx, &availabilityID, availInsertQuery, createdUser.ID, 30) // Default timeGap = 30
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability", err))
-		return
-	}
-
-	// 7. Prepare and Insert Default DayAvailability Records
-	dayInserts := make([]map[string]interface{}, 0, len(enum.AllDayOfWeek()))
-	defaultStartTime := "09:00:00" // Default 9 AM
-	defaultEndTime := "17:00:00"   // Default 5 PM
-
-	for _, day := range enum.AllDayOfWeek() {
-		isAvailable := (day != enum.Sunday && day != enum.Saturday) // Not available on weekends
-		dayInserts = append(dayInserts, map[string]any{
-			"availability_id": availabilityID,
-			"day":             day,
-			"start_time":      defaultStartTime,
-			"end_time":        defaultEndTime,
-			"is_available":    isAvailable,
-		})
-	}
-
-	if len(dayInserts) > 0 {
-		dayInsertQuery := `
-			INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
-			VALUES (:availability_id, :day, :start_time, :end_time, :is_available);
-		`
-		_, err = tx.NamedExecContext(ctx, dayInsertQuery, dayInserts)
-		if err != nil {
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert default day availability", err))
-		}
-	}
-
-	// Commit is handled by defer
-	if err != nil { // Check if commit failed
-		appError.WriteError(w, err)
-	}
-
-	response := map[string]any{
-		"message": "User created successfully",
-		"user":    createdUser,
-	}
-	helper.ResponseJson(w, http.StatusCreated, response)
-}
-
-// POST /auth/login
-func (h *Controller) Login(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	dto, ok := validator.GetValidatedDTOFromContext[dto.LoginDto](ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
-		return
-	}
-
-	// 1. Find User by Email (including password hash)
-	var user model.User
-	query := `SELECT id, name, email, username, password, image_url, created_at, updated_at FROM users WHERE email = $1;`
-	err := h.db.GetContext(ctx, &user, query, dto.Email)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// Use specific error code for user not found during login attempt
-			appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "Invalid email or password", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to query user", err))
-		return
-	}
-
-	// 2. Compare Password
-	err = helper.ComparePassword(user.Password, dto.Password)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.AuthUnauthorizedAccess, "Invalid email or password", nil))
-		return
-	}
-
-	// 3. Generate JWT
-	accessToken, expiresAt, err := pkgJwt.SignJwtToken(user.ID)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to generate access token", err))
-		return
-	}
-
-	// 4. Prepare and Return Response (omit password)
-	user.Password = "" // Explicitly clear password before returning
-
-	response := map[string]any{
-		"message":     "User logged in successfully",
-		"user":        user,
-		"accessToken": accessToken,
-		"expiresAt":   expiresAt,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-var (
-	// Precompile regex for username generation
-	nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
-	whitespaceRegexUser  = regexp.MustCompile(`\s+`)
-)
-
-// generateUsername creates a unique username based on the name.
-// It needs access to the AuthService's db connection.
-func (h *Controller) generateUsername(ctx context.Context, name string) (string, error) {
-	// Clean base name
-	baseUsername := strings.ToLower(name)
-	baseUsername = whitespaceRegexUser.ReplaceAllString(baseUsername, "")  // Remove spaces
-	baseUsername = nonAlphanumericRegex.ReplaceAllString(baseUsername, "") // Remove non-alphanumeric
-
-	if baseUsername == "" {
-		baseUsername = "user" // Fallback if name becomes empty
-	}
-
-	// Max attempts to prevent infinite loop (highly unlikely)
-	maxAttempts := 10
-	for range maxAttempts {
-		// Generate suffix
-		uid := uuid.NewString()
-		// Use a slightly longer suffix for better uniqueness chances initially
-		suffix := uid[:6]
-		username := baseUsername + suffix
-
-		// Check if username exists
-		var exists bool
-		err := h.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username)
-		if err != nil {
-			return "", appError.NewAppError(enum.InternalServerError, "Failed to check username uniqueness", err)
-		}
-
-		if !exists {
-			return username, nil // Found a unique username
-		}
-		// If exists, loop will continue
-	}
-
-	// If loop finishes, we failed to find a unique username after max attempts
-	return "", appError.NewAppError(enum.InternalServerError, "Failed to generate unique username after multiple attempts", nil)
-}
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	pkgJwt "github.com/fazamuttaqien/calendly/pkg/jwt"
+	"github.com/fazamuttaqien/calendly/pkg/oauth"
+	"github.com/fazamuttaqien/calendly/pkg/tracing"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// POST /auth/register
+func (h *Controller) Register(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.RegisterDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Check if user already exists
+	var exists bool
+	err := h.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)", dto.Email)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check user existence", err))
+		return
+	}
+	if exists {
+		appError.WriteError(w, appError.NewAppError(enum.AuthEmailAlreadyExists, "User with this email already exists", nil))
+		return
+	}
+
+	// 2. Hash password
+	hashedPassword, err := helper.HashPassword(dto.Password)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to hash password", err))
+		return
+	}
+
+	// 3. Insert the user with a unique username, together with its default availability
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	userInsertQuery := `
+		INSERT INTO users (username, name, email, password, is_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, FALSE, NOW(), NOW())
+		ON CONFLICT (username) DO NOTHING
+		RETURNING id, name, email, username, image_url, is_verified, role, created_at, updated_at; -- Do NOT return password hash
+	`
+	createdUser, err := insertUserWithUniqueUsername(ctx, tx, dto.Name, userInsertQuery, dto.Name, dto.Email, hashedPassword)
+	if err != nil {
+		// The email was registered concurrently after the check above
+		if isUniqueViolation(err) {
+			appError.WriteError(w, appError.NewAppError(enum.AuthEmailAlreadyExists, "User with this email already exists", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert user", err))
+		return
+	}
+
+	if err := insertDefaultAvailability(ctx, tx, createdUser.ID); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	// 4. Send verification email, no access token is issued until the address is verified
+	verificationToken, errToken := pkgJwt.SignVerificationToken(createdUser.ID)
+	if errToken != nil {
+		log.Printf("Warning: Failed to generate verification token (UserID: %s): %v\n", createdUser.ID, errToken)
+	} else if errMail := h.mailer.SendVerificationEmail(createdUser.Email, verificationToken); errMail != nil {
+		log.Printf("Warning: Failed to send verification email (UserID: %s): %v\n", createdUser.ID, errMail)
+	}
+
+	h.recordAudit(r, audit.AuditEntry{
+		UserID:     createdUser.ID,
+		Action:     audit.ActionUserRegister,
+		EntityType: audit.EntityUser,
+		EntityID:   createdUser.ID,
+	})
+
+	response := map[string]any{
+		"message": "User created successfully, please verify your email address",
+		"user":    createdUser,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// POST /auth/login
+func (h *Controller) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.LoginDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Find User by Email (including password hash)
+	var user model.User
+	query := `SELECT id, name, email, username, password, image_url, is_verified, role, created_at, updated_at FROM users WHERE email = $1 AND deleted_at IS NULL;`
+	err := h.db.GetContext(ctx, &user, query, dto.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Use specific error code for user not found during login attempt
+			appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "Invalid email or password", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to query user", err))
+		return
+	}
+
+	// 2. Compare Password
+	err = helper.ComparePassword(user.Password, dto.Password)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.AuthUnauthorizedAccess, "Invalid email or password", nil))
+		return
+	}
+
+	// 3. Only verified users can log in
+	if !user.IsVerified {
+		appError.WriteError(w, appError.NewAppError(enum.AuthUnauthorizedAccess, "Email address is not verified", nil))
+		return
+	}
+
+	// 4. Generate JWT
+	accessToken, expiresAt, err := pkgJwt.SignJwtToken(user.ID, user.Role)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to generate access token", err))
+		return
+	}
+
+	h.recordAudit(r, audit.AuditEntry{
+		UserID:     user.ID,
+		Action:     audit.ActionUserLogin,
+		EntityType: audit.EntityUser,
+		EntityID:   user.ID,
+		Metadata:   map[string]any{"tokenExpiresAt": expiresAt},
+	})
+
+	// 5. Prepare and Return Response (omit password)
+	user.Password = "" // Explicitly clear password before returning
+
+	response := map[string]any{
+		"message":     "User logged in successfully",
+		"user":        user,
+		"accessToken": accessToken,
+		"expiresAt":   expiresAt,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /auth/verify-email?token=...
+func (h *Controller) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.VerifyEmailDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Validate token and extract the user it was issued for
+	userID, err := pkgJwt.ParseVerificationToken(dto.Token)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.AuthInvalidToken, "Invalid or expired verification token", err))
+		return
+	}
+
+	// 2. Mark the user as verified
+	result, err := h.db.ExecContext(ctx, `UPDATE users SET is_verified = TRUE, updated_at = NOW() WHERE id = $1;`, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to verify email", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to verify email", err))
+		return
+	}
+	if affected == 0 {
+		appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "User not found", nil))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Email verified successfully"})
+}
+
+// POST /auth/resend-verification
+func (h *Controller) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.ResendVerificationDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Same answer whether or not the address belongs to an unverified account, so it can't be used to probe emails
+	response := helper.SimpleMessage{Message: "If the account exists and is not verified yet, a verification email has been sent"}
+
+	// 1. Find the unverified account of the address
+	var user model.User
+	query := `SELECT id, email FROM users WHERE email = $1 AND is_verified = FALSE AND deleted_at IS NULL;`
+	err := h.db.GetContext(ctx, &user, query, dto.Email)
+	if err == sql.ErrNoRows {
+		helper.ResponseJson(w, http.StatusOK, response)
+		return
+	}
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to resend verification email", err))
+		return
+	}
+
+	// 2. Send a fresh verification email
+	verificationToken, err := pkgJwt.SignVerificationToken(user.ID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to generate verification token", err))
+		return
+	}
+	if err := h.mailer.SendVerificationEmail(user.Email, verificationToken); err != nil {
+		log.Printf("Warning: Failed to send verification email (UserID: %s): %v\n", user.ID, err)
+	}
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// POST /auth/logout
+func (h *Controller) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// Revoked until its natural expiry, after which the cleanup job removes it
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	query := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING;
+	`
+	_, err := h.db.ExecContext(ctx, query, claims.ID, expiresAt)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to revoke token", err))
+		return
+	}
+
+	h.recordAudit(r, audit.AuditEntry{
+		UserID:     claims.UserID,
+		Action:     audit.ActionUserLogout,
+		EntityType: audit.EntityUser,
+		EntityID:   claims.UserID,
+		Metadata:   map[string]any{"tokenId": claims.ID},
+	})
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "User logged out successfully"})
+}
+
+// googleLoginStateCookie holds the state of a pending Google sign-in until its callback
+const googleLoginStateCookie = "google_login_state"
+
+// GET /auth/google/login
+func (h *Controller) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	// An empty state still carries a signed CSRF token and expiry
+	stateString, err := oauth.SignState(oauth.OAuthState{})
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to encode state", err))
+		return
+	}
+
+	// Binds the state to this browser, a callback carrying another browser's state is refused (login CSRF)
+	config := GetGoogleLoginOAuthConfig()
+	http.SetCookie(w, &http.Cookie{
+		Name:     googleLoginStateCookie,
+		Value:    stateString,
+		Path:     "/",
+		MaxAge:   int(oauth.StateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.RedirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode, // Sent on the top-level redirect back from Google
+	})
+
+	authUrl := config.AuthCodeURL(stateString)
+	http.Redirect(w, r, authUrl, http.StatusTemporaryRedirect)
+}
+
+// GET /auth/google/login/callback
+func (h *Controller) GoogleLoginCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	redirectWithError := func(msg string) {
+		redirectURL := fmt.Sprintf("%s?error=%s", h.loginRedirectUrl, url.QueryEscape(msg))
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+	}
+
+	// 1. Validate state and code, the state must be the one issued to this browser
+	stateCookie, err := r.Cookie(googleLoginStateCookie)
+	http.SetCookie(w, &http.Cookie{Name: googleLoginStateCookie, Path: "/", MaxAge: -1, HttpOnly: true})
+	state := query.Get("state")
+	if err != nil || subtle.ConstantTimeCompare([]byte(stateCookie.Value), []byte(state)) != 1 {
+		redirectWithError("Invalid state parameter")
+		return
+	}
+	if _, err := oauth.VerifyAndDecodeState(state); err != nil {
+		redirectWithError("Invalid state parameter")
+		return
+	}
+	code := query.Get("code")
+	if code == "" {
+		redirectWithError("Invalid authorization code")
+		return
+	}
+
+	// 2. Exchange code and fetch the Google profile
+	exchangeCtx, span := tracing.Start(ctx, "google.oauth.exchange")
+	token, err := GetGoogleLoginOAuthConfig().Exchange(exchangeCtx, code)
+	tracing.End(span, err)
+	if err != nil {
+		redirectWithError("Failed to exchange token")
+		return
+	}
+
+	profile, err := fetchGoogleUserInfo(ctx, token)
+	if err != nil {
+		log.Printf("Warning: Failed to fetch Google user info: %v\n", err)
+		redirectWithError("Failed to fetch Google profile")
+		return
+	}
+	if profile.Sub == "" || profile.Email == "" || !profile.EmailVerified {
+		redirectWithError("Google account email is not verified")
+		return
+	}
+
+	// 3. Find or create the user
+	user, err := h.upsertGoogleUser(ctx, profile)
+	if err != nil {
+		log.Printf("Warning: Failed to sign in Google user (Sub: %s): %v\n", profile.Sub, err)
+		redirectWithError("Failed to sign in with Google")
+		return
+	}
+
+	// 4. Generate JWT
+	accessToken, expiresAt, err := pkgJwt.SignJwtToken(user.ID, user.Role)
+	if err != nil {
+		redirectWithError("Failed to generate access token")
+		return
+	}
+
+	h.recordAudit(r, audit.AuditEntry{
+		UserID:     user.ID,
+		Action:     audit.ActionUserGoogleLogin,
+		EntityType: audit.EntityUser,
+		EntityID:   user.ID,
+		Metadata:   map[string]any{"tokenExpiresAt": expiresAt},
+	})
+
+	redirectURL := fmt.Sprintf("%s?token=%s", h.loginRedirectUrl, url.QueryEscape(accessToken))
+	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+}
+
+// upsertGoogleUser returns the user linked to the Google account, linking an existing
+// account with the same email or creating a new one with a random password.
+func (h *Controller) upsertGoogleUser(ctx context.Context, profile googleUserInfo) (model.User, error) {
+	var user model.User
+	selectQuery := `
+		SELECT id, name, email, username, image_url, is_verified, role, google_id, created_at, updated_at
+		FROM users
+		WHERE (google_id = $1 OR email = $2) AND deleted_at IS NULL
+		ORDER BY (google_id = $1) DESC NULLS LAST
+		LIMIT 1;
+	`
+	err := h.db.GetContext(ctx, &user, selectQuery, profile.Sub, profile.Email)
+	if err == nil {
+		// Google has verified the address, so the account is verified as well
+		if !user.IsVerified {
+			// Anyone could have registered the address, so the password of an unverified account isn't
+			// trusted and is replaced, and sessions opened with it are ended
+			hashedPassword, err := randomPasswordHash()
+			if err != nil {
+				return model.User{}, err
+			}
+			linkQuery := `
+				UPDATE users
+				SET google_id = $1, is_verified = TRUE, password = $2,
+					tokens_invalid_before = date_trunc('second', NOW()), updated_at = NOW()
+				WHERE id = $3;
+			`
+			if _, err := h.db.ExecContext(ctx, linkQuery, profile.Sub, hashedPassword, user.ID); err != nil {
+				return model.User{}, fmt.Errorf("failed to link google account: %w", err)
+			}
+		} else if !user.GoogleID.Valid {
+			_, err = h.db.ExecContext(ctx,
+				`UPDATE users SET google_id = $1, updated_at = NOW() WHERE id = $2;`,
+				profile.Sub, user.ID)
+			if err != nil {
+				return model.User{}, fmt.Errorf("failed to link google account: %w", err)
+			}
+		}
+		return user, nil
+	}
+	if err != sql.ErrNoRows {
+		return model.User{}, fmt.Errorf("failed to query user: %w", err)
+	}
+
+	hashedPassword, err := randomPasswordHash()
+	if err != nil {
+		return model.User{}, err
+	}
+
+	name := profile.Name
+	if name == "" {
+		name = strings.Split(profile.Email, "@")[0]
+	}
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var imageURL sql.NullString
+	if profile.Picture != "" {
+		imageURL = sql.NullString{String: profile.Picture, Valid: true}
+	}
+
+	insertQuery := `
+		INSERT INTO users (username, name, email, password, image_url, google_id, is_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, TRUE, NOW(), NOW())
+		ON CONFLICT (username) DO NOTHING
+		RETURNING id, name, email, username, image_url, is_verified, role, google_id, created_at, updated_at;
+	`
+	user, err = insertUserWithUniqueUsername(ctx, tx, name, insertQuery, name, profile.Email, hashedPassword, imageURL, profile.Sub)
+	if err != nil {
+		return model.User{}, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	if err := insertDefaultAvailability(ctx, tx, user.ID); err != nil {
+		return model.User{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return user, nil
+}
+
+// randomPasswordHash returns the hash of a random password nobody knows, for accounts that sign in with Google.
+func randomPasswordHash() (string, error) {
+	randomPassword := make([]byte, 32)
+	if _, err := rand.Read(randomPassword); err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := helper.HashPassword(hex.EncodeToString(randomPassword))
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return hashedPassword, nil
+}
+
+// insertDefaultAvailability creates the availability record and weekday 09:00-17:00 rules for a new user.
+func insertDefaultAvailability(ctx context.Context, tx *sqlx.Tx, userID string) error {
+	var availabilityID string
+	availInsertQuery := `
+		INSERT INTO availability (user_id, time_gap, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW()) RETURNING id;
+	`
+	err := tx.GetContext(ctx, &availabilityID, availInsertQuery, userID, 30) // Default timeGap = 30
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to create availability", err)
+	}
+
+	dayInserts := make([]map[string]any, 0, len(enum.AllDayOfWeek()))
+	defaultStartTime := "09:00:00" // Default 9 AM
+	defaultEndTime := "17:00:00"   // Default 5 PM
+
+	for _, day := range enum.AllDayOfWeek() {
+		isAvailable := (day != enum.Sunday && day != enum.Saturday) // Not available on weekends
+		dayInserts = append(dayInserts, map[string]any{
+			"availability_id": availabilityID,
+			"day":             day,
+			"start_time":      defaultStartTime,
+			"end_time":        defaultEndTime,
+			"is_available":    isAvailable,
+		})
+	}
+
+	dayInsertQuery := `
+		INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
+		VALUES (:availability_id, :day, :start_time, :end_time, :is_available);
+	`
+	_, err = tx.NamedExecContext(ctx, dayInsertQuery, dayInserts)
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to insert default day availability", err)
+	}
+	return nil
+}
+
+var (
+	// Precompile regex for username generation
+	nonAlphanumericRegex = regexp.MustCompile(`[^a-z0-9]+`)
+	whitespaceRegexUser  = regexp.MustCompile(`\s+`)
+)
+
+// maxUsernameAttempts bounds the suffixes tried before giving up on a new username
+const maxUsernameAttempts = 10
+
+// baseUsername lowercases name and drops everything but letters and digits.
+func baseUsername(name string) string {
+	base := strings.ToLower(name)
+	base = whitespaceRegexUser.ReplaceAllString(base, "")  // Remove spaces
+	base = nonAlphanumericRegex.ReplaceAllString(base, "") // Remove non-alphanumeric
+
+	if base == "" {
+		base = "user" // Fallback if name becomes empty
+	}
+	return base
+}
+
+// insertUserWithUniqueUsername runs insertQuery with a username generated from name as $1, followed by args.
+// The query ends in ON CONFLICT (username) DO NOTHING RETURNING ..., so a taken username returns no row
+// and is retried with a fresh suffix. Checking and inserting in one statement keeps concurrent
+// sign-ups with the same name from racing for the same username.
+func insertUserWithUniqueUsername(ctx context.Context, tx *sqlx.Tx, name, insertQuery string, args ...any) (model.User, error) {
+	base := baseUsername(name)
+	for range maxUsernameAttempts {
+		username := base + uuid.NewString()[:6]
+
+		var user model.User
+		err := tx.GetContext(ctx, &user, insertQuery, append([]any{username}, args...)...)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		return user, err
+	}
+
+	return model.User{}, appError.NewAppError(enum.InternalServerError, "Failed to generate unique username after multiple attempts", nil)
+}
@@ -1,368 +1,1254 @@
-package controller
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"net/http"
-	"strings"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/helper"
-	"github.com/fazamuttaqien/calendly/internal/dto"
-	"github.com/fazamuttaqien/calendly/internal/model"
-	"github.com/fazamuttaqien/calendly/middleware"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"github.com/fazamuttaqien/calendly/pkg/validator"
-
-	"github.com/go-chi/chi/v5"
-)
-
-// GET /me/availability
-func (a *Controller) GetUserAvailability(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	var dbDetail []AvailabilityDetail
-	query := `
-		SELECT
-			a.time_gap,
-			d.day,
-			d.start_time::TEXT, -- Cast TIME to TEXT for easier parsing in Go
-			d.end_time::TEXT,   -- Cast TIME to TEXT
-			d.is_available
-		FROM availability a
-		JOIN day_availability d ON a.id = d.availability_id
-		WHERE a.user_id = $1;
-	`
-
-	err := a.db.SelectContext(ctx, &dbDetail, query, userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			var exists bool
-			errCheck := a.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID)
-			if errCheck == nil && exists {
-				appError.WriteError(w, appError.NewNotFoundError("User availability", nil))
-				return
-			}
-			appError.WriteError(w, appError.NewNotFoundError("User", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user availability", err))
-	}
-
-	if len(dbDetail) == 0 {
-		appError.WriteError(w, appError.NewNotFoundError("User availability data", nil))
-		return
-	}
-
-	availability := &AvailabilityResponse{
-		TimeGap: dbDetail[0].TimeGap, //	TimeGap is the same for all rows for this user
-		Days:    make([]DayAvailabilityDetail, 0, len(dbDetail)),
-	}
-
-	for _, detail := range dbDetail {
-		// Parse HH:MM:SS string from DB into HH:MM
-		startTimeHM, _, _ := strings.Cut(detail.StartTime, ":")     // Get HH
-		startTimeMM, _, _ := strings.Cut(detail.StartTime[3:], ":") // Get MM
-		endTimeHM, _, _ := strings.Cut(detail.EndTime, ":")         // Get HH
-		endTimeMM, _, _ := strings.Cut(detail.EndTime[3:], ":")     // Get MM
-
-		availability.Days = append(availability.Days, DayAvailabilityDetail{
-			Day:         detail.Day,
-			StartTime:   fmt.Sprintf("%s:%s", startTimeHM, startTimeMM), // Format HH:MM
-			EndTime:     fmt.Sprintf("%s:%s", endTimeHM, endTimeMM),     // Format HH:MM
-			IsAvailable: detail.IsAvailable,
-		})
-	}
-
-	response := map[string]any{
-		"message":      "Fetched availability successfully",
-		"availability": availability,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// PUT /me/availability
-func (a *Controller) UpdateAvailability(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateAvailabilityDto](ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
-		return
-	}
-
-	tx, err := a.db.BeginTxx(ctx, nil)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
-		return
-	}
-	// Ensure rollback on error
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			// An error occurred, rollback
-			// Log rollback error if needed: tx.Rollback()
-			_ = tx.Rollback()
-		} else {
-			// Success, commit
-			err = tx.Commit()
-			if err != nil {
-				err = appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err)
-			}
-		}
-	}()
-
-	// 1. Find Availability ID for the user
-	var availabilityID string
-	err = tx.GetContext(ctx, &availabilityID,
-		"SELECT id FROM availability WHERE user_id = $1",
-		userID,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			// Availability doesn't exist, create it first
-			err = tx.GetContext(ctx, &availabilityID, "INSERT INTO availability (user_id, time_gap) VALUES ($1, $2) RETURNING id", userID, dto.TimeGap)
-			if err != nil {
-				appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability record", err))
-				return
-			}
-			// If creation succeeds, proceed without updating timeGap again below
-		} else {
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to find availability record", err))
-			return
-		}
-	} else {
-		// Availability exists, Update timeGap
-		_, err = tx.ExecContext(ctx,
-			"UPDATE availability SET time_gap = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
-			dto.TimeGap,
-			availabilityID,
-		)
-		if err != nil {
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update time gap", err))
-			return
-		}
-	}
-
-	// 2. Delete old DayAvailability records
-	_, err = tx.ExecContext(ctx,
-		"DELETE FROM day_availability WHERE availability_id = $1",
-		availabilityID,
-	)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete old availability days", err))
-		return
-	}
-
-	// 3. Prepare and Insert new DayAvailability records
-	if len(dto.Days) > 0 {
-		insertQuery := `
-			INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
-			VALUES (:availability_id, :day, :start_time, :end_time, :is_available)
-		`
-		dayInserts := make([]map[string]interface{}, len(dto.Days))
-		for i, dayDto := range dto.Days {
-			// Validate HH:MM format before saving (optional but good)
-			_, errST := time.Parse(layoutHM, dayDto.StartTime)
-			_, errET := time.Parse(layoutHM, dayDto.EndTime)
-			if errST != nil || errET != nil {
-				err = fmt.Errorf(
-					"invalid time format for day %s: start='%s', end='%s'",
-					dayDto.Day, dayDto.StartTime, dayDto.EndTime,
-				)
-				appError.WriteError(w, appError.NewAppError(enum.ValidationError, err.Error(), nil))
-				return
-			}
-
-			dayInserts[i] = map[string]any{
-				"availability_id": availabilityID,
-				"day":             dayDto.Day,
-				"start_time":      dayDto.StartTime, // Store as HH:MM directly if DB type is TIME or VARCHAR
-				"end_time":        dayDto.EndTime,
-				"is_available":    dayDto.IsAvailable,
-			}
-		}
-
-		_, err = tx.NamedExecContext(ctx, insertQuery, dayInserts)
-		if err != nil {
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert new availability days", err))
-			return
-		}
-	}
-
-	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Availability updated successfully"})
-}
-
-// GET /public/events/{eventId}/availability
-func (a *Controller) GetPublicEventAvailability(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	eventID := chi.URLParam(r, "eventId")
-	if eventID == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
-		return
-	}
-
-	// Optional: Add UUID validation if service doesn't handle format errors well
-
-	// 1. Fetch Event, User, Availability, and Day rules
-	var dbResult []struct {
-		model.Event
-		AvailabilityID sql.NullString `db:"availability_id"` // From event table
-		TimeGap        sql.NullInt64  `db:"time_gap"`
-		Day            enum.DayOfWeek `db:"day"`          // From day_availability
-		StartTime      string         `db:"start_time"`   // TIME as TEXT
-		EndTime        string         `db:"end_time"`     // TIME as TEXT
-		IsAvailable    bool           `db:"is_available"` // From day_availability
-	}
-
-	query := `
-		SELECT
-			e.*, -- Select all from event
-			a.id as availability_id,
-			a.time_gap,
-			d.day,
-			d.start_time::TEXT,
-			d.end_time::TEXT,
-			d.is_available
-		FROM events e
-		JOIN users u ON e.user_id = u.id
-		LEFT JOIN availability a ON u.id = a.user_id  -- Use LEFT JOIN for availability
-		LEFT JOIN day_availability d ON a.id = d.availability_id -- LEFT JOIN for days
-		WHERE e.id = $1 AND e.is_private = FALSE;
-	`
-
-	err := a.db.SelectContext(ctx, &dbResult, query, eventID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("Public event", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event and availabilty", err))
-		return
-	}
-
-	if len(dbResult) == 0 || !dbResult[0].AvailabilityID.Valid {
-		// Event found, but no availability configured for the user
-		// Return empty list as per TS logic?
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Event found but no availability for user", nil))
-		return
-	}
-
-	event := dbResult[0].Event
-	timeGap := int(dbResult[0].TimeGap.Int64)
-	userID := event.UserID
-
-	// Organize day rules fetched from DB
-	dayRules := make(map[enum.DayOfWeek]AvailabilityDetail)
-
-	for _, row := range dbResult {
-		// Only process if day availability details are present
-		if row.AvailabilityID.Valid && row.Day != "" {
-			dayRules[row.Day] = AvailabilityDetail{
-				TimeGap:     timeGap, // Use consistent timeGap
-				Day:         row.Day,
-				StartTime:   row.StartTime,
-				EndTime:     row.EndTime,
-				IsAvailable: row.IsAvailable,
-			}
-		}
-	}
-
-	// 2. Calculate dates for the next 7 days (or desired range)
-	datesToCheck := make(map[enum.DayOfWeek]time.Time)
-	dateRangeStart := time.Now()
-	dateRangeEnd := dateRangeStart.AddDate(0, 0, 7)
-
-	var errDate error
-	for _, day := range enum.AllDayOfWeek() {
-		datesToCheck[day], errDate = GetNextDateForDay(day)
-		if errDate != nil {
-			// Log this error
-			log.Printf("Warning: Could not calculate next date for %s: %v\n", day, errDate)
-			// Decide whether to skip or return error. Skipping for now.
-		}
-	}
-
-	// 3. Fetch meetings for the relevant user within the date range ONCE
-	var meetingsInRange []model.Meeting
-	meetingsQuery := `
-	    SELECT id, user_id, event_id, guest_name, guest_email, additional_info, start_time, end_time, meet_link, calendar_event_id, calendar_app_type, status, created_at, updated_at
-        FROM meetings
-        WHERE user_id = $1 AND start_time < $2 AND end_time > $3
-	`
-
-	err = a.db.SelectContext(ctx, &meetingsInRange, meetingsQuery, userID, dateRangeEnd, dateRangeStart)
-	if err != nil && err != sql.ErrNoRows {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meetings", err))
-		return
-	}
-
-	// 4. Generate slots for each day
-	resultSlots := make([]DailyAvailabilitySlots, 0, len(enum.AllDayOfWeek()))
-	for _, dayOfWeek := range enum.AllDayOfWeek() {
-		targetDate, dateOk := datesToCheck[dayOfWeek]
-		if !dateOk {
-			continue
-		}
-
-		rule, ruleExists := dayRules[dayOfWeek]
-
-		dailyResult := DailyAvailabilitySlots{
-			Day:         dayOfWeek,
-			Slots:       []string{},
-			IsAvailable: ruleExists && rule.IsAvailable,
-		}
-
-		if dailyResult.IsAvailable {
-			// Filter meetings spesifically for this targetDate
-			meetingsForThisDate := make([]model.Meeting, 0)
-			dayStart := targetDate
-			dayEnd := targetDate.AddDate(0, 0, 1)
-
-			for _, m := range meetingsInRange {
-				if m.StartTime.Before(dayEnd) && m.EndTime.After(dayStart) {
-					meetingsForThisDate = append(meetingsForThisDate, m)
-				}
-			}
-
-			slots, errSlots := GenerateAvailableTimeSlots(
-				rule.StartTime,
-				rule.EndTime,
-				int(event.Duration),
-				timeGap,
-				meetingsForThisDate,
-				targetDate,
-			)
-			if errSlots != nil {
-				// Log error but potentially continue for other days
-				log.Printf("Error generating slots for %s on %s: %v\n", dayOfWeek, targetDate.Format(layoutDate), errSlots)
-			} else {
-				dailyResult.Slots = slots
-			}
-		}
-		resultSlots = append(resultSlots, dailyResult)
-	}
-
-	response := map[string]any{
-		"message": "Event availability fetched successfully",
-		"data":    resultSlots,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/pubsub"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Server-sent events settings of the availability stream
+const (
+	sseRetry             = 3 * time.Second  // Reconnect delay suggested to clients
+	sseKeepAliveInterval = 25 * time.Second // Below common proxy idle timeouts
+	sseDeadlineMargin    = 2 * time.Second
+	// Open streams allowed per event, each one holds a connection for close to a minute
+	maxStreamsPerEvent = 50
+	// How long a refresh computed for one change is shared with streams that read the change late
+	sharedRefreshTTL = 5 * time.Second
+)
+
+// availabilityStreams counts the open streams of each event and shares their refreshes
+var availabilityStreams = &streamRegistry{
+	open:      make(map[string]int),
+	refreshes: make(map[string]*sharedRefresh),
+}
+
+// GET /me/availability
+func (a *Controller) GetUserAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	var dbDetail []AvailabilityDetail
+	query := `
+		SELECT
+			a.time_gap,
+			d.day,
+			d.start_time::TEXT, -- Cast TIME to TEXT for easier parsing in Go
+			d.end_time::TEXT,   -- Cast TIME to TEXT
+			d.is_available
+		FROM availability a
+		JOIN day_availability d ON a.id = d.availability_id
+		WHERE a.user_id = $1;
+	`
+
+	err := a.db.SelectContext(ctx, &dbDetail, query, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			var exists bool
+			errCheck := a.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID)
+			if errCheck == nil && exists {
+				appError.WriteError(w, appError.NewNotFoundError("User availability", nil))
+				return
+			}
+			appError.WriteError(w, appError.NewNotFoundError("User", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user availability", err))
+		return
+	}
+
+	if len(dbDetail) == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("User availability data", nil))
+		return
+	}
+
+	response := map[string]any{
+		"message":      "Fetched availability successfully",
+		"availability": buildAvailabilityResponse(dbDetail),
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /availability/public/{username}
+func (a *Controller) GetPublicUserAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing username in path", nil))
+		return
+	}
+
+	// 1. Fetch the host's weekly schedule
+	var dbDetail []AvailabilityDetail
+	query := `
+		SELECT
+			a.time_gap,
+			d.day,
+			d.start_time::TEXT,
+			d.end_time::TEXT,
+			d.is_available
+		FROM users u
+		JOIN availability a ON a.user_id = u.id
+		JOIN day_availability d ON a.id = d.availability_id
+		WHERE u.username = $1;
+	`
+
+	if err := a.db.SelectContext(ctx, &dbDetail, query, username); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user availability", err))
+		return
+	}
+
+	// 2. Distinguish an unknown user from a user without a schedule
+	if len(dbDetail) == 0 {
+		var exists bool
+		errCheck := a.db.GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)", username)
+		if errCheck == nil && exists {
+			appError.WriteError(w, appError.NewNotFoundError("User availability", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewNotFoundError("User", nil))
+		return
+	}
+
+	response := map[string]any{
+		"message":      "Fetched availability successfully",
+		"availability": buildAvailabilityResponse(dbDetail),
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PUT /me/availability
+func (a *Controller) UpdateAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateAvailabilityDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	// Ensure rollback on error
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		} else if err != nil {
+			// An error occurred, rollback
+			// Log rollback error if needed: tx.Rollback()
+			_ = tx.Rollback()
+		} else {
+			// Success, commit
+			err = tx.Commit()
+			if err != nil {
+				err = appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err)
+			}
+		}
+	}()
+
+	// 1. Find Availability ID for the user
+	var availabilityID string
+	err = tx.GetContext(ctx, &availabilityID,
+		"SELECT id FROM availability WHERE user_id = $1",
+		userID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Availability doesn't exist, create it first
+			err = tx.GetContext(ctx, &availabilityID, "INSERT INTO availability (user_id, time_gap) VALUES ($1, $2) RETURNING id", userID, dto.TimeGap)
+			if err != nil {
+				appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability record", err))
+				return
+			}
+			// If creation succeeds, proceed without updating timeGap again below
+		} else {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to find availability record", err))
+			return
+		}
+	} else {
+		// Availability exists, Update timeGap
+		_, err = tx.ExecContext(ctx,
+			"UPDATE availability SET time_gap = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2",
+			dto.TimeGap,
+			availabilityID,
+		)
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update time gap", err))
+			return
+		}
+	}
+
+	// 2. Delete old DayAvailability records
+	_, err = tx.ExecContext(ctx,
+		"DELETE FROM day_availability WHERE availability_id = $1",
+		availabilityID,
+	)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete old availability days", err))
+		return
+	}
+
+	// 3. Prepare and Insert new DayAvailability records
+	if len(dto.Days) > 0 {
+		insertQuery := `
+			INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
+			VALUES (:availability_id, :day, :start_time, :end_time, :is_available)
+		`
+		dayInserts := make([]map[string]interface{}, len(dto.Days))
+		for i, dayDto := range dto.Days {
+			// Validate HH:MM format before saving (optional but good)
+			_, errST := time.Parse(layoutHM, dayDto.StartTime)
+			_, errET := time.Parse(layoutHM, dayDto.EndTime)
+			if errST != nil || errET != nil {
+				err = fmt.Errorf(
+					"invalid time format for day %s: start='%s', end='%s'",
+					dayDto.Day, dayDto.StartTime, dayDto.EndTime,
+				)
+				appError.WriteError(w, appError.NewAppError(enum.ValidationError, err.Error(), nil))
+				return
+			}
+
+			dayInserts[i] = map[string]any{
+				"availability_id": availabilityID,
+				"day":             dayDto.Day,
+				"start_time":      dayDto.StartTime, // Store as HH:MM directly if DB type is TIME or VARCHAR
+				"end_time":        dayDto.EndTime,
+				"is_available":    dayDto.IsAvailable,
+			}
+		}
+
+		_, err = tx.NamedExecContext(ctx, insertQuery, dayInserts)
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert new availability days", err))
+			return
+		}
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Availability updated successfully"})
+}
+
+// PATCH /me/availability/time-gap
+func (a *Controller) UpdateTimeGap(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateTimeGapDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// 1. Update only the time gap, day_availability is left untouched
+	updateQuery := `UPDATE availability SET time_gap = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2;`
+	result, err := tx.ExecContext(ctx, updateQuery, *dto.TimeGap, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update time gap", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Could not get rows affected after update", err))
+		return
+	}
+
+	// 2. No availability yet, create the defaults used on registration then apply the gap
+	if affected == 0 {
+		if err := insertDefaultAvailability(ctx, tx, userID); err != nil {
+			appError.WriteError(w, err)
+			return
+		}
+		if _, err := tx.ExecContext(ctx, updateQuery, *dto.TimeGap, userID); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update time gap", err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Time gap updated successfully",
+		"timeGap": *dto.TimeGap,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /me/availability/day/{day}
+// Updates a single day, the other days are left untouched.
+func (a *Controller) UpdateDayAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// Already validated as a DayOfWeek by the params middleware
+	day := enum.DayOfWeek(chi.URLParam(r, "day"))
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateDayAvailabilityDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// 1. Find the availability, creating the defaults used on registration if there is none yet
+	var availabilityID string
+	availabilityQuery := `SELECT id FROM availability WHERE user_id = $1;`
+	err = tx.GetContext(ctx, &availabilityID, availabilityQuery, userID)
+	if err == sql.ErrNoRows {
+		if err := insertDefaultAvailability(ctx, tx, userID); err != nil {
+			appError.WriteError(w, err)
+			return
+		}
+		err = tx.GetContext(ctx, &availabilityID, availabilityQuery, userID)
+	}
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to find availability record", err))
+		return
+	}
+
+	// 2. Update the day, or insert it when it has no row yet
+	updateQuery := `
+		UPDATE day_availability
+		SET start_time = $1, end_time = $2, is_available = $3, updated_at = NOW()
+		WHERE availability_id = $4 AND day = $5;
+	`
+	result, err := tx.ExecContext(ctx, updateQuery, dto.StartTime, dto.EndTime, *dto.IsAvailable, availabilityID, day)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update availability day", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Could not get rows affected after update", err))
+		return
+	}
+
+	if affected == 0 {
+		insertQuery := `
+			INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
+			VALUES ($1, $2, $3, $4, $5);
+		`
+		if _, err := tx.ExecContext(ctx, insertQuery, availabilityID, day, dto.StartTime, dto.EndTime, *dto.IsAvailable); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to insert availability day", err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Availability day updated successfully",
+		"day": DayAvailabilityDetail{
+			Day:         day,
+			StartTime:   dto.StartTime,
+			EndTime:     dto.EndTime,
+			IsAvailable: *dto.IsAvailable,
+		},
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// POST /availability/exceptions
+func (a *Controller) CreateAvailabilityException(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateAvailabilityExceptionDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	var reason sql.NullString
+	if dto.Reason != "" {
+		reason = sql.NullString{String: dto.Reason, Valid: true}
+	}
+
+	// One exception per date, posting the same date again replaces it
+	var exception model.AvailabilityException
+	query := `
+		INSERT INTO availability_exceptions (availability_id, exception_date, reason, is_available, created_at)
+		SELECT a.id, $2, $3, $4, NOW()
+		FROM availability a
+		WHERE a.user_id = $1
+		ON CONFLICT (availability_id, exception_date)
+		DO UPDATE SET reason = EXCLUDED.reason, is_available = EXCLUDED.is_available
+		RETURNING id, availability_id, exception_date, reason, is_available, created_at;
+	`
+	err := a.db.GetContext(ctx, &exception, query, userID, dto.Date, reason, dto.IsAvailable)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("User availability", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability exception", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":   "Availability exception created successfully",
+		"exception": exception,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// GET /availability/exceptions
+func (a *Controller) GetAvailabilityExceptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	exceptions := []model.AvailabilityException{}
+	query := `
+		SELECT x.id, x.availability_id, x.exception_date, x.reason, x.is_available, x.created_at
+		FROM availability_exceptions x
+		JOIN availability a ON x.availability_id = a.id
+		WHERE a.user_id = $1
+		ORDER BY x.exception_date ASC;
+	`
+	if err := a.db.SelectContext(ctx, &exceptions, query, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve availability exceptions", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":    "Fetched availability exceptions successfully",
+		"exceptions": exceptions,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// DELETE /availability/exceptions/{id}
+func (a *Controller) DeleteAvailabilityException(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	exceptionID := chi.URLParam(r, "id")
+	if exceptionID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing exception id in path", nil))
+		return
+	}
+
+	query := `
+		DELETE FROM availability_exceptions x
+		USING availability a
+		WHERE x.availability_id = a.id AND x.id = $1 AND a.user_id = $2;
+	`
+	result, err := a.db.ExecContext(ctx, query, exceptionID, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete availability exception", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Could not get rows affected after delete", err))
+		return
+	}
+	if affected == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("Availability exception", nil))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Availability exception deleted successfully"})
+}
+
+// POST /availability/vacation
+func (a *Controller) CreateVacationBlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateVacationBlockDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Formats were checked by the validator
+	startDate, _ := time.Parse(layoutDate, dto.StartDate)
+	endDate, _ := time.Parse(layoutDate, dto.EndDate)
+	if endDate.Before(startDate) {
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "endDate must not be before startDate", nil))
+		return
+	}
+	if endDate.Sub(startDate) >= maxVacationBlockDays*24*time.Hour {
+		msg := fmt.Sprintf("Vacation block cannot exceed %d days", maxVacationBlockDays)
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, msg, nil))
+		return
+	}
+
+	var reason sql.NullString
+	if dto.Reason != "" {
+		reason = sql.NullString{String: dto.Reason, Valid: true}
+	}
+
+	var block model.VacationBlock
+	query := `
+		INSERT INTO vacation_blocks (user_id, start_date, end_date, reason, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, user_id, start_date, end_date, reason, created_at;
+	`
+	if err := a.db.GetContext(ctx, &block, query, userID, dto.StartDate, dto.EndDate, reason); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create vacation block", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":       "Vacation block created successfully",
+		"vacationBlock": block,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// GET /availability/vacation
+func (a *Controller) GetVacationBlocks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	blocks := []model.VacationBlock{}
+	query := `
+		SELECT id, user_id, start_date, end_date, reason, created_at
+		FROM vacation_blocks
+		WHERE user_id = $1
+		ORDER BY start_date ASC;
+	`
+	if err := a.db.SelectContext(ctx, &blocks, query, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve vacation blocks", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":        "Fetched vacation blocks successfully",
+		"vacationBlocks": blocks,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// DELETE /availability/vacation/{id}
+func (a *Controller) DeleteVacationBlock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	blockID := chi.URLParam(r, "id")
+	if blockID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing vacation block id in path", nil))
+		return
+	}
+
+	result, err := a.db.ExecContext(ctx, `DELETE FROM vacation_blocks WHERE id = $1 AND user_id = $2;`, blockID, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete vacation block", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Could not get rows affected after delete", err))
+		return
+	}
+	if affected == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("Vacation block", nil))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Vacation block deleted successfully"})
+}
+
+// GET /public/events/{eventId}/availability
+func (a *Controller) GetPublicEventAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	// Optional: Add UUID validation if service doesn't handle format errors well
+
+	rangeDto, ok := validator.GetValidatedDTOFromContext[dto.AvailabilityRangeDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Dates in [startDate, endDate], defaulting to the next 7 days from today
+	dateRangeStart, dateRangeEnd, err := parseAvailabilityRange(rangeDto.StartDate, rangeDto.EndDate)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	_, resultSlots, err := a.eventAvailability(ctx, a.readDB, eventID, "", dateRangeStart, dateRangeEnd)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "Event availability fetched successfully",
+		"data":    resultSlots,
+	}
+
+	// Let polling clients revalidate instead of downloading unchanged slots
+	etag, err := helper.ETagFromJSON(response)
+	if err != nil {
+		log.Printf("Warning: failed to compute ETag for event %s availability: %v", eventID, err)
+		helper.ResponseJson(w, http.StatusOK, response)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if helper.ETagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /availability/public/{eventId}/stream
+// Streams the event's availability as server-sent events: the current slots first, then fresh slots
+// whenever a meeting of the host is booked, rescheduled or cancelled. The stream ends shortly before the
+// request timeout, EventSource clients reconnect on their own after sseRetry.
+func (a *Controller) StreamPublicEventAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	rangeDto, ok := validator.GetValidatedDTOFromContext[dto.AvailabilityRangeDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	dateRangeStart, dateRangeEnd, err := parseAvailabilityRange(rangeDto.StartDate, rangeDto.EndDate)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	if !availabilityStreams.acquire(eventID) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(sseRetry.Seconds())))
+		appError.WriteError(w, appError.NewAppError(enum.ServiceUnavailable, "Too many open availability streams for this event", nil))
+		return
+	}
+	defer availabilityStreams.release(eventID)
+
+	event, resultSlots, err := a.eventAvailability(ctx, a.readDB, eventID, "", dateRangeStart, dateRangeEnd)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	updates := pubsub.Subscribe(event.UserID)
+	defer pubsub.Unsubscribe(event.UserID, updates)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Keep reverse proxies from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if err := writeSSEData(w, rc, resultSlots); err != nil {
+		log.Printf("Warning: Failed to write availability stream (EventID: %s): %v\n", eventID, err)
+		return
+	}
+
+	// Close the stream ourselves before the request timeout cancels it mid-write
+	var streamEnd <-chan time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		streamEnd = time.After(time.Until(deadline) - sseDeadlineMargin)
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-streamEnd:
+			return
+		case <-keepAlive.C:
+			// Comment lines keep idle connections from being closed by proxies
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case change, ok := <-updates:
+			if !ok {
+				return
+			}
+			// Streams of the same event and range share one computation per change
+			key := fmt.Sprintf("%s|%s|%s|%s", eventID, dateRangeStart.Format(layoutDate), dateRangeEnd.Format(layoutDate), change)
+			resultSlots, err := availabilityStreams.refresh(key, func() (map[string]DailyAvailability, error) {
+				// Detached from this stream, other streams wait on the result too
+				refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), middleware.AvailabilityRequestTimeout)
+				defer cancel()
+				// Read from the primary, the replica may not have the change yet
+				_, slots, err := a.eventAvailability(refreshCtx, a.db, eventID, "", dateRangeStart, dateRangeEnd)
+				return slots, err
+			})
+			if err != nil {
+				log.Printf("Warning: Failed to refresh streamed availability (EventID: %s): %v\n", eventID, err)
+				continue
+			}
+			if err := writeSSEData(w, rc, resultSlots); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// streamRegistry tracks the open availability streams and the refreshes they share.
+type streamRegistry struct {
+	mu        sync.Mutex
+	open      map[string]int // Open streams per event ID
+	refreshes map[string]*sharedRefresh
+}
+
+// sharedRefresh is one availability computation, done is closed once slots and err are set.
+type sharedRefresh struct {
+	done  chan struct{}
+	slots map[string]DailyAvailability
+	err   error
+}
+
+// acquire reserves a stream slot of eventID, false when the event already has maxStreamsPerEvent streams.
+func (s *streamRegistry) acquire(eventID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open[eventID] >= maxStreamsPerEvent {
+		return false
+	}
+	s.open[eventID]++
+	return true
+}
+
+// release frees a slot reserved by acquire.
+func (s *streamRegistry) release(eventID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.open[eventID]--; s.open[eventID] <= 0 {
+		delete(s.open, eventID)
+	}
+}
+
+// refresh returns the result of compute for key. Concurrent callers with the same key, and callers
+// within sharedRefreshTTL after it finished, get the result of a single compute call.
+func (s *streamRegistry) refresh(key string, compute func() (map[string]DailyAvailability, error)) (map[string]DailyAvailability, error) {
+	s.mu.Lock()
+	if shared, ok := s.refreshes[key]; ok {
+		s.mu.Unlock()
+		<-shared.done
+		return shared.slots, shared.err
+	}
+	shared := &sharedRefresh{done: make(chan struct{})}
+	s.refreshes[key] = shared
+	s.mu.Unlock()
+
+	shared.slots, shared.err = compute()
+	close(shared.done)
+
+	time.AfterFunc(sharedRefreshTTL, func() {
+		s.mu.Lock()
+		delete(s.refreshes, key)
+		s.mu.Unlock()
+	})
+	return shared.slots, shared.err
+}
+
+// writeSSEData writes v as the JSON data of a server-sent event and flushes it to the client.
+func writeSSEData(w http.ResponseWriter, rc *http.ResponseController, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	return rc.Flush()
+}
+
+// errNoAvailability is wrapped in the error of eventAvailability when the owner has no availability configured
+var errNoAvailability = errors.New("no availability configured")
+
+// eventAvailability computes the bookable slots of a public event for the dates in [dateRangeStart, dateRangeEnd),
+// keyed by YYYY-MM-DD. The event is returned as well, its UserID is the host whose meetings block slots.
+// When ownerID is set, private events of that user are included so hosts can preview them.
+func (a *Controller) eventAvailability(ctx context.Context, db *database.DB, eventID, ownerID string, dateRangeStart, dateRangeEnd time.Time) (model.Event, map[string]DailyAvailability, error) {
+	// 1. Fetch Event, User, Availability, and Day rules
+	var dbResult []struct {
+		model.Event
+		AvailabilityID sql.NullString `db:"availability_id"` // From event table
+		TimeGap        sql.NullInt64  `db:"time_gap"`
+		Day            enum.DayOfWeek `db:"day"`          // From day_availability
+		StartTime      string         `db:"start_time"`   // TIME as TEXT
+		EndTime        string         `db:"end_time"`     // TIME as TEXT
+		IsAvailable    bool           `db:"is_available"` // From day_availability
+	}
+
+	query := `
+		SELECT
+			e.*, -- Select all from event
+			a.id as availability_id,
+			a.time_gap,
+			d.day,
+			d.start_time::TEXT,
+			d.end_time::TEXT,
+			d.is_available
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		LEFT JOIN availability a ON u.id = a.user_id  -- Use LEFT JOIN for availability
+		LEFT JOIN day_availability d ON a.id = d.availability_id -- LEFT JOIN for days
+		WHERE e.id = $1 AND e.deleted_at IS NULL AND (e.is_private = FALSE OR e.user_id::TEXT = $2);
+	`
+
+	err := db.SelectContext(ctx, &dbResult, query, eventID, ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.Event{}, nil, appError.NewNotFoundError("Public event", nil)
+		}
+		return model.Event{}, nil, appError.NewAppError(enum.InternalServerError, "Failed to fetch event and availabilty", err)
+	}
+
+	if len(dbResult) == 0 || !dbResult[0].AvailabilityID.Valid {
+		// Event found, but no availability configured for the user
+		return model.Event{}, nil, appError.NewAppError(enum.ValidationError, "Event found but no availability for user", errNoAvailability)
+	}
+
+	event := dbResult[0].Event
+	timeGap := int(dbResult[0].TimeGap.Int64)
+	userID := event.UserID
+
+	// Dates past the event's booking window have no bookable slots
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	if latest := today.AddDate(0, 0, event.MaxNoticeDays+1); dateRangeEnd.After(latest) {
+		dateRangeEnd = latest
+	}
+	if dateRangeEnd.Before(dateRangeStart) {
+		dateRangeEnd = dateRangeStart
+	}
+
+	// Organize day rules fetched from DB
+	dayRules := make(map[enum.DayOfWeek]AvailabilityDetail)
+
+	for _, row := range dbResult {
+		// Only process if day availability details are present
+		if row.AvailabilityID.Valid && row.Day != "" {
+			dayRules[row.Day] = AvailabilityDetail{
+				TimeGap:     timeGap, // Use consistent timeGap
+				Day:         row.Day,
+				StartTime:   row.StartTime,
+				EndTime:     row.EndTime,
+				IsAvailable: row.IsAvailable,
+			}
+		}
+	}
+
+	// 2. Walk every date in the requested range
+	datesToCheck := make([]time.Time, 0, int(dateRangeEnd.Sub(dateRangeStart).Hours()/24)+1)
+	for date := dateRangeStart; date.Before(dateRangeEnd); date = date.AddDate(0, 0, 1) {
+		datesToCheck = append(datesToCheck, date)
+	}
+
+	// 3. Fetch meetings for the relevant user within the date range ONCE
+	var meetingsInRange []model.Meeting
+	meetingsQuery := `
+	    SELECT id, user_id, event_id, guest_name, guest_email, additional_info, start_time, end_time, meet_link, calendar_event_id, calendar_app_type, status, created_at, updated_at
+        FROM meetings
+        WHERE user_id = $1 AND start_time < $2 AND end_time > $3
+	`
+
+	err = db.SelectContext(ctx, &meetingsInRange, meetingsQuery, userID, dateRangeEnd, dateRangeStart)
+	if err != nil && err != sql.ErrNoRows {
+		return model.Event{}, nil, appError.NewAppError(enum.InternalServerError, "Failed to fetch meetings", err)
+	}
+
+	// Busy times in the host's Google Calendar block slots too, availability degrades to local meetings on failure
+	var busyInRange []BusyBlock
+	var googleIntegration model.Integration
+	googleQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
+	err = db.GetContext(ctx, &googleIntegration, googleQuery, userID, enum.AppGoogleMeetAndCalendar)
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("Warning: Failed to fetch Google integration for busy times (UserID: %s): %v\n", userID, err)
+	} else if err == nil {
+		busyInRange, err = GetBusyTimesFromGoogleCalendar(ctx, a.db, googleIntegration, dateRangeStart, dateRangeEnd)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch Google Calendar busy times (UserID: %s): %v\n", userID, err)
+		}
+	}
+
+	// Date-specific exceptions override the weekly rules
+	var exceptions []model.AvailabilityException
+	exceptionsQuery := `
+		SELECT id, availability_id, exception_date, reason, is_available, created_at
+		FROM availability_exceptions
+		WHERE availability_id = $1 AND exception_date >= $2 AND exception_date < $3;
+	`
+	err = db.SelectContext(ctx, &exceptions, exceptionsQuery,
+		dbResult[0].AvailabilityID.String, dateRangeStart.Format(layoutDate), dateRangeEnd.Format(layoutDate))
+	if err != nil {
+		return model.Event{}, nil, appError.NewAppError(enum.InternalServerError, "Failed to fetch availability exceptions", err)
+	}
+
+	exceptionsByDate := make(map[string]model.AvailabilityException, len(exceptions))
+	for _, exception := range exceptions {
+		exceptionsByDate[exception.ExceptionDate.Format(layoutDate)] = exception
+	}
+
+	// Vacation blocks take precedence over both weekly rules and exceptions
+	var vacationBlocks []model.VacationBlock
+	vacationQuery := `
+		SELECT id, user_id, start_date, end_date, reason, created_at
+		FROM vacation_blocks
+		WHERE user_id = $1 AND start_date < $2 AND end_date >= $3;
+	`
+	err = db.SelectContext(ctx, &vacationBlocks, vacationQuery,
+		userID, dateRangeEnd.Format(layoutDate), dateRangeStart.Format(layoutDate))
+	if err != nil {
+		return model.Event{}, nil, appError.NewAppError(enum.InternalServerError, "Failed to fetch vacation blocks", err)
+	}
+	vacationRanges := mergeDateRanges(vacationBlocks)
+
+	// 4. Generate slots for each date, keyed by YYYY-MM-DD
+	resultSlots := make(map[string]DailyAvailability, len(datesToCheck))
+	for _, targetDate := range datesToCheck {
+		dateKey := targetDate.Format(layoutDate)
+		resultSlots[dateKey] = DailyAvailability{IsAvailable: false, Slots: []string{}}
+
+		rule, ruleExists := dayRules[DayOfWeekFromDate(targetDate)]
+		isAvailable := ruleExists && rule.IsAvailable
+		if exception, ok := exceptionsByDate[dateKey]; ok {
+			// Blocked date, or a one-off opening using the weekday's hours
+			isAvailable = ruleExists && exception.IsAvailable
+		}
+		if !isAvailable || dateInRanges(dateKey, vacationRanges) {
+			continue
+		}
+
+		// Filter meetings spesifically for this targetDate
+		meetingsForThisDate := make([]model.Meeting, 0)
+		dayStart := targetDate
+		dayEnd := targetDate.AddDate(0, 0, 1)
+
+		for _, m := range meetingsInRange {
+			if m.StartTime.Before(dayEnd) && m.EndTime.After(dayStart) {
+				meetingsForThisDate = append(meetingsForThisDate, m)
+			}
+		}
+
+		busyForThisDate := make([]BusyBlock, 0)
+		for _, block := range busyInRange {
+			if block.Start.Before(dayEnd) && block.End.After(dayStart) {
+				busyForThisDate = append(busyForThisDate, block)
+			}
+		}
+
+		slots, errSlots := GenerateAvailableTimeSlots(
+			rule.StartTime,
+			rule.EndTime,
+			int(event.Duration),
+			timeGap,
+			meetingsForThisDate,
+			busyForThisDate,
+			targetDate,
+		)
+		if errSlots != nil {
+			// Log error but potentially continue for other days
+			log.Printf("Error generating slots on %s: %v\n", dateKey, errSlots)
+			continue
+		}
+		resultSlots[dateKey] = DailyAvailability{IsAvailable: true, Slots: slots}
+	}
+
+	return event, resultSlots, nil
+}
+
+// parseAvailabilityRange turns the optional YYYY-MM-DD bounds into [start, end) at local midnight.
+// The end date is inclusive for the caller, so the returned end is the day after it.
+func parseAvailabilityRange(startDateStr, endDateStr string) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if startDateStr != "" {
+		parsed, err := time.ParseInLocation(layoutDate, startDateStr, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, appError.NewAppError(enum.ValidationError, "Invalid startDate, expected YYYY-MM-DD", err)
+		}
+		start = parsed
+	}
+
+	end := start.AddDate(0, 0, defaultAvailabilityRangeDays)
+	if endDateStr != "" {
+		parsed, err := time.ParseInLocation(layoutDate, endDateStr, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, appError.NewAppError(enum.ValidationError, "Invalid endDate, expected YYYY-MM-DD", err)
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, appError.NewAppError(enum.ValidationError, "endDate must not be before startDate", nil)
+	}
+	if end.After(start.AddDate(0, 0, maxAvailabilityRangeDays)) {
+		msg := fmt.Sprintf("Date range cannot exceed %d days", maxAvailabilityRangeDays)
+		return time.Time{}, time.Time{}, appError.NewAppError(enum.ValidationError, msg, nil)
+	}
+
+	return start, end, nil
+}
+
+// POST /event/{eventId}/clone-availability
+// Copies the availability of the sourceEventId owner when the caller has none yet.
+// With ?preview=true the availability that would be created is returned without writing it.
+func (a *Controller) CloneAvailability(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CloneAvailabilityDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	preview := false
+	if previewQuery := r.URL.Query().Get("preview"); previewQuery != "" {
+		parsed, err := strconv.ParseBool(previewQuery)
+		if err != nil {
+			appError.WriteError(w, appError.NewValidationError("preview must be true or false", err))
+			return
+		}
+		preview = parsed
+	}
+
+	// 1. The target event must belong to the caller
+	var ownsEvent bool
+	ownerQuery := `SELECT EXISTS(SELECT 1 FROM events WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL);`
+	if err := a.db.GetContext(ctx, &ownsEvent, ownerQuery, eventID, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err))
+		return
+	}
+	if !ownsEvent {
+		appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
+		return
+	}
+
+	// 2. Nothing to do when the caller already has availability
+	var existing []AvailabilityDetail
+	existingQuery := `
+		SELECT a.time_gap, d.day, d.start_time::TEXT, d.end_time::TEXT, d.is_available
+		FROM availability a
+		JOIN day_availability d ON a.id = d.availability_id
+		WHERE a.user_id = $1;
+	`
+	if err := a.db.SelectContext(ctx, &existing, existingQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user availability", err))
+		return
+	}
+	if len(existing) > 0 {
+		response := map[string]any{
+			"message":      "Availability is already configured",
+			"cloned":       false,
+			"availability": buildAvailabilityResponse(existing),
+		}
+		helper.ResponseJson(w, http.StatusOK, response)
+		return
+	}
+
+	if dto.SourceEventID == "" {
+		appError.WriteError(w, appError.NewValidationError("sourceEventId is required to clone availability", nil))
+		return
+	}
+
+	// 3. Read the source owner's schedule, the source event must be public or the caller's own
+	var source []AvailabilityDetail
+	sourceQuery := `
+		SELECT a.time_gap, d.day, d.start_time::TEXT, d.end_time::TEXT, d.is_available
+		FROM events e
+		JOIN availability a ON a.user_id = e.user_id
+		JOIN day_availability d ON a.id = d.availability_id
+		WHERE e.id = $1 AND e.deleted_at IS NULL AND (e.is_private = FALSE OR e.user_id = $2);
+	`
+	if err := a.db.SelectContext(ctx, &source, sourceQuery, dto.SourceEventID, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve source availability", err))
+		return
+	}
+	if len(source) == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("Source event availability", nil))
+		return
+	}
+
+	if preview {
+		response := map[string]any{
+			"message":      "Availability preview",
+			"cloned":       false,
+			"availability": buildAvailabilityResponse(source),
+		}
+		helper.ResponseJson(w, http.StatusOK, response)
+		return
+	}
+
+	// 4. Copy the time gap and every day into a fresh availability record
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var availabilityID string
+	availInsertQuery := `
+		INSERT INTO availability (user_id, time_gap, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW()) RETURNING id;
+	`
+	if err := tx.GetContext(ctx, &availabilityID, availInsertQuery, userID, source[0].TimeGap); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability", err))
+		return
+	}
+
+	dayInserts := make([]map[string]any, 0, len(source))
+	for _, day := range source {
+		dayInserts = append(dayInserts, map[string]any{
+			"availability_id": availabilityID,
+			"day":             day.Day,
+			"start_time":      day.StartTime,
+			"end_time":        day.EndTime,
+			"is_available":    day.IsAvailable,
+		})
+	}
+	dayInsertQuery := `
+		INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
+		VALUES (:availability_id, :day, :start_time, :end_time, :is_available);
+	`
+	if _, err := tx.NamedExecContext(ctx, dayInsertQuery, dayInserts); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to copy day availability", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":      "Availability cloned successfully",
+		"cloned":       true,
+		"availability": buildAvailabilityResponse(source),
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
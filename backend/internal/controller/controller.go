@@ -1,30 +1,48 @@
-package controller
-
-import (
-	"net/url"
-	"os"
-
-	"github.com/jmoiron/sqlx"
-)
-
-type Controller struct {
-	db          *sqlx.DB
-	frontendUrl string
-}
-
-func New(db *sqlx.DB) *Controller {
-	frontendUrl, err := url.Parse(os.Getenv("FRONTEND_URL"))
-	if err != nil {
-		panic("Invalid FRONTEND_URL configuration")
-	}
-
-	// Load frontend URL from config
-	if frontendUrl.String() == "" {
-		panic("FRONTEND_URL configuration is missing")
-	}
-
-	return &Controller{
-		db:          db,
-		frontendUrl: frontendUrl.String(),
-	}
-}
+package controller
+
+import (
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/pkg/cache"
+	"github.com/fazamuttaqien/calendly/pkg/mailer"
+)
+
+type Controller struct {
+	db *database.DB
+	// readDB serves read-heavy handlers, the read replica when one is configured
+	readDB      *database.DB
+	frontendUrl string
+	// Frontend page that receives the token after signing in with Google
+	loginRedirectUrl string
+	mailer           mailer.Mailer
+	// Responses of GetPublicByUsername, keyed by username
+	publicEventsCache cache.Cache[string, map[string]any]
+}
+
+// publicEventsCacheTTL bounds how stale a public booking page can be when a write misses invalidation.
+const publicEventsCacheTTL = 60 * time.Second
+
+func New(db *database.DB) *Controller {
+	frontendUrl, err := url.Parse(os.Getenv("FRONTEND_URL"))
+	if err != nil {
+		panic("Invalid FRONTEND_URL configuration")
+	}
+
+	// Load frontend URL from config
+	if frontendUrl.String() == "" {
+		panic("FRONTEND_URL configuration is missing")
+	}
+
+	return &Controller{
+		db:                db,
+		readDB:            db.Reader(),
+		frontendUrl:       frontendUrl.String(),
+		loginRedirectUrl:  helper.GetEnv("FRONTEND_LOGIN_URL", frontendUrl.String()),
+		mailer:            mailer.NewFromEnv(),
+		publicEventsCache: cache.NewTTL[string, map[string]any](publicEventsCacheTTL),
+	}
+}
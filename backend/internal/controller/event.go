@@ -1,471 +1,1452 @@
-package controller
-
-import (
-	"database/sql"
-	"fmt"
-	"net/http"
-	"slices"
-
-	"github.com/fazamuttaqien/calendly/helper"
-	"github.com/fazamuttaqien/calendly/internal/dto"
-	"github.com/fazamuttaqien/calendly/internal/model"
-	"github.com/fazamuttaqien/calendly/middleware"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"github.com/fazamuttaqien/calendly/pkg/validator"
-	"github.com/go-chi/chi/v5"
-	"github.com/jmoiron/sqlx"
-)
-
-// POST /events
-func (e *Controller) CreateEvent(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		// This should ideally be caught by auth middleware, but good practice to check
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateEventDto](ctx)
-	if !ok {
-		// Should be caught by validation middleware normally
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
-		return
-	}
-
-	// Basic validation (can also rely on DB enum constraint)
-	isValidLocation := slices.Contains(enum.AllEventLocationType(), dto.LocationType)
-
-	if !isValidLocation {
-		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Invalid location type provided", nil))
-		return
-	}
-
-	slug := helper.Slugify(dto.Title)
-
-	var event model.Event
-	query := `
-		INSERT INTO events (user_id, title, description, duration, slug, location_type, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, user_id, title, description, duration, slug, is_private, location_type, created_at, updated_at
-	`
-
-	// Use sql.NullString for optional description
-	var description sql.NullString
-	if dto.Description != "" {
-		description = sql.NullString{
-			String: dto.Description,
-			Valid:  true,
-		}
-	}
-
-	err := e.db.GetContext(ctx, &event, query,
-		userID, dto.Title, description, dto.Duration, slug, dto.LocationType)
-	if err != nil {
-		// Consider checking for specific DB errors like unique constraint violations if needed
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create event", err))
-		return
-	}
-
-	response := map[string]any{
-		"message": "Event created successfully",
-		"event":   event,
-	}
-	helper.ResponseJson(w, http.StatusCreated, response)
-}
-
-// GET /me/events
-func (e *Controller) GetUserEvents(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		// This should ideally be caught by auth middleware, but good practice to check
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	// 1. Check if user exists and get username
-	var username string
-	errUser := e.db.GetContext(ctx, &username, "SELECT username FROM users WHERE id = $1", userID)
-	if errUser != nil {
-		if errUser == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("User", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check user existence", errUser))
-		return
-	}
-
-	// 2. Scan User and potentially NULL Event data using userEventScanDTO
-	var scanResults []dto.UserEventScanDto
-
-	// Query with aliases matching the scan DTO's db tags
-	userEventsQuery := `
-	SELECT
-		u.id           AS user_id,
-		u.username     AS username,
-		e.id           AS event_id,
-		e.title        AS event_title,
-		e.description  AS event_description,
-		e.duration     AS event_duration,
-		e.slug         AS event_slug,
-		e.is_private   AS event_is_private,
-		e.location_type AS event_location_type,
-		e.created_at   AS event_created_at,
-		e.updated_at   AS event_updated_at
-	FROM users u
-	LEFT JOIN events e ON u.id = e.user_id -- LEFT JOIN is the key part
-	WHERE u.id = $1
-	ORDER BY e.created_at DESC; -- Ordering by event creation might put NULL events first/last depending on DB
-`
-
-	if err := e.db.SelectContext(ctx, &scanResults, userEventsQuery, userID); err != nil && err != sql.ErrNoRows { // Ignore ErrNoRows here, handled by initial user check
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user events data", err))
-		return
-	}
-
-	// 3. Process scan results, filtering out NULL events
-	validEventsMap := make(map[string]model.Event)
-	eventID := make([]string, 0, len(scanResults))
-
-	for _, row := range scanResults {
-		// Check if the event ID is valid (meaning the LEFT JOIN found a matching event)
-		if row.EventID.Valid {
-			// Construct the non-nullable models.Event from the valid scan DTO fields
-			event := model.Event{
-				ID:           row.EventID.String,
-				UserID:       row.UserID,                  // UserID is guaranteed non-null here
-				Title:        row.EventTitle.String,       // Assume title is NOT NULL in DB based on entity
-				Description:  row.EventDescription.String, // Assign NullString directly
-				Duration:     row.EventDuration.Int64,
-				Slug:         row.EventSlug.String, // Assume slug is NOT NULL
-				IsPrivate:    row.EventIsPrivate.Bool,
-				LocationType: enum.EventLocationType(row.EventLocationType.String), // Convert string to enum
-				CreatedAt:    row.EventCreatedAt.Time,
-				UpdatedAt:    row.EventUpdatedAt.Time,
-			}
-			// Add validation checks for required fields if necessary based on Null* types
-			validEventsMap[event.ID] = event
-			eventID = append(eventID, event.ID)
-		}
-	}
-
-	// If no valid events were found after scanning, return early
-	if len(eventID) == 0 {
-		appError.WriteError(w, appError.NewAppError(enum.ResourceNotFound, "Events not found", nil))
-		return
-	}
-
-	// 4. Get Meeting Counts for the *valid* Event ID
-	type meetingCountResult struct {
-		EventID string `db:"event_id"`
-		Count   int    `db:"count"`
-	}
-	var counts []meetingCountResult
-
-	countQuery, args, err := sqlx.In(`
-		SELECT event_id, COUNT(*) as count
-		FROM meetings
-		WHERE event_id IN (?)
-		GROUP BY event_id;
-	`, eventID)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to build meeting count query", err))
-		return
-	}
-
-	countQuery = e.db.Rebind(countQuery)
-	err = e.db.SelectContext(ctx, &counts, countQuery, args...)
-	if err != nil && err != sql.ErrNoRows {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve meeting counts", err))
-		return
-	}
-
-	// Map counts back to the events
-	countsMap := make(map[string]int)
-	for _, c := range counts {
-		countsMap[c.EventID] = c.Count
-	}
-
-	// 5. Construct final result with counts
-	finalEventsWithCount := make([]EventWithCount, 0, len(validEventsMap))
-	// Iterate over eventID to maintain the original query's order (approximated)
-	// Note: A more robust ordering might require storing the original order or re-querying events.
-	// For simplicity, iterating eventIDs collected earlier.
-	processedOrder := make(map[string]bool) // To handle potential duplicates if scanResults had them (unlikely with PK)
-	for _, id := range eventID {
-		if _, done := processedOrder[id]; !done {
-			event := validEventsMap[id]
-			finalEventsWithCount = append(finalEventsWithCount, EventWithCount{
-				Event:        event,
-				MeetingCount: countsMap[event.ID],
-			})
-			processedOrder[id] = true
-		}
-	}
-
-	// Construct the specific response structure from TS
-	response := map[string]any{
-		"message": "User event fetched successfully",
-		"data": map[string]any{
-			"events":   finalEventsWithCount,
-			"username": username,
-		},
-	}
-
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// PATCH /events/{eventId}/privacy
-func (e *Controller) TogglePrivacy(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	eventID := chi.URLParam(r, "eventId")
-	if eventID == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
-		return
-	}
-	// Optional: Add UUID validation here if needed,
-	// although service layer might handle invalid ID format error
-
-	var event model.Event
-	query := `
-		UPDATE events
-		SET is_private = NOT is_private, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $1 AND user_id = $2
-		RETURNING id, user_id, title, description, duration, slug, is_private, location_type, created_at, updated_at
-	`
-
-	err := e.db.GetContext(ctx, &event, query, eventID, userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to toggle event privacy", err))
-		return
-	}
-
-	privacyStatus := "public"
-	if event.IsPrivate {
-		privacyStatus = "private"
-	}
-
-	message := fmt.Sprintf("Event sent to %s successfully", privacyStatus)
-	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: message})
-}
-
-// GET /public/users/{username}/events
-func (e *Controller) GetPublicByUsername(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	username := chi.URLParam(r, "username")
-	if username == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing username in path", nil))
-		return
-	}
-
-	var results []struct {
-		// User fields (prefixed u_)
-		UserID       string         `db:"u_id"`
-		UserName     string         `db:"u_name"`
-		UserImageURL sql.NullString `db:"u_image_url"`
-
-		// Event fields (prefixed e_) - use Null types for LEFT JOIN safety
-		EventID           sql.NullString `db:"e_id"`
-		EventTitle        sql.NullString `db:"e_title"`
-		EventDescription  sql.NullString `db:"e_description"`
-		EventSlug         sql.NullString `db:"e_slug"`
-		EventDuration     sql.NullInt64  `db:"e_duration"` // Use NullInt64 for nullable integers
-		EventLocationType sql.NullString `db:"e_location_type"`
-		EventCreatedAt    sql.NullTime   `db:"e_created_at"`
-		EventUpdatedAt    sql.NullTime   `db:"e_updated_at"`
-	}
-
-	query := `
-		SELECT
-			u.id         AS u_id,
-			u.name       AS u_name,
-			u.image_url  AS u_image_url,
-			e.id         AS e_id,
-			e.title      AS e_title,
-			e.description AS e_description,
-			e.slug       AS e_slug,
-			e.duration   AS e_duration,
-			e.location_type AS e_location_type,
-            e.created_at AS e_created_at,
-            e.updated_at AS e_updated_at
-		FROM users u
-		LEFT JOIN events e ON u.id = e.user_id AND e.is_private = FALSE
-		WHERE u.username = $1
-		ORDER BY e.created_at DESC;
-	`
-
-	err := e.db.SelectContext(ctx, &results, query, username)
-	if err != nil && err != sql.ErrNoRows {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve public events", err))
-		return
-	}
-
-	if len(results) == 0 {
-		// No user found with that username
-		appError.WriteError(w, appError.NewNotFoundError("User", nil))
-	}
-
-	// User found, extract user info and events
-	userInfo := PublicUserInfo{
-		ID:       results[0].UserID,
-		Name:     results[0].UserName,
-		ImageURL: results[0].UserImageURL,
-	}
-
-	events := make([]model.Event, 0, len(results))
-	for _, row := range results {
-		// Check if the event part is valid (e.g., EventID is not NULL)
-		if row.EventID.Valid {
-			events = append(events, model.Event{
-				ID:           row.EventID.String,
-				UserID:       userInfo.ID,
-				Title:        row.EventTitle.String,
-				Description:  row.EventDescription.String,
-				Duration:     row.EventDuration.Int64,
-				Slug:         row.EventSlug.String,
-				IsPrivate:    false,
-				LocationType: enum.EventLocationType(row.EventLocationType.String),
-				CreatedAt:    row.EventCreatedAt.Time,
-				UpdatedAt:    row.EventUpdatedAt.Time,
-			})
-		}
-	}
-
-	// Construct response matching TS structure
-	response := map[string]any{
-		"message": "Public events fetched successfully",
-		"user": map[string]any{
-			"name":     userInfo.Name,
-			"username": username,
-			"imageUrl": userInfo.ImageURL.String,
-		},
-		"events": events,
-	}
-
-	// Handle null image URL cleanly
-	if !userInfo.ImageURL.Valid {
-		response["user"].(map[string]any)["imageUrl"] = nil
-	}
-
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// GET /public/users/{username}/events/{slug}
-func (e *Controller) GetPublicBySlug(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	username := chi.URLParam(r, "username")
-	slug := chi.URLParam(r, "slug")
-
-	if username == "" || slug == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing username or slug in path", nil))
-		return
-	}
-
-	dto := dto.UserNameAndSlugDto{
-		Username: username,
-		Slug:     slug,
-	}
-
-	// We need to scan into a flat struct first because sqlx doesn't directly support nested structs with prefixes easily during GetContext
-	var flatResult struct {
-		model.Event
-		UserID       string         `db:"user_id"`
-		UserName     string         `db:"user_name"`
-		UserImageURL sql.NullString `db:"user_image_url"`
-	}
-
-	query := `
-		SELECT
-			e.id, e.user_id, e.title, e.description, e.duration, e.slug, e.is_private, e.location_type, e.created_at, e.updated_at,
-			u.id as user_id, u.name as user_name, u.image_url as user_image_url
-		FROM events e
-		JOIN users u ON e.user_id = u.id
-		WHERE u.username = $1 AND e.slug = $2 AND e.is_private = FALSE;
-	`
-
-	err := e.db.GetContext(ctx, &flatResult, query, dto.Username, dto.Slug)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("Public Event", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve public event", err))
-		return
-	}
-
-	// Construct the nested result
-	result := &EventWithPublicUserInfo{
-		Event: flatResult.Event,
-		User: PublicUserInfo{
-			ID:       flatResult.UserID,
-			Name:     flatResult.UserName,
-			ImageURL: flatResult.UserImageURL,
-		},
-	}
-
-	// Ensure the embedded Event's UserID is correct (might be overwritten by user_id scan)
-	result.Event.UserID = sql.NullString{String: flatResult.UserID, Valid: true}.String
-
-	response := map[string]any{
-		"message": "Event details fetched successfully",
-		"event":   result,
-	}
-
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// DELETE /events/{eventId}
-func (e *Controller) DeleteEvent(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	eventID := chi.URLParam(r, "eventId")
-	if eventID == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
-		return
-	}
-	// Optional: Add UUID validation
-
-	query := `DELETE FROM events WHERE id = $1 AND user_id = $2;`
-
-	result, err := e.db.ExecContext(ctx, query, eventID, userID)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete event", err))
-		return
-	}
-
-	affected, err := result.RowsAffected()
-	if err != nil {
-		appError.WriteError(w, appError.NewNotFoundError("Could not get rows affected after delete", err))
-		return
-	}
-
-	if affected == 0 {
-		// Event not found for this user or already deleted
-		appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
-		return
-	}
-
-	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Event deleted successfully"})
-}
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/go-chi/chi/v5"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// eventSortColumns maps the allowed sort_by values of GetUserEvents to SQL columns.
+var eventSortColumns = map[string]string{
+	"created_at": "e.created_at",
+	"title":      "e.title",
+	"duration":   "e.duration",
+}
+
+// POST /events
+// Backed by: ALTER TABLE events ADD CONSTRAINT events_user_id_slug_key UNIQUE (user_id, slug);
+func (e *Controller) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		// This should ideally be caught by auth middleware, but good practice to check
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateEventDto](ctx)
+	if !ok {
+		// Should be caught by validation middleware normally
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Basic validation (can also rely on DB enum constraint)
+	for _, locationType := range dto.LocationTypes {
+		if !slices.Contains(enum.AllEventLocationType(), locationType) {
+			appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Invalid location type provided", nil))
+			return
+		}
+	}
+
+	// A custom slug is kept exactly, only generated slugs get a random suffix
+	customSlug := dto.Slug != ""
+	slug := helper.Slugify(dto.Title)
+	if customSlug {
+		slug = helper.SlugifyClean(dto.Slug)
+		if slug == "" {
+			appError.WriteError(w, appError.NewValidationError("Invalid slug provided", nil))
+			return
+		}
+	}
+
+	color := dto.Color
+	if color == "" {
+		color = enum.DefaultEventColor
+	}
+
+	var event model.Event
+	query := `
+		INSERT INTO events (
+			user_id, title, description, duration, slug, location_types, max_bookings, enable_waitlist,
+			min_notice_hours, max_notice_days, color, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, 0), COALESCE($10, 60), $11, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, user_id, title, description, duration, slug, is_private, location_types, max_bookings, enable_waitlist,
+			min_notice_hours, max_notice_days, color, created_at, updated_at
+	`
+
+	// Use sql.NullString for optional description
+	var description sql.NullString
+	if dto.Description != "" {
+		description = sql.NullString{
+			String: dto.Description,
+			Valid:  true,
+		}
+	}
+
+	// Event and its booking questions are created together
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// A slug already taken by this user fails UNIQUE (user_id, slug), generated ones are retried with a fresh suffix
+	nextSlug := func() string { return helper.Slugify(dto.Title) }
+	if customSlug {
+		nextSlug = nil
+	}
+	err = withSlugRetry(ctx, tx, slug, nextSlug, func(slug string) error {
+		return tx.GetContext(ctx, &event, query,
+			userID, dto.Title, description, dto.Duration, slug, enum.EventLocationTypes(dto.LocationTypes), dto.MaxBookings, dto.EnableWaitlist,
+			dto.MinNoticeHours, dto.MaxNoticeDays, color)
+	})
+	if err != nil && customSlug && isUniqueViolation(err) {
+		appError.WriteError(w, appError.NewValidationError("Slug already in use", err))
+		return
+	}
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create event", err))
+		return
+	}
+
+	questions, err := insertEventQuestions(ctx, tx, event.ID, dto.Questions)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create event questions", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	e.invalidatePublicEvents(ctx, userID)
+
+	response := map[string]any{
+		"message":   "Event created successfully",
+		"event":     event,
+		"questions": questions,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// GET /me/events
+func (e *Controller) GetUserEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		// This should ideally be caught by auth middleware, but good practice to check
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	listQuery, ok := validator.GetValidatedDTOFromContext[dto.EventListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Check if user exists
+	var username string
+	errUser := e.readDB.GetContext(ctx, &username, "SELECT username FROM users WHERE id = $1", userID)
+	if errUser != nil {
+		if errUser == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("User", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check user existence", errUser))
+		return
+	}
+
+	// 2. Scan User and potentially NULL Event data using userEventScanDTO
+	var scanResults []dto.UserEventScanDto
+
+	// Query with aliases matching the scan DTO's db tags
+	userEventsQuery := `
+	SELECT
+		u.id           AS user_id,
+		u.username     AS username,
+		e.id           AS event_id,
+		e.title        AS event_title,
+		e.description  AS event_description,
+		e.duration     AS event_duration,
+		e.slug         AS event_slug,
+		e.is_private   AS event_is_private,
+		e.location_types AS event_location_types,
+		e.color        AS event_color,
+		e.created_at   AS event_created_at,
+		e.updated_at   AS event_updated_at
+	FROM users u
+	LEFT JOIN events e ON u.id = e.user_id AND e.deleted_at IS NULL -- LEFT JOIN is the key part
+`
+
+	// Filters belong to the JOIN so the user row is still returned when nothing matches
+	args := []any{userID}
+	filterClause := ""
+	if listQuery.LocationType != "" {
+		args = append(args, listQuery.LocationType)
+		filterClause += fmt.Sprintf(" AND $%d = ANY(e.location_types)", len(args))
+	}
+	if listQuery.Q != "" {
+		args = append(args, "%"+escapeLikePattern(listQuery.Q)+"%")
+		filterClause += fmt.Sprintf(" AND e.title ILIKE $%d", len(args))
+	}
+	userEventsQuery += filterClause
+
+	// Total ignores the cursor so it stays the same across pages
+	var total int
+	countQuery := "SELECT COUNT(*) FROM events e WHERE e.user_id = $1 AND e.deleted_at IS NULL" + filterClause + ";"
+	if err := e.readDB.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count user events", err))
+		return
+	}
+
+	// Column and direction come from allowlists, never from the raw query string
+	sortBy := listQuery.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+	sortOrder := "DESC"
+	if listQuery.SortOrder == "asc" {
+		sortOrder = "ASC"
+	}
+	orderColumn, ok := eventSortColumns[sortBy]
+	if !ok {
+		// meeting_count is sorted after the counts are merged below
+		orderColumn = eventSortColumns["created_at"]
+	}
+
+	// Keyset pagination over (created_at, id), other orderings return the full list
+	paginate := sortBy == "created_at"
+	if listQuery.Cursor != "" && !paginate {
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "cursor is only supported when sorting by created_at", nil))
+		return
+	}
+	limit := listQuery.Limit
+	if limit == 0 {
+		limit = defaultEventPageLimit
+	}
+	if listQuery.Cursor != "" {
+		cursorTime, cursorID, err := decodeEventCursor(listQuery.Cursor)
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Invalid cursor", err))
+			return
+		}
+		comparison := "<"
+		if sortOrder == "ASC" {
+			comparison = ">"
+		}
+		args = append(args, cursorTime, cursorID)
+		userEventsQuery += fmt.Sprintf(" AND (e.created_at, e.id) %s ($%d, $%d)", comparison, len(args)-1, len(args))
+	}
+
+	userEventsQuery += fmt.Sprintf("\n\tWHERE u.id = $1\n\tORDER BY %s %s, e.id %s", orderColumn, sortOrder, sortOrder)
+	if paginate {
+		// One extra row tells whether another page exists
+		args = append(args, limit+1)
+		userEventsQuery += fmt.Sprintf("\n\tLIMIT $%d", len(args))
+	}
+	userEventsQuery += ";"
+
+	if err := e.readDB.SelectContext(ctx, &scanResults, userEventsQuery, args...); err != nil && err != sql.ErrNoRows { // Ignore ErrNoRows here, handled by initial user check
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user events data", err))
+		return
+	}
+
+	// 3. Process scan results, filtering out NULL events
+	validEventsMap := make(map[string]model.Event)
+	eventID := make([]string, 0, len(scanResults))
+
+	for _, row := range scanResults {
+		// Check if the event ID is valid (meaning the LEFT JOIN found a matching event)
+		if row.EventID.Valid {
+			// Construct the non-nullable models.Event from the valid scan DTO fields
+			event := model.Event{
+				ID:            row.EventID.String,
+				UserID:        row.UserID,                  // UserID is guaranteed non-null here
+				Title:         row.EventTitle.String,       // Assume title is NOT NULL in DB based on entity
+				Description:   row.EventDescription.String, // Assign NullString directly
+				Duration:      row.EventDuration.Int64,
+				Slug:          row.EventSlug.String, // Assume slug is NOT NULL
+				IsPrivate:     row.EventIsPrivate.Bool,
+				LocationTypes: row.EventLocationTypes,
+				Color:         enum.EventColor(row.EventColor.String),
+				CreatedAt:     row.EventCreatedAt.Time,
+				UpdatedAt:     row.EventUpdatedAt.Time,
+			}
+			// Add validation checks for required fields if necessary based on Null* types
+			validEventsMap[event.ID] = event
+			eventID = append(eventID, event.ID)
+		}
+	}
+
+	// If no valid events were found after scanning, return early
+	if len(eventID) == 0 {
+		appError.WriteError(w, appError.NewAppError(enum.ResourceNotFound, "Events not found", nil))
+		return
+	}
+
+	var nextCursor *string
+	if paginate && len(eventID) > limit {
+		eventID = eventID[:limit]
+		last := validEventsMap[eventID[limit-1]]
+		cursor := encodeEventCursor(last.CreatedAt, last.ID)
+		nextCursor = &cursor
+	}
+
+	// 4. Get Meeting Counts for the *valid* Event ID
+	type meetingCountResult struct {
+		EventID string `db:"event_id"`
+		Count   int    `db:"count"`
+	}
+	var counts []meetingCountResult
+
+	countQuery, args, err := sqlx.In(`
+		SELECT event_id, COUNT(*) as count
+		FROM meetings
+		WHERE event_id IN (?)
+		GROUP BY event_id;
+	`, eventID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to build meeting count query", err))
+		return
+	}
+
+	countQuery = e.readDB.Rebind(countQuery)
+	err = e.readDB.SelectContext(ctx, &counts, countQuery, args...)
+	if err != nil && err != sql.ErrNoRows {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve meeting counts", err))
+		return
+	}
+
+	// Map counts back to the events
+	countsMap := make(map[string]int)
+	for _, c := range counts {
+		countsMap[c.EventID] = c.Count
+	}
+
+	// 5. Construct final result with counts
+	finalEventsWithCount := make([]EventWithCount, 0, len(validEventsMap))
+	// Iterate over eventID to maintain the original query's order (approximated)
+	// Note: A more robust ordering might require storing the original order or re-querying events.
+	// For simplicity, iterating eventIDs collected earlier.
+	processedOrder := make(map[string]bool) // To handle potential duplicates if scanResults had them (unlikely with PK)
+	for _, id := range eventID {
+		if _, done := processedOrder[id]; !done {
+			event := validEventsMap[id]
+			finalEventsWithCount = append(finalEventsWithCount, EventWithCount{
+				Event:        event,
+				MeetingCount: countsMap[event.ID],
+			})
+			processedOrder[id] = true
+		}
+	}
+
+	if sortBy == "meeting_count" {
+		sort.SliceStable(finalEventsWithCount, func(i, j int) bool {
+			if sortOrder == "ASC" {
+				return finalEventsWithCount[i].MeetingCount < finalEventsWithCount[j].MeetingCount
+			}
+			return finalEventsWithCount[i].MeetingCount > finalEventsWithCount[j].MeetingCount
+		})
+	}
+
+	pageLimit := limit
+	if !paginate {
+		pageLimit = total
+	}
+	response := helper.NewPaginatedResponse("User event fetched successfully", finalEventsWithCount, total, pageLimit, 0)
+	response.Pagination.NextCursor = nextCursor
+	links := helper.BuildCursorLinks(r, nextCursor)
+	response.Links = &links
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /events/search
+// Backed by: CREATE INDEX idx_events_search ON events
+// USING GIN (to_tsvector('english', title || ' ' || COALESCE(description, '')));
+func (e *Controller) SearchPublicEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	searchQuery, ok := validator.GetValidatedDTOFromContext[dto.EventSearchQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	limit := searchQuery.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// 1. Build optional filters, every value is passed as a parameter
+	whereClause, orderClause, args := publicEventFilters(searchQuery.Q, searchQuery.LocationTypeCode, searchQuery.DurationMin, searchQuery.DurationMax)
+
+	// 2. Count total matches for pagination
+	total, err := e.countPublicEvents(ctx, whereClause, args)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count events", err))
+		return
+	}
+
+	// 3. Fetch the requested page with owner info and meeting counts
+	results := []dto.PublicEventSearchResult{}
+	pageArgs := append(args, limit, searchQuery.Offset)
+	listQuery := `
+		SELECT
+			e.slug, u.username, u.name, e.title, e.duration, e.location_types, e.color,
+			COALESCE(mc.count, 0) AS meeting_count
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		LEFT JOIN (
+			SELECT event_id, COUNT(*) AS count FROM meetings GROUP BY event_id
+		) mc ON mc.event_id = e.id` + whereClause + orderClause +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d;", len(pageArgs)-1, len(pageArgs))
+
+	if err := e.readDB.SelectContext(ctx, &results, listQuery, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to search events", err))
+		return
+	}
+
+	response := helper.NewPaginatedResponse("Events fetched successfully", results, total, limit, searchQuery.Offset)
+	links := helper.BuildPaginationLinks(r, limit, searchQuery.Offset, total)
+	response.Links = &links
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /event/public?page=1&limit=20&locationType=...&durationMin=...&q=...
+// Lists public events of all users for discovery, newest first.
+func (e *Controller) ListPublicEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	listQuery, ok := validator.GetValidatedDTOFromContext[dto.PublicEventListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	page := listQuery.Page
+	if page == 0 {
+		page = 1
+	}
+	limit := listQuery.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	whereClause, orderClause, args := publicEventFilters(listQuery.Q, listQuery.LocationType, listQuery.DurationMin, listQuery.DurationMax)
+
+	total, err := e.countPublicEvents(ctx, whereClause, args)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count events", err))
+		return
+	}
+
+	events := []dto.PublicEventListItem{}
+	pageArgs := append(args, limit, offset)
+	query := `
+		SELECT
+			e.title, e.slug, COALESCE(e.description, '') AS description, e.duration, e.location_types, e.color, e.created_at,
+			u.username, u.name
+		FROM events e
+		JOIN users u ON e.user_id = u.id` + whereClause + orderClause +
+		fmt.Sprintf(" LIMIT $%d OFFSET $%d;", len(pageArgs)-1, len(pageArgs))
+
+	if err := e.readDB.SelectContext(ctx, &events, query, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve public events", err))
+		return
+	}
+
+	helper.ResponsePaginated(w, http.StatusOK, "Public events fetched successfully", events, total, limit, offset)
+}
+
+// countPublicEvents counts the public events matching the clauses of publicEventFilters.
+func (e *Controller) countPublicEvents(ctx context.Context, whereClause string, args []any) (int, error) {
+	var total int
+	countQuery := "SELECT COUNT(*) FROM events e JOIN users u ON e.user_id = u.id" + whereClause + ";"
+	err := e.readDB.GetContext(ctx, &total, countQuery, args...)
+	return total, err
+}
+
+// publicEventFilters builds the WHERE and ORDER BY clauses shared by the public event listings, which join
+// the owner as u. Filters left empty or zero are skipped. Matches of q are ranked first, otherwise newest events come first.
+func publicEventFilters(q string, locationType enum.EventLocationType, durationMin, durationMax int) (string, string, []any) {
+	// Owners whose account is deleted drop out along with their events
+	whereClause := " WHERE e.is_private = FALSE AND e.deleted_at IS NULL AND u.deleted_at IS NULL"
+	args := []any{}
+	orderClause := " ORDER BY e.created_at DESC"
+
+	if q != "" {
+		// plainto_tsquery accepts free text without tsquery syntax errors
+		args = append(args, q)
+		whereClause += fmt.Sprintf(
+			" AND to_tsvector('english', e.title || ' ' || COALESCE(e.description, '')) @@ plainto_tsquery('english', $%d)", len(args))
+		orderClause = fmt.Sprintf(
+			" ORDER BY ts_rank(to_tsvector('english', e.title || ' ' || COALESCE(e.description, '')), plainto_tsquery('english', $%d)) DESC, e.created_at DESC", len(args))
+	}
+	if locationType != "" {
+		args = append(args, locationType)
+		whereClause += fmt.Sprintf(" AND $%d = ANY(e.location_types)", len(args))
+	}
+	if durationMin > 0 {
+		args = append(args, durationMin)
+		whereClause += fmt.Sprintf(" AND e.duration >= $%d", len(args))
+	}
+	if durationMax > 0 {
+		args = append(args, durationMax)
+		whereClause += fmt.Sprintf(" AND e.duration <= $%d", len(args))
+	}
+	return whereClause, orderClause, args
+}
+
+// PATCH /events/{eventId}/privacy
+func (e *Controller) TogglePrivacy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+	// Optional: Add UUID validation here if needed,
+	// although service layer might handle invalid ID format error
+
+	var event model.Event
+	query := `
+		UPDATE events
+		SET is_private = NOT is_private, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+		RETURNING id, user_id, title, description, duration, slug, is_private, location_types, color, created_at, updated_at
+	`
+
+	err := e.db.GetContext(ctx, &event, query, eventID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to toggle event privacy", err))
+		return
+	}
+
+	privacyStatus := "public"
+	if event.IsPrivate {
+		privacyStatus = "private"
+	}
+
+	e.invalidatePublicEvents(ctx, userID)
+
+	message := fmt.Sprintf("Event sent to %s successfully", privacyStatus)
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: message})
+}
+
+// GET /public/users/{username}/events
+func (e *Controller) GetPublicByUsername(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing username in path", nil))
+		return
+	}
+
+	// Listing pages can ask for just the event fields they render, e.g. ?fields=title,duration,slug
+	fields := helper.ParseFields(r)
+
+	if cached, ok := e.publicEventsCache.Get(username); ok {
+		helper.ResponseJson(w, http.StatusOK, selectEventFields(cached, fields))
+		return
+	}
+
+	var results []struct {
+		// User fields (prefixed u_)
+		UserID       string         `db:"u_id"`
+		UserName     string         `db:"u_name"`
+		UserImageURL sql.NullString `db:"u_image_url"`
+
+		// Event fields (prefixed e_) - use Null types for LEFT JOIN safety
+		EventID            sql.NullString          `db:"e_id"`
+		EventTitle         sql.NullString          `db:"e_title"`
+		EventDescription   sql.NullString          `db:"e_description"`
+		EventSlug          sql.NullString          `db:"e_slug"`
+		EventDuration      sql.NullInt64           `db:"e_duration"` // Use NullInt64 for nullable integers
+		EventLocationTypes enum.EventLocationTypes `db:"e_location_types"`
+		EventColor         sql.NullString          `db:"e_color"`
+		EventCreatedAt     sql.NullTime            `db:"e_created_at"`
+		EventUpdatedAt     sql.NullTime            `db:"e_updated_at"`
+	}
+
+	query := `
+		SELECT
+			u.id         AS u_id,
+			u.name       AS u_name,
+			u.image_url  AS u_image_url,
+			e.id         AS e_id,
+			e.title      AS e_title,
+			e.description AS e_description,
+			e.slug       AS e_slug,
+			e.duration   AS e_duration,
+			e.location_types AS e_location_types,
+			e.color      AS e_color,
+            e.created_at AS e_created_at,
+            e.updated_at AS e_updated_at
+		FROM users u
+		LEFT JOIN events e ON u.id = e.user_id AND e.is_private = FALSE AND e.deleted_at IS NULL
+		WHERE u.username = $1 AND u.deleted_at IS NULL
+		ORDER BY e.created_at DESC;
+	`
+
+	err := e.readDB.SelectContext(ctx, &results, query, username)
+	if err != nil && err != sql.ErrNoRows {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve public events", err))
+		return
+	}
+
+	if len(results) == 0 {
+		// No user found with that username
+		appError.WriteError(w, appError.NewNotFoundError("User", nil))
+		return
+	}
+
+	// User found, extract user info and events
+	userInfo := PublicUserInfo{
+		ID:       results[0].UserID,
+		Name:     results[0].UserName,
+		ImageURL: results[0].UserImageURL,
+	}
+
+	events := make([]model.Event, 0, len(results))
+	for _, row := range results {
+		// Check if the event part is valid (e.g., EventID is not NULL)
+		if row.EventID.Valid {
+			events = append(events, model.Event{
+				ID:            row.EventID.String,
+				UserID:        userInfo.ID,
+				Title:         row.EventTitle.String,
+				Description:   row.EventDescription.String,
+				Duration:      row.EventDuration.Int64,
+				Slug:          row.EventSlug.String,
+				IsPrivate:     false,
+				LocationTypes: row.EventLocationTypes,
+				Color:         enum.EventColor(row.EventColor.String),
+				CreatedAt:     row.EventCreatedAt.Time,
+				UpdatedAt:     row.EventUpdatedAt.Time,
+			})
+		}
+	}
+
+	// Construct response matching TS structure
+	response := map[string]any{
+		"message": "Public events fetched successfully",
+		"user": map[string]any{
+			"name":     userInfo.Name,
+			"username": username,
+			"imageUrl": userInfo.ImageURL.String,
+		},
+		"events": events,
+	}
+
+	// Handle null image URL cleanly
+	if !userInfo.ImageURL.Valid {
+		response["user"].(map[string]any)["imageUrl"] = nil
+	}
+
+	e.publicEventsCache.Set(username, response)
+	helper.ResponseJson(w, http.StatusOK, selectEventFields(response, fields))
+}
+
+// selectEventFields returns a copy of a public events response with every event reduced to fields.
+// The cached response itself is left untouched, it keeps serving requests without ?fields.
+func selectEventFields(response map[string]any, fields []string) map[string]any {
+	events, ok := response["events"].([]model.Event)
+	if len(fields) == 0 || !ok {
+		return response
+	}
+
+	filtered := make([]map[string]any, 0, len(events))
+	for _, event := range events {
+		filtered = append(filtered, helper.FilterFields(event, fields))
+	}
+
+	result := maps.Clone(response)
+	result["events"] = filtered
+	return result
+}
+
+// GET /public/users/{username}/events/{slug}
+func (e *Controller) GetPublicBySlug(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	username := chi.URLParam(r, "username")
+	slug := chi.URLParam(r, "slug")
+
+	if username == "" || slug == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing username or slug in path", nil))
+		return
+	}
+
+	dto := dto.UserNameAndSlugDto{
+		Username: username,
+		Slug:     slug,
+	}
+
+	// We need to scan into a flat struct first because sqlx doesn't directly support nested structs with prefixes easily during GetContext
+	var flatResult struct {
+		model.Event
+		UserID       string         `db:"user_id"`
+		UserName     string         `db:"user_name"`
+		UserImageURL sql.NullString `db:"user_image_url"`
+	}
+
+	query := `
+		SELECT
+			e.id, e.user_id, e.title, e.description, e.duration, e.slug, e.is_private, e.location_types, e.color, e.created_at, e.updated_at,
+			u.id as user_id, u.name as user_name, u.image_url as user_image_url
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE u.username = $1 AND (e.slug = $2 OR $2 = ANY(e.slug_aliases)) AND e.is_private = FALSE AND e.deleted_at IS NULL
+		ORDER BY (e.slug = $2) DESC -- A current slug wins over another event's old one
+		LIMIT 1;
+	`
+
+	err := e.db.GetContext(ctx, &flatResult, query, dto.Username, dto.Slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Public Event", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve public event", err))
+		return
+	}
+
+	// Construct the nested result
+	result := &EventWithPublicUserInfo{
+		Event: flatResult.Event,
+		User: PublicUserInfo{
+			ID:       flatResult.UserID,
+			Name:     flatResult.UserName,
+			ImageURL: flatResult.UserImageURL,
+		},
+	}
+
+	// Ensure the embedded Event's UserID is correct (might be overwritten by user_id scan)
+	result.Event.UserID = sql.NullString{String: flatResult.UserID, Valid: true}.String
+
+	questions, err := e.getEventQuestions(ctx, result.Event.ID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve event questions", err))
+		return
+	}
+	result.Questions = questions
+
+	response := map[string]any{
+		"message": "Event details fetched successfully",
+		"event":   result,
+	}
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /me/booking-preview/{slug}
+// Shows a host one of their events the way guests see it, private events included,
+// along with warnings about setup issues that would keep guests from booking.
+func (e *Controller) GetBookingPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	slug := chi.URLParam(r, "slug")
+	if slug == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing slug in path", nil))
+		return
+	}
+
+	var flatResult struct {
+		model.Event
+		UserID       string         `db:"user_id"`
+		UserName     string         `db:"user_name"`
+		UserImageURL sql.NullString `db:"user_image_url"`
+	}
+
+	query := `
+		SELECT
+			e.id, e.user_id, e.title, e.description, e.duration, e.slug, e.is_private, e.location_types, e.color, e.created_at, e.updated_at,
+			u.id as user_id, u.name as user_name, u.image_url as user_image_url
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE e.user_id = $1 AND e.slug = $2 AND e.deleted_at IS NULL;
+	`
+
+	err := e.db.GetContext(ctx, &flatResult, query, userID, slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Event", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve event", err))
+		return
+	}
+
+	result := &EventWithPublicUserInfo{
+		Event: flatResult.Event,
+		User: PublicUserInfo{
+			ID:       flatResult.UserID,
+			Name:     flatResult.UserName,
+			ImageURL: flatResult.UserImageURL,
+		},
+	}
+	result.Event.UserID = flatResult.UserID
+
+	questions, err := e.getEventQuestions(ctx, result.Event.ID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve event questions", err))
+		return
+	}
+	result.Questions = questions
+
+	warnings := []string{}
+	if result.Event.IsPrivate {
+		warnings = append(warnings, "Event is private, guests can't book it until it is made public")
+	}
+
+	// The same integrations booking requires for each offered location
+	for _, locationType := range result.Event.LocationTypes {
+		appType, ok := IntegrationAppTypeFromEventLocation(locationType)
+		if !ok {
+			continue
+		}
+		var connected bool
+		integrationQuery := `SELECT EXISTS(SELECT 1 FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE);`
+		if err := e.db.GetContext(ctx, &connected, integrationQuery, userID, appType); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check integrations", err))
+			return
+		}
+		if !connected {
+			warnings = append(warnings, fmt.Sprintf("%s integration not connected", appTypeToTitleMap[appType]))
+		}
+	}
+
+	// Slots for the next 7 days, the default range of the public availability endpoint
+	dateRangeStart, dateRangeEnd, err := parseAvailabilityRange("", "")
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	_, slots, err := e.eventAvailability(ctx, e.db, result.Event.ID, userID, dateRangeStart, dateRangeEnd)
+	switch {
+	case errors.Is(err, errNoAvailability):
+		warnings = append(warnings, "No availability configured")
+	case err != nil:
+		appError.WriteError(w, err)
+		return
+	case !hasBookableSlot(slots):
+		warnings = append(warnings, fmt.Sprintf("No available slots in the next %d days", defaultAvailabilityRangeDays))
+	}
+
+	if len(questions) == 0 {
+		warnings = append(warnings, "Event has no custom questions")
+	}
+
+	response := map[string]any{
+		"message":      "Booking preview fetched successfully",
+		"event":        result,
+		"availability": slots,
+		"warnings":     warnings,
+	}
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+func hasBookableSlot(slots map[string]DailyAvailability) bool {
+	for _, day := range slots {
+		if day.IsAvailable && len(day.Slots) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PUT /event/{eventId}?regenerateSlug=true
+// PATCH /event/{eventId}?regenerateSlug=true
+// With regenerateSlug the slug is generated again from the title, the old one is kept as an alias.
+func (e *Controller) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateEventDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	var description sql.NullString
+	if dto.Description != "" {
+		description = sql.NullString{
+			String: dto.Description,
+			Valid:  true,
+		}
+	}
+
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// 1. Update the event itself, scoped to its owner
+	var event model.Event
+	query := `
+		UPDATE events
+		SET title = $1, description = $2, duration = $3, location_types = $4,
+			min_notice_hours = COALESCE($5, min_notice_hours), max_notice_days = COALESCE($6, max_notice_days),
+			color = COALESCE(NULLIF($7, ''), color), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND user_id = $9 AND deleted_at IS NULL
+		RETURNING id, user_id, title, description, duration, slug, slug_aliases, is_private, location_types, max_bookings, enable_waitlist,
+			min_notice_hours, max_notice_days, color, created_at, updated_at
+	`
+	err = tx.GetContext(ctx, &event, query,
+		dto.Title, description, dto.Duration, enum.EventLocationTypes(dto.LocationTypes), dto.MinNoticeHours, dto.MaxNoticeDays, dto.Color, eventID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update event", err))
+		return
+	}
+
+	// 2. Optionally regenerate the slug from the (new) title
+	if regenerateSlug, _ := strconv.ParseBool(r.URL.Query().Get("regenerateSlug")); regenerateSlug {
+		if err := regenerateEventSlug(ctx, tx, &event); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to regenerate event slug", err))
+			return
+		}
+	}
+
+	// 3. Update booking questions in place, answers of past bookings are kept
+	questions, err := upsertEventQuestions(ctx, tx, event.ID, dto.Questions)
+	if err != nil {
+		var appErr *appError.AppError
+		if !errors.As(err, &appErr) {
+			appErr = appError.NewAppError(enum.InternalServerError, "Failed to update event questions", err)
+		}
+		appError.WriteError(w, appErr)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	e.invalidatePublicEvents(ctx, userID)
+
+	response := map[string]any{
+		"message":   "Event updated successfully",
+		"event":     event,
+		"questions": questions,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// POST /events/{eventId}/copy?includeQuestions=true
+// Creates a new event from the caller's own or a public event. The copy uses the caller's
+// availability, only when the caller has none yet is the source owner's availability copied.
+func (e *Controller) CopyEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	includeQuestions := false
+	if includeQuery := r.URL.Query().Get("includeQuestions"); includeQuery != "" {
+		parsed, err := strconv.ParseBool(includeQuery)
+		if err != nil {
+			appError.WriteError(w, appError.NewValidationError("includeQuestions must be true or false", err))
+			return
+		}
+		includeQuestions = parsed
+	}
+
+	// 1. The source event must be the caller's own or public
+	var source model.Event
+	sourceQuery := `
+		SELECT id, user_id, title, COALESCE(description, '') AS description, duration, slug, is_private, location_types,
+			max_bookings, enable_waitlist, min_notice_hours, max_notice_days, color, created_at, updated_at
+		FROM events
+		WHERE id = $1 AND deleted_at IS NULL AND (is_private = FALSE OR user_id = $2);
+	`
+	if err := e.db.GetContext(ctx, &source, sourceQuery, eventID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s", eventID), nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err))
+		return
+	}
+
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// 2. Link to the caller's availability, copying the source owner's when there is none
+	availabilityCopied := false
+	var availabilityExists bool
+	if err := tx.GetContext(ctx, &availabilityExists, `SELECT EXISTS(SELECT 1 FROM availability WHERE user_id = $1);`, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user availability", err))
+		return
+	}
+	if !availabilityExists && source.UserID != userID {
+		var availabilityID sql.NullString
+		availInsertQuery := `
+			INSERT INTO availability (user_id, time_gap, created_at, updated_at)
+			SELECT $1, time_gap, NOW(), NOW() FROM availability WHERE user_id = $2
+			RETURNING id;
+		`
+		if err := tx.GetContext(ctx, &availabilityID, availInsertQuery, userID, source.UserID); err != nil && err != sql.ErrNoRows {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create availability", err))
+			return
+		}
+
+		if availabilityID.Valid {
+			dayInsertQuery := `
+				INSERT INTO day_availability (availability_id, day, start_time, end_time, is_available)
+				SELECT $1, d.day, d.start_time, d.end_time, d.is_available
+				FROM day_availability d
+				JOIN availability a ON a.id = d.availability_id
+				WHERE a.user_id = $2;
+			`
+			if _, err := tx.ExecContext(ctx, dayInsertQuery, availabilityID.String, source.UserID); err != nil {
+				appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to copy day availability", err))
+				return
+			}
+			availabilityCopied = true
+		}
+	}
+
+	// 3. Clone the event under a fresh slug
+	var event model.Event
+	query := `
+		INSERT INTO events (
+			user_id, title, description, duration, slug, is_private, location_types, max_bookings, enable_waitlist,
+			min_notice_hours, max_notice_days, color, created_at, updated_at
+		)
+		SELECT $1, title, description, duration, $2, is_private, location_types, max_bookings, enable_waitlist,
+			min_notice_hours, max_notice_days, color, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM events WHERE id = $3
+		RETURNING id, user_id, title, COALESCE(description, '') AS description, duration, slug, is_private, location_types,
+			max_bookings, enable_waitlist, min_notice_hours, max_notice_days, color, created_at, updated_at
+	`
+	nextSlug := func() string { return helper.Slugify(source.Title) }
+	err = withSlugRetry(ctx, tx, nextSlug(), nextSlug, func(slug string) error {
+		return tx.GetContext(ctx, &event, query, userID, slug, source.ID)
+	})
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to copy event", err))
+		return
+	}
+
+	// 4. Copy booking questions when asked to
+	questions := []model.EventQuestion{}
+	if includeQuestions {
+		questionQuery := `
+			INSERT INTO event_questions (event_id, label, field_type, options, is_required, sort_order)
+			SELECT $1, label, field_type, options, is_required, sort_order
+			FROM event_questions WHERE event_id = $2 AND archived_at IS NULL
+			RETURNING id, event_id, label, field_type, options, is_required, sort_order;
+		`
+		if err := tx.SelectContext(ctx, &questions, questionQuery, event.ID, source.ID); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to copy event questions", err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	e.invalidatePublicEvents(ctx, userID)
+
+	response := map[string]any{
+		"message":            "Event copied successfully",
+		"eventId":            event.ID,
+		"event":              event,
+		"questions":          questions,
+		"availabilityCopied": availabilityCopied,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// GET /events/{eventId}/analytics
+func (e *Controller) GetEventAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	// Every stat comes from one query over the event's meetings, scoped to the owner
+	var analytics EventAnalytics
+	query := `
+		WITH em AS (
+			SELECT m.* FROM meetings m
+			JOIN events e ON e.id = m.event_id
+			WHERE e.id = $1 AND e.user_id = $2 AND e.deleted_at IS NULL
+		)
+		SELECT
+			(SELECT COUNT(*) FROM em) AS total_bookings,
+			(SELECT COUNT(*) FROM em WHERE status = $3) AS cancelled_bookings,
+			(SELECT COUNT(*) FROM em WHERE status = $4) AS no_show_bookings,
+			(SELECT COUNT(*) FROM em WHERE status = $5 AND start_time > NOW()) AS upcoming_bookings,
+			(
+				SELECT COALESCE(json_agg(json_build_object('dayOfWeek', d.day_of_week, 'count', d.count) ORDER BY d.day_of_week), '[]')
+				FROM (
+					SELECT EXTRACT(DOW FROM start_time)::INT AS day_of_week, COUNT(*) AS count
+					FROM em GROUP BY EXTRACT(DOW FROM start_time)
+				) d
+			) AS bookings_by_day,
+			(
+				SELECT COALESCE(json_agg(json_build_object('hour', h.hour, 'count', h.count) ORDER BY h.hour), '[]')
+				FROM (
+					SELECT EXTRACT(HOUR FROM start_time)::INT AS hour, COUNT(*) AS count
+					FROM em GROUP BY EXTRACT(HOUR FROM start_time)
+				) h
+			) AS bookings_by_hour,
+			(SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (start_time - created_at)) / 3600), 0) FROM em) AS avg_lead_time_hours,
+			(
+				SELECT COALESCE(json_agg(json_build_object('domain', g.domain, 'count', g.count) ORDER BY g.count DESC, g.domain), '[]')
+				FROM (
+					SELECT LOWER(SPLIT_PART(guest_email, '@', 2)) AS domain, COUNT(*) AS count
+					FROM em GROUP BY LOWER(SPLIT_PART(guest_email, '@', 2))
+					ORDER BY count DESC, domain ASC
+					LIMIT 10
+				) g
+			) AS top_guest_domains,
+			(SELECT MAX(created_at) FROM em) AS last_booked_at
+		FROM events
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+	`
+	err := e.db.GetContext(ctx, &analytics, query, eventID, userID, enum.Cancelled, enum.NoShow, enum.Scheduled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to compute event analytics", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":   "Fetched event analytics successfully",
+		"analytics": analytics,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// DELETE /events/{eventId}
+func (e *Controller) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+	// Optional: Add UUID validation
+
+	// Archive instead of deleting so the meeting history of the event is kept
+	query := `UPDATE events SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;`
+
+	result, err := e.db.ExecContext(ctx, query, eventID, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete event", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewNotFoundError("Could not get rows affected after delete", err))
+		return
+	}
+
+	if affected == 0 {
+		// Event not found for this user or already deleted
+		appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s for user", eventID), nil))
+		return
+	}
+
+	e.invalidatePublicEvents(ctx, userID)
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Event deleted successfully"})
+}
+
+// DELETE /events/{eventId}/hard
+// Admin only: permanently removes an event and everything booked on it, for data-retention compliance.
+func (e *Controller) HardDeleteEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	eventID := chi.URLParam(r, "eventId")
+	if eventID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing eventId in path", nil))
+		return
+	}
+
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// Dependent rows first, so this works regardless of the foreign key actions
+	dependentQueries := []string{
+		`DELETE FROM booking_answers WHERE meeting_id IN (SELECT id FROM meetings WHERE event_id = $1);`,
+		`DELETE FROM meetings WHERE event_id = $1;`,
+		`DELETE FROM event_questions WHERE event_id = $1;`,
+		`DELETE FROM waitlist WHERE event_id = $1;`,
+	}
+	for _, query := range dependentQueries {
+		if _, err := tx.ExecContext(ctx, query, eventID); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete event data", err))
+			return
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM events WHERE id = $1;`, eventID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete event", err))
+		return
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Could not get rows affected after delete", err))
+		return
+	}
+	if affected == 0 {
+		appError.WriteError(w, appError.NewNotFoundError(fmt.Sprintf("Event with ID %s", eventID), nil))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Event permanently deleted"})
+}
+
+// invalidatePublicEvents drops the cached public events of userID after one of their events changed.
+func (e *Controller) invalidatePublicEvents(ctx context.Context, userID string) {
+	var username string
+	if err := e.db.GetContext(ctx, &username, "SELECT username FROM users WHERE id = $1", userID); err != nil {
+		log.Printf("Warning: failed to resolve username of user %s for cache invalidation: %v", userID, err)
+		return
+	}
+	e.publicEventsCache.Invalidate(username)
+}
+
+// regenerateEventSlug gives event a fresh slug generated from its title and appends the old slug to its aliases.
+// Slugs used by the owner's other events, current or aliased, are skipped.
+func regenerateEventSlug(ctx context.Context, tx *sqlx.Tx, event *model.Event) error {
+	aliasTakenQuery := `SELECT EXISTS(SELECT 1 FROM events WHERE user_id = $1 AND id <> $2 AND $3 = ANY(slug_aliases));`
+	updateQuery := `
+		UPDATE events
+		SET slug = $1, slug_aliases = array_append(array_remove(slug_aliases, $1), slug), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING slug, slug_aliases;
+	`
+
+	nextSlug := func() string { return helper.Slugify(event.Title) }
+	return withSlugRetry(ctx, tx, nextSlug(), nextSlug, func(slug string) error {
+		var aliasTaken bool
+		if err := tx.GetContext(ctx, &aliasTaken, aliasTakenQuery, event.UserID, event.ID, slug); err != nil {
+			return err
+		}
+		if aliasTaken {
+			return errSlugTaken
+		}
+		return tx.QueryRowxContext(ctx, updateQuery, slug, event.ID).Scan(&event.Slug, &event.SlugAliases)
+	})
+}
+
+// insertEventQuestions creates the booking questions of an event within tx.
+func insertEventQuestions(ctx context.Context, tx *sqlx.Tx, eventID string, questions []dto.EventQuestionDto) ([]model.EventQuestion, error) {
+	created := make([]model.EventQuestion, 0, len(questions))
+
+	query := `
+		INSERT INTO event_questions (event_id, label, field_type, options, is_required, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, event_id, label, field_type, options, is_required, sort_order;
+	`
+
+	for _, q := range questions {
+		options, err := encodeQuestionOptions(q)
+		if err != nil {
+			return nil, err
+		}
+
+		var question model.EventQuestion
+		err = tx.GetContext(ctx, &question, query,
+			eventID, q.Label, q.FieldType, options, q.IsRequired, q.SortOrder)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert question %q: %w", q.Label, err)
+		}
+		created = append(created, question)
+	}
+
+	return created, nil
+}
+
+// upsertEventQuestions makes questions the booking questions of an event. Questions with an ID are updated,
+// the others created. Removed questions are deleted when unanswered and archived otherwise,
+// so the answers guests gave on past bookings survive.
+func upsertEventQuestions(ctx context.Context, tx *sqlx.Tx, eventID string, questions []dto.EventQuestionDto) ([]model.EventQuestion, error) {
+	updateQuery := `
+		UPDATE event_questions
+		SET label = $1, field_type = $2, options = $3, is_required = $4, sort_order = $5
+		WHERE id = $6 AND event_id = $7 AND archived_at IS NULL
+		RETURNING id, event_id, label, field_type, options, is_required, sort_order;
+	`
+
+	saved := make([]model.EventQuestion, 0, len(questions))
+	var newQuestions []dto.EventQuestionDto
+	keptIDs := []string{}
+	for _, q := range questions {
+		if q.ID == "" {
+			newQuestions = append(newQuestions, q)
+			continue
+		}
+
+		options, err := encodeQuestionOptions(q)
+		if err != nil {
+			return nil, err
+		}
+
+		var question model.EventQuestion
+		err = tx.GetContext(ctx, &question, updateQuery, q.Label, q.FieldType, options, q.IsRequired, q.SortOrder, q.ID, eventID)
+		if err == sql.ErrNoRows {
+			return nil, appError.NewValidationError(fmt.Sprintf("Question %s does not belong to this event", q.ID), nil)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update question %q: %w", q.Label, err)
+		}
+		saved = append(saved, question)
+		keptIDs = append(keptIDs, q.ID)
+	}
+
+	// Removed questions, unanswered ones go and answered ones are archived
+	deleteQuery := `
+		DELETE FROM event_questions q
+		WHERE q.event_id = $1 AND q.archived_at IS NULL AND NOT (q.id = ANY($2::UUID[]))
+			AND NOT EXISTS (SELECT 1 FROM booking_answers a WHERE a.question_id = q.id);
+	`
+	if _, err := tx.ExecContext(ctx, deleteQuery, eventID, pq.Array(keptIDs)); err != nil {
+		return nil, fmt.Errorf("failed to delete removed questions: %w", err)
+	}
+	archiveQuery := `
+		UPDATE event_questions SET archived_at = NOW()
+		WHERE event_id = $1 AND archived_at IS NULL AND NOT (id = ANY($2::UUID[]));
+	`
+	if _, err := tx.ExecContext(ctx, archiveQuery, eventID, pq.Array(keptIDs)); err != nil {
+		return nil, fmt.Errorf("failed to archive removed questions: %w", err)
+	}
+
+	created, err := insertEventQuestions(ctx, tx, eventID, newQuestions)
+	if err != nil {
+		return nil, err
+	}
+	return append(saved, created...), nil
+}
+
+// encodeQuestionOptions returns the JSONB value of a question's choices, NULL unless choices are given.
+func encodeQuestionOptions(q dto.EventQuestionDto) ([]byte, error) {
+	if len(q.Options) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(q.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode options for question %q: %w", q.Label, err)
+	}
+	return encoded, nil
+}
+
+// getEventQuestions returns the booking questions of an event in display order.
+func (e *Controller) getEventQuestions(ctx context.Context, eventID string) ([]model.EventQuestion, error) {
+	questions := []model.EventQuestion{}
+	query := `
+		SELECT id, event_id, label, field_type, options, is_required, sort_order
+		FROM event_questions
+		WHERE event_id = $1 AND archived_at IS NULL
+		ORDER BY sort_order ASC, label ASC;
+	`
+	if err := e.db.SelectContext(ctx, &questions, query, eventID); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/pkg/feature"
+)
+
+// GET /features
+// Public, lets the frontend hide features that are switched off for this deployment.
+func (f *Controller) GetFeatures(w http.ResponseWriter, r *http.Request) {
+	response := map[string]any{
+		"message":  "Fetched features successfully",
+		"features": feature.All(),
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
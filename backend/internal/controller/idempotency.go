@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	idempotencyKeyHeader    = "X-Idempotency-Key"
+	idempotencyKeyMaxLength = 255
+)
+
+// idempotentResponse is a response stored under an idempotency key.
+type idempotentResponse struct {
+	ResponseBody json.RawMessage `db:"response_body"`
+	StatusCode   int             `db:"status_code"`
+}
+
+// idempotencyKey scopes the client supplied key so the same key sent to a
+// different resource is treated as a new request.
+func idempotencyKey(scope, key string) string {
+	return scope + ":" + key
+}
+
+// getIdempotentResponse returns the stored response for key, or nil if there is none or it has expired.
+func (c *Controller) getIdempotentResponse(ctx context.Context, key string) (*idempotentResponse, error) {
+	var stored idempotentResponse
+	query := `SELECT response_body, status_code FROM idempotency_keys WHERE key = $1 AND expires_at > NOW();`
+	err := c.db.GetContext(ctx, &stored, query, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// storeIdempotentResponse saves the response for key inside tx for 24 hours. It returns
+// false when a live entry already exists, meaning a concurrent request with the same key won.
+func storeIdempotentResponse(ctx context.Context, tx *sqlx.Tx, key string, statusCode int, body any) (bool, error) {
+	responseBody, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+
+	query := `
+		INSERT INTO idempotency_keys (key, response_body, status_code, expires_at)
+		VALUES ($1, $2, $3, NOW() + INTERVAL '24 hours')
+		ON CONFLICT (key) DO UPDATE
+		SET response_body = EXCLUDED.response_body,
+			status_code = EXCLUDED.status_code,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW();
+	`
+	result, err := tx.ExecContext(ctx, query, key, responseBody, statusCode)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// writeIdempotentResponse replays a stored response.
+func writeIdempotentResponse(w http.ResponseWriter, stored *idempotentResponse) {
+	w.Header().Set("Idempotent-Replayed", "true")
+	helper.ResponseJson(w, stored.StatusCode, stored.ResponseBody)
+}
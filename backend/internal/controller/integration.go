@@ -1,445 +1,802 @@
-package controller
-
-import (
-	"context"
-	"database/sql"
-	"encoding/base64"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
-	"os"
-	"slices"
-	"strings"
-
-	"github.com/fazamuttaqien/calendly/helper"
-	"github.com/fazamuttaqien/calendly/internal/model"
-	"github.com/fazamuttaqien/calendly/middleware"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"github.com/go-chi/chi/v5"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-)
-
-// GET /me/integrations
-func (i *Controller) GetUserIntegrations(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	var userIntegrations []model.Integration
-
-	query := `SELECT app_type FROM integrations WHERE user_id = $1 AND is_connected = TRUE;`
-
-	err := i.db.SelectContext(ctx, &userIntegrations, query, userID)
-	if err != nil && err != sql.ErrNoRows {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch user integrations", err))
-		return
-	}
-
-	connectedMap := make(map[enum.IntegrationAppType]bool)
-	for _, integration := range userIntegrations {
-		connectedMap[enum.IntegrationAppType(integration.AppType)] = true
-	}
-
-	integrations := make([]IntegrationStatus, 0, len(enum.AllIntegrationAppType()))
-	for _, appType := range enum.AllIntegrationAppType() {
-		// Use maps define above to get details
-		provider, _ := appTypeToProviderMap[appType] //	Handle missing entries if maps aren't exclusive
-		category, _ := appTypeToCategoryMap[appType]
-		title, _ := appTypeToTitleMap[appType]
-
-		integrations = append(integrations, IntegrationStatus{
-			Provider:    provider,
-			Title:       title,
-			AppType:     appType,
-			Category:    category,
-			IsConnected: connectedMap[appType],
-		})
-	}
-
-	response := map[string]any{
-		"message":      "Fetched user integrations successfully",
-		"integrations": integrations,
-	}
-
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// GET /me/integrations/check/{appType}
-func (i *Controller) CheckIntegration(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	appTypeStr := chi.URLParam(r, "appType")
-	if appTypeStr == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing appType in path", nil))
-		return
-	}
-
-	// Convert string to enum type
-	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
-	isValid := slices.Contains(enum.AllIntegrationAppType(), appType)
-	if !isValid {
-		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
-		return
-	}
-
-	var isConnected bool
-	query := `SELECT EXISTS (SELECT 1 FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE);`
-
-	err := i.db.GetContext(ctx, &isConnected, query, userID, appType)
-	if err != nil {
-		// Do not treat ErrNoRows as error, GetContext handles it correctly for EXISTS
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check integration existence", err))
-		return
-	}
-
-	response := map[string]any{
-		"message":     "Integration checked successfully",
-		"isConnected": isConnected,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// POST /me/integrations/connect/{appType}
-func (i *Controller) ConnectApp(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	appTypeStr := chi.URLParam(r, "appType")
-	if appTypeStr == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing appType in path", nil))
-		return
-	}
-
-	// Convert string to enum type
-	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
-	isValid := slices.Contains(enum.AllIntegrationAppType(), appType)
-	if !isValid {
-		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
-		return
-	}
-
-	stateData := OAuthState{
-		UserID:  userID,
-		AppType: appType,
-	}
-
-	stateString, err := EncodeState(stateData)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to encode state", err))
-		return
-	}
-
-	var authUrl string
-
-	switch appType {
-	case enum.AppGoogleMeetAndCalendar:
-		// Add options for offline access (refresh token) and consent prompt
-		opts := []oauth2.AuthCodeOption{
-			oauth2.AccessTypeOffline,
-			oauth2.ApprovalForce, // Equivalent to prompt=consent
-		}
-		authUrl = googleOAuthConfig.AuthCodeURL(stateString, opts...)
-
-	case enum.AppZoomMeeting, enum.AppOutlookCalendar:
-		// TODO: Implement OAuth flow for Zoom and Microsoft
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Unsupported app type for connection", nil))
-		return
-	default:
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Unknown app type", nil))
-		return
-	}
-
-	response := map[string]any{
-		"url": authUrl,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-// GET /auth/google/callback
-// NOTE: This handler usually DOES NOT have the JWT AuthMiddleware applied.
-func (i *Controller) GoogleOAuthCallback(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	query := r.URL.Query()
-	code := query.Get("code")
-	stateEncoded := query.Get("state")
-
-	// --- Build Redirect URL (helper) ---
-	buildRedirectURL := func(appType enum.IntegrationAppType, queryParams map[string]string) string {
-		// Start with base frontend URL + app type indicator
-		baseURL := i.frontendUrl
-		if !strings.Contains(baseURL, "?") {
-			baseURL += "?"
-		} else if !strings.HasSuffix(baseURL, "&") && !strings.HasSuffix(baseURL, "?") {
-			baseURL += "&"
-		}
-		// Ensure app_type is always present, even on error, for context on FE
-		redirectURL := fmt.Sprintf("%sapp_type=%s", baseURL, url.QueryEscape(string(appType)))
-
-		// Append other query parameters (like error or success)
-		for key, val := range queryParams {
-			redirectURL += fmt.Sprintf("&%s=%s", url.QueryEscape(key), url.QueryEscape(val))
-		}
-		return redirectURL
-	}
-
-	// --- State Validation ---
-	if stateEncoded == "" {
-		// Redirect with generic error, as we don't know the appType yet
-		errorRedirectURL := fmt.Sprintf("%s?error=%s", i.frontendUrl, url.QueryEscape("Invalid state parameter"))
-		http.Redirect(w, r, errorRedirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-
-	state, err := DecodeState(stateEncoded) // Use DecodeState from services
-	// Use state.AppType to build specific redirect URL on error
-	if err != nil {
-		redirectURL := buildRedirectURL(
-			state.AppType,
-			map[string]string{"error": "Invalid state parameter"},
-		)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-	if state.UserID == "" {
-		redirectURL := buildRedirectURL(
-			state.AppType,
-			map[string]string{"error": "UserID is required in state"},
-		)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-	// Add CSRF validation here if implemented
-
-	// --- Code Validation ---
-	if code == "" {
-		redirectURL := buildRedirectURL(
-			state.AppType,
-			map[string]string{"error": "Invalid authorization code"},
-		)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-
-	// --- Token Exchange ---
-	// Use global or service's oauth config
-	token, err := GetGoogleOAuthConfig().Exchange(ctx, code)
-	if err != nil {
-		errMsg := fmt.Sprintf("Failed to exchange token: %v", err)
-		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": errMsg})
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-	if !token.Valid() || token.AccessToken == "" {
-		redirectURL := buildRedirectURL(
-			state.AppType,
-			map[string]string{"error": "Invalid token received"},
-		)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-
-	// --- Prepare Integration Data ---
-	var expiryDate sql.NullInt64
-	if !token.Expiry.IsZero() {
-		expiryDate = sql.NullInt64{Int64: token.Expiry.Unix(), Valid: true}
-	}
-	refreshToken := sql.NullString{String: token.RefreshToken, Valid: token.RefreshToken != ""}
-
-	// Extract metadata (example for Google)
-	metadata := map[string]any{
-		"scope":      token.Extra("scope"),
-		"token_type": token.TokenType,
-		// Add other relevant metadata if needed
-	}
-
-	data := CreateIntegration{
-		UserID:       state.UserID,
-		AppType:      state.AppType,
-		AccessToken:  token.AccessToken,
-		RefreshToken: refreshToken,
-		ExpiryDate:   expiryDate,
-		Metadata:     metadata,
-	}
-
-	// --- Create Integration in DB ---
-	// Determine Provider and Category from AppType
-	provider, okP := appTypeToProviderMap[data.AppType]
-	category, okC := appTypeToCategoryMap[data.AppType]
-	if !okP || !okC {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Invalid app type provided", nil))
-		return
-	}
-
-	// Marshal metadata to JSONB
-	metadataJSON, err := json.Marshal(data.Metadata)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to marshal metadata", err))
-		return
-	}
-
-	// Use ON CONFLICT to handle existing integrations (UPSERT)
-	var integration model.Integration
-	queryIntegrations := `
-		INSERT INTO integrations (
-			user_id, provider, category, app_type, access_token,
-			refresh_token, expiry_date, metadata, is_connected,
-			created_at, updated_at
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
-		)
-		RETURNING id, user_id, provider, category, app_type, access_token, refresh_token, expiry_date, metadata, is_connected, created_at, updated_at;
-	`
-	// ON CONFLICT (user_id, app_type) DO UPDATE SET
-	// 	access_token = EXCLUDED.access_token,
-	// 	refresh_token = EXCLUDED.refresh_token,
-	// 	expiry_date = EXCLUDED.expiry_date,
-	// 	metadata = EXCLUDED.metadata,
-	// 	is_connected = TRUE, -- Ensure it's marked connected on update
-	// 	updated_at = CURRENT_TIMESTAMP
-
-	if err := i.db.GetContext(ctx, &integration, queryIntegrations,
-		data.UserID, provider, category, data.AppType, data.AccessToken,
-		data.RefreshToken, data.ExpiryDate, metadataJSON,
-	); err != nil {
-		errMsg := fmt.Sprintf("Failed to save integration: %v", err)
-		// Check if it's a known error like "already connected" if CreateIntegration uses ON CONFLICT
-		if appErr, ok := err.(*appError.AppError); ok && appErr.Code == enum.BadRequest {
-			errMsg = appErr.Error()
-		}
-		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": errMsg})
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
-		return
-	}
-
-	// --- Success Redirect ---
-	successRedirectURL := buildRedirectURL(state.AppType, map[string]string{"success": "true"})
-	http.Redirect(w, r, successRedirectURL, http.StatusTemporaryRedirect)
-}
-
-// Helper function (example) to update token data in DB - call this after refreshing!
-func (i *Controller) UpdateIntegrationToken(ctx context.Context, userID string, appType enum.IntegrationAppType, newToken *oauth2.Token) error {
-	expiryUnix := sql.NullInt64{Valid: false}
-	if !newToken.Expiry.IsZero() {
-		expiryUnix = sql.NullInt64{Int64: newToken.Expiry.Unix(), Valid: true}
-	}
-	refreshToken := sql.NullString{Valid: false}
-	if newToken.RefreshToken != "" {
-		refreshToken = sql.NullString{String: newToken.RefreshToken, Valid: true}
-	}
-
-	query := `
-        UPDATE integrations SET
-            access_token = $1,
-            refresh_token = $2,
-            expiry_date = $3,
-            updated_at = CURRENT_TIMESTAMP
-        WHERE user_id = $4 AND app_type = $5;
-    `
-	_, err := i.db.ExecContext(ctx, query, newToken.AccessToken, refreshToken, expiryUnix, userID, appType)
-	if err != nil {
-		return appError.NewAppError(enum.InternalServerError, "Failed to update integration token in DB", err)
-	}
-	return nil
-}
-
-// --- Mappings ---
-
-var (
-	appTypeToProviderMap = map[enum.IntegrationAppType]enum.IntegrationProvider{
-		enum.AppGoogleMeetAndCalendar: enum.ProviderGoogle,
-		enum.AppZoomMeeting:           enum.ProviderZoom,
-		enum.AppOutlookCalendar:       enum.ProviderMicrosoft,
-	}
-
-	appTypeToCategoryMap = map[enum.IntegrationAppType]enum.IntegrationCategory{
-		enum.AppGoogleMeetAndCalendar: enum.CategoryCalendarAndVideo,
-		enum.AppZoomMeeting:           enum.CategoryVideo,
-		enum.AppOutlookCalendar:       enum.CategoryCalendar,
-	}
-
-	appTypeToTitleMap = map[enum.IntegrationAppType]string{
-		enum.AppGoogleMeetAndCalendar: "Google Meet & Calendar",
-		enum.AppZoomMeeting:           "Zoom",
-		enum.AppOutlookCalendar:       "Outlook Calendar",
-	}
-)
-
-// --- OAuth2 Configuration (Global or within Service) ---
-
-var googleOAuthConfig *oauth2.Config
-
-func init() {
-	// Initialize Google OAuth2 Config
-	googleOAuthConfig = &oauth2.Config{
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URI"),
-		Scopes: []string{
-			"https://www.googleapis.com/auth/calendar.events",
-			// Add other required scopes here
-		},
-		Endpoint: google.Endpoint,
-	}
-}
-
-func GetGoogleOAuthConfig() *oauth2.Config {
-	// Ensure googleOAuthConfig is initialized (e.g., in init())
-	if googleOAuthConfig == nil {
-		panic("Google OAuth2 config not initialized")
-	}
-	return googleOAuthConfig
-}
-
-// --- State Encoding/Decoding ---
-
-// OAuthState represents the data encoded in the OAuth state parameter.
-type OAuthState struct {
-	UserID  string                  `json:"userId"`
-	AppType enum.IntegrationAppType `json:"appType"`
-	// Add other relevant fields like redirect URL, CSRF token etc. if needed
-}
-
-// EncodeState encodes state data into a Base64 string.
-func EncodeState(state OAuthState) (string, error) {
-	jsonData, err := json.Marshal(state)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal state: %w", err)
-	}
-	return base64.URLEncoding.EncodeToString(jsonData), nil
-}
-
-// DecodeState decodes a Base64 string back into state data.
-func DecodeState(encodedState string) (OAuthState, error) {
-	jsonData, err := base64.URLEncoding.DecodeString(encodedState)
-	if err != nil {
-		return OAuthState{}, fmt.Errorf("failed to decode base64 state: %w", err)
-	}
-
-	var state OAuthState
-	err = json.Unmarshal(jsonData, &state)
-	if err != nil {
-		return OAuthState{}, fmt.Errorf("failed to unmarshal state JSON: %w", err)
-	}
-
-	return state, nil
-}
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/crypto"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/feature"
+	"github.com/fazamuttaqien/calendly/pkg/oauth"
+	"github.com/fazamuttaqien/calendly/pkg/tracing"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/fazamuttaqien/calendly/pkg/zoom"
+	"github.com/go-chi/chi/v5"
+	"github.com/lib/pq"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// GET /me/integrations
+func (i *Controller) GetUserIntegrations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	listQuery, ok := validator.GetValidatedDTOFromContext[dto.IntegrationListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	limit := listQuery.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	var userIntegrations []model.Integration
+
+	query := `SELECT app_type FROM integrations WHERE user_id = $1 AND is_connected = TRUE;`
+
+	err := i.db.SelectContext(ctx, &userIntegrations, query, userID)
+	if err != nil && err != sql.ErrNoRows {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch user integrations", err))
+		return
+	}
+
+	connectedMap := make(map[enum.IntegrationAppType]bool)
+	for _, integration := range userIntegrations {
+		connectedMap[enum.IntegrationAppType(integration.AppType)] = true
+	}
+
+	// Connected integrations come first, the page is taken from the whole catalogue in that order
+	var connectedTypes, availableTypes []enum.IntegrationAppType
+	for _, appType := range enum.AllIntegrationAppType() {
+		if connectedMap[appType] {
+			connectedTypes = append(connectedTypes, appType)
+		} else {
+			availableTypes = append(availableTypes, appType)
+		}
+	}
+	catalogue := append(connectedTypes, availableTypes...)
+
+	total := len(catalogue)
+	start := min(listQuery.Offset, total)
+	end := min(start+limit, total)
+
+	statuses := make([]IntegrationStatus, 0, end-start)
+	page := IntegrationStatusPage{
+		Connected: []IntegrationStatus{},
+		Available: []IntegrationStatus{},
+	}
+	for _, appType := range catalogue[start:end] {
+		// Use maps define above to get details
+		provider, _ := appTypeToProviderMap[appType] //	Handle missing entries if maps aren't exclusive
+		category, _ := appTypeToCategoryMap[appType]
+		title, _ := appTypeToTitleMap[appType]
+
+		status := IntegrationStatus{
+			Provider:    provider,
+			Title:       title,
+			AppType:     appType,
+			Category:    category,
+			IsConnected: connectedMap[appType],
+		}
+		statuses = append(statuses, status)
+		if status.IsConnected {
+			page.Connected = append(page.Connected, status)
+		} else {
+			page.Available = append(page.Available, status)
+		}
+	}
+
+	links := helper.BuildPaginationLinks(r, limit, listQuery.Offset, total)
+	response := IntegrationListResponse{
+		PaginatedResponse:     helper.NewPaginatedResponse("Fetched user integrations successfully", statuses, total, limit, listQuery.Offset),
+		IntegrationStatusPage: page,
+	}
+	response.Links = &links
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /me/integrations/check/{appType}
+func (i *Controller) CheckIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	appTypeStr := chi.URLParam(r, "appType")
+	if appTypeStr == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing appType in path", nil))
+		return
+	}
+
+	// Convert string to enum type
+	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
+	isValid := slices.Contains(enum.AllIntegrationAppType(), appType)
+	if !isValid {
+		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+		return
+	}
+
+	var isConnected bool
+	query := `SELECT EXISTS (SELECT 1 FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE);`
+
+	err := i.db.GetContext(ctx, &isConnected, query, userID, appType)
+	if err != nil {
+		// Do not treat ErrNoRows as error, GetContext handles it correctly for EXISTS
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check integration existence", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":     "Integration checked successfully",
+		"isConnected": isConnected,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /integration/{appType}/health
+// Verifies the stored token still works with a lightweight provider call,
+// so the frontend can prompt a reconnect before a booking fails.
+func (i *Controller) CheckIntegrationHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	appTypeStr := chi.URLParam(r, "appType")
+	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
+	if !slices.Contains(enum.AllIntegrationAppType(), appType) {
+		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+		return
+	}
+	if appType != enum.AppGoogleMeetAndCalendar {
+		msg := fmt.Sprintf("Health check is not supported for %s", appType)
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+		return
+	}
+
+	// 1. Fetch the connected integration
+	var integration model.Integration
+	query := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
+	err := i.db.GetContext(ctx, &integration, query, userID, appType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Integration", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch integration", err))
+		return
+	}
+
+	// 2. Refresh the token if needed, then make the cheapest authenticated call
+	calendarSvc, _, err := GetCalendarClient(ctx, i.db, integration)
+	if err == nil {
+		_, err = calendarSvc.CalendarList.List().MaxResults(1).Context(ctx).Do()
+	}
+	if err != nil {
+		log.Printf("Warning: Integration health check failed (IntegrationID: %s): %v\n", integration.ID, err)
+		response := map[string]any{
+			"isHealthy": false,
+			"appType":   appType,
+			"error":     describeIntegrationError(err),
+		}
+		helper.ResponseJson(w, http.StatusOK, response)
+		return
+	}
+
+	response := map[string]any{
+		"isHealthy": true,
+		"appType":   appType,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// POST /me/integrations/connect/{appType}
+func (i *Controller) ConnectApp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	appTypeStr := chi.URLParam(r, "appType")
+	if appTypeStr == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing appType in path", nil))
+		return
+	}
+
+	// Convert string to enum type
+	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
+	isValid := slices.Contains(enum.AllIntegrationAppType(), appType)
+	if !isValid {
+		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+		return
+	}
+
+	stateData := oauth.OAuthState{
+		UserID:  userID,
+		AppType: appType,
+	}
+
+	stateString, err := oauth.SignState(stateData)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to encode state", err))
+		return
+	}
+
+	var authUrl string
+
+	// PKCE: an intercepted code is useless without the verifier, which never leaves the server.
+	// The stored verifier is consumed by the callback, so each state can only be used once.
+	savePKCEVerifier := func() (string, bool) {
+		verifier := oauth2.GenerateVerifier()
+		if err := i.savePKCEVerifier(ctx, stateString, verifier); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to store PKCE verifier", err))
+			return "", false
+		}
+		return verifier, true
+	}
+
+	switch appType {
+	case enum.AppGoogleMeetAndCalendar:
+		verifier, ok := savePKCEVerifier()
+		if !ok {
+			return
+		}
+
+		// Add options for offline access (refresh token) and consent prompt
+		opts := []oauth2.AuthCodeOption{
+			oauth2.AccessTypeOffline,
+			oauth2.ApprovalForce, // Equivalent to prompt=consent
+			oauth2.S256ChallengeOption(verifier),
+		}
+		authUrl = googleOAuthConfig.AuthCodeURL(stateString, opts...)
+
+	case enum.AppZoomMeeting:
+		if !feature.IsEnabled(feature.FeatureZoomIntegration) {
+			appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Unsupported app type for connection", nil))
+			return
+		}
+		verifier, ok := savePKCEVerifier()
+		if !ok {
+			return
+		}
+		authUrl = zoomOAuthConfig.AuthCodeURL(stateString, oauth2.S256ChallengeOption(verifier))
+
+	case enum.AppOutlookCalendar:
+		// TODO: Implement OAuth flow for Microsoft, the flag only reserves the rollout
+		if !feature.IsEnabled(feature.FeatureOutlookIntegration) {
+			appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Unsupported app type for connection", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Outlook Calendar connection is not implemented yet", nil))
+		return
+	default:
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Unknown app type", nil))
+		return
+	}
+
+	response := map[string]any{
+		"url": authUrl,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// DELETE /integration/{appType}
+func (i *Controller) DisconnectIntegration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	appTypeStr := chi.URLParam(r, "appType")
+	if appTypeStr == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing appType in path", nil))
+		return
+	}
+
+	// Convert string to enum type
+	appType := enum.IntegrationAppType(strings.ToUpper(appTypeStr))
+	isValid := slices.Contains(enum.AllIntegrationAppType(), appType)
+	if !isValid {
+		msg := fmt.Sprintf("Invalid appType provided: %s", appTypeStr)
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+		return
+	}
+
+	// 1. Fetch the connected integration
+	var integration model.Integration
+	query := `
+		SELECT id, app_type, access_token, refresh_token
+		FROM integrations
+		WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;
+	`
+	err := i.db.GetContext(ctx, &integration, query, userID, appType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Integration", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch integration", err))
+		return
+	}
+
+	// 2. Revoke the token at the provider, the local disconnect goes ahead even if this fails
+	revokeIntegrationToken(ctx, integration)
+
+	// 3. Mark disconnected and make dependent events private in one transaction
+	tx, err := i.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	disconnectQuery := `
+		UPDATE integrations
+		SET is_connected = FALSE, access_token = '', refresh_token = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND app_type = $2;
+	`
+	if _, err := tx.ExecContext(ctx, disconnectQuery, userID, appType); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to disconnect integration", err))
+		return
+	}
+
+	// Events relying only on the integration can no longer be booked, hybrid events keep their other location types
+	locationTypes := pq.StringArray{}
+	for _, loc := range enum.AllEventLocationType() {
+		if required, ok := IntegrationAppTypeFromEventLocation(loc); ok && required == appType {
+			locationTypes = append(locationTypes, string(loc))
+		}
+	}
+	if len(locationTypes) > 0 {
+		eventsQuery := `
+			UPDATE events
+			SET is_private = TRUE, updated_at = CURRENT_TIMESTAMP
+			WHERE user_id = $1 AND location_types <@ $2 AND deleted_at IS NULL;
+		`
+		if _, err := tx.ExecContext(ctx, eventsQuery, userID, locationTypes); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update events for disconnected integration", err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	// Events may have been made private above
+	i.invalidatePublicEvents(ctx, userID)
+
+	i.recordAudit(r, audit.AuditEntry{
+		UserID:     userID,
+		Action:     audit.ActionIntegrationDisconnect,
+		EntityType: audit.EntityIntegration,
+		EntityID:   integration.ID,
+		Metadata:   map[string]any{"appType": appType},
+	})
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Integration disconnected successfully"})
+}
+
+// revokeIntegrationToken revokes the integration's OAuth token at the provider (best effort).
+// integration must have its id, app_type and token columns loaded.
+func revokeIntegrationToken(ctx context.Context, integration model.Integration) {
+	if integration.AppType != enum.AppGoogleMeetAndCalendar {
+		return
+	}
+
+	// Revoking the refresh token also revokes the access tokens issued from it
+	encryptedToken := integration.AccessToken.String
+	if integration.RefreshToken.Valid && integration.RefreshToken.String != "" {
+		encryptedToken = integration.RefreshToken.String
+	}
+
+	token, err := crypto.DecryptString(encryptedToken)
+	if err != nil {
+		log.Printf("Warning: Failed to decrypt token for revocation (IntegrationID: %s): %v\n", integration.ID, err)
+		return
+	}
+
+	revokeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := RevokeGoogleToken(revokeCtx, token); err != nil {
+		log.Printf("Warning: Failed to revoke Google token (IntegrationID: %s): %v\n", integration.ID, err)
+	}
+}
+
+// savePKCEVerifier stores the PKCE code verifier of an OAuth flow until the callback for state arrives.
+// Verifiers of abandoned flows are purged along the way.
+func (i *Controller) savePKCEVerifier(ctx context.Context, state, verifier string) error {
+	if _, err := i.db.ExecContext(ctx, `DELETE FROM oauth_pkce_verifiers WHERE expires_at < NOW();`); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO oauth_pkce_verifiers (state_hash, code_verifier, expires_at) VALUES ($1, $2, $3);`
+	_, err := i.db.ExecContext(ctx, query, pkceStateHash(state), verifier, time.Now().Add(oauth.StateTTL))
+	return err
+}
+
+// takePKCEVerifier returns and deletes the unexpired PKCE code verifier stored for state.
+func (i *Controller) takePKCEVerifier(ctx context.Context, state string) (string, error) {
+	query := `
+		DELETE FROM oauth_pkce_verifiers
+		WHERE state_hash = $1
+		RETURNING code_verifier, expires_at > NOW() AS valid;
+	`
+	var row struct {
+		Verifier string `db:"code_verifier"`
+		Valid    bool   `db:"valid"`
+	}
+	if err := i.db.GetContext(ctx, &row, query, pkceStateHash(state)); err != nil {
+		return "", err
+	}
+	if !row.Valid {
+		return "", oauth.ErrExpiredState
+	}
+	return row.Verifier, nil
+}
+
+// pkceStateHash keys verifiers by a hash of the signed state, which is unique per flow
+func pkceStateHash(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return hex.EncodeToString(sum[:])
+}
+
+// GET /integration/google/callback, GET /integration/zoom/callback
+// NOTE: This handler usually DOES NOT have the JWT AuthMiddleware applied.
+// The signed state carries the app type, which picks the OAuth config to exchange the code with.
+func (i *Controller) GoogleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+	code := query.Get("code")
+	stateEncoded := query.Get("state")
+
+	// --- Build Redirect URL (helper) ---
+	buildRedirectURL := func(appType enum.IntegrationAppType, queryParams map[string]string) string {
+		// Start with base frontend URL + app type indicator
+		baseURL := i.frontendUrl
+		if !strings.Contains(baseURL, "?") {
+			baseURL += "?"
+		} else if !strings.HasSuffix(baseURL, "&") && !strings.HasSuffix(baseURL, "?") {
+			baseURL += "&"
+		}
+		// Ensure app_type is always present, even on error, for context on FE
+		redirectURL := fmt.Sprintf("%sapp_type=%s", baseURL, url.QueryEscape(string(appType)))
+
+		// Append other query parameters (like error or success)
+		for key, val := range queryParams {
+			redirectURL += fmt.Sprintf("&%s=%s", url.QueryEscape(key), url.QueryEscape(val))
+		}
+		return redirectURL
+	}
+
+	// --- State Validation ---
+	if stateEncoded == "" {
+		// Redirect with generic error, as we don't know the appType yet
+		errorRedirectURL := fmt.Sprintf("%s?error=%s", i.frontendUrl, url.QueryEscape("Invalid state parameter"))
+		http.Redirect(w, r, errorRedirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+
+	// Signature covers the whole state, including the CSRF token and expiry
+	state, err := oauth.VerifyAndDecodeState(stateEncoded)
+	// Use state.AppType to build specific redirect URL on error
+	if err != nil {
+		redirectURL := buildRedirectURL(
+			state.AppType,
+			map[string]string{"error": "Invalid state parameter"},
+		)
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+	if state.UserID == "" {
+		redirectURL := buildRedirectURL(
+			state.AppType,
+			map[string]string{"error": "UserID is required in state"},
+		)
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+
+	// --- Code Validation ---
+	if code == "" {
+		redirectURL := buildRedirectURL(
+			state.AppType,
+			map[string]string{"error": "Invalid authorization code"},
+		)
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+
+	// --- Token Exchange ---
+	// Use global or service's oauth config
+	exchangeCtx, span := tracing.Start(ctx, "google.oauth.exchange")
+	oauthConfig := GetGoogleOAuthConfig()
+	if state.AppType == enum.AppZoomMeeting {
+		oauthConfig = zoomOAuthConfig
+	}
+	// Single use for every provider, a replayed state finds no verifier
+	verifier, err := i.takePKCEVerifier(ctx, stateEncoded)
+	if err != nil {
+		tracing.End(span, err)
+		log.Printf("Warning: PKCE verifier not found for OAuth callback (UserID: %s): %v\n", state.UserID, err)
+		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": "Authorization session expired, please try again"})
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+	token, err := oauthConfig.Exchange(exchangeCtx, code, oauth2.VerifierOption(verifier))
+	tracing.End(span, err)
+	if err != nil {
+		errMsg := fmt.Sprintf("Failed to exchange token: %v", err)
+		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": errMsg})
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+	if !token.Valid() || token.AccessToken == "" {
+		redirectURL := buildRedirectURL(
+			state.AppType,
+			map[string]string{"error": "Invalid token received"},
+		)
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+
+	// --- Prepare Integration Data ---
+	var expiryDate sql.NullInt64
+	if !token.Expiry.IsZero() {
+		expiryDate = sql.NullInt64{Int64: token.Expiry.Unix(), Valid: true}
+	}
+
+	// Encrypt tokens before they reach the database
+	accessToken, err := crypto.EncryptString(token.AccessToken)
+	if err != nil {
+		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": "Failed to secure access token"})
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+	var refreshToken sql.NullString
+	if token.RefreshToken != "" {
+		encryptedRefresh, err := crypto.EncryptString(token.RefreshToken)
+		if err != nil {
+			redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": "Failed to secure refresh token"})
+			http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+			return
+		}
+		refreshToken = sql.NullString{String: encryptedRefresh, Valid: true}
+	}
+
+	// Extract metadata (example for Google)
+	metadata := map[string]any{
+		"scope":      token.Extra("scope"),
+		"token_type": token.TokenType,
+		// Add other relevant metadata if needed
+	}
+
+	data := CreateIntegration{
+		UserID:       state.UserID,
+		AppType:      state.AppType,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiryDate:   expiryDate,
+		Metadata:     metadata,
+	}
+
+	// --- Create Integration in DB ---
+	// Determine Provider and Category from AppType
+	provider, okP := appTypeToProviderMap[data.AppType]
+	category, okC := appTypeToCategoryMap[data.AppType]
+	if !okP || !okC {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Invalid app type provided", nil))
+		return
+	}
+
+	// Marshal metadata to JSONB
+	metadataJSON, err := json.Marshal(data.Metadata)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to marshal metadata", err))
+		return
+	}
+
+	// Use ON CONFLICT to handle existing integrations (UPSERT), reconnecting reuses the row
+	var integration model.Integration
+	queryIntegrations := `
+		INSERT INTO integrations (
+			user_id, provider, category, app_type, access_token,
+			refresh_token, expiry_date, metadata, is_connected,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		)
+		ON CONFLICT (user_id, app_type) DO UPDATE SET
+			access_token = EXCLUDED.access_token,
+			refresh_token = EXCLUDED.refresh_token,
+			expiry_date = EXCLUDED.expiry_date,
+			metadata = EXCLUDED.metadata,
+			is_connected = TRUE, -- Ensure it's marked connected on update
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, user_id, provider, category, app_type, access_token, refresh_token, expiry_date, metadata, is_connected, created_at, updated_at;
+	`
+
+	if err := i.db.GetContext(ctx, &integration, queryIntegrations,
+		data.UserID, provider, category, data.AppType, data.AccessToken,
+		data.RefreshToken, data.ExpiryDate, metadataJSON,
+	); err != nil {
+		errMsg := fmt.Sprintf("Failed to save integration: %v", err)
+		// Check if it's a known error like "already connected" if CreateIntegration uses ON CONFLICT
+		if errors.Is(err, appError.ErrBadRequest) {
+			errMsg = err.Error()
+		}
+		redirectURL := buildRedirectURL(state.AppType, map[string]string{"error": errMsg})
+		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		return
+	}
+
+	i.recordAudit(r, audit.AuditEntry{
+		UserID:     integration.UserID,
+		Action:     audit.ActionIntegrationConnect,
+		EntityType: audit.EntityIntegration,
+		EntityID:   integration.ID,
+		Metadata:   map[string]any{"appType": integration.AppType},
+	})
+
+	// --- Success Redirect ---
+	successRedirectURL := buildRedirectURL(state.AppType, map[string]string{"success": "true"})
+	http.Redirect(w, r, successRedirectURL, http.StatusTemporaryRedirect)
+}
+
+// UpdateIntegrationToken stores a refreshed token of the user's integration, encrypted like on connect.
+func UpdateIntegrationToken(ctx context.Context, db *database.DB, userID string, appType enum.IntegrationAppType, newToken *oauth2.Token) error {
+	expiryUnix := sql.NullInt64{Valid: false}
+	if !newToken.Expiry.IsZero() {
+		expiryUnix = sql.NullInt64{Int64: newToken.Expiry.Unix(), Valid: true}
+	}
+	accessToken, err := crypto.EncryptString(newToken.AccessToken)
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to encrypt access token", err)
+	}
+	refreshToken := sql.NullString{Valid: false}
+	if newToken.RefreshToken != "" {
+		encryptedRefresh, err := crypto.EncryptString(newToken.RefreshToken)
+		if err != nil {
+			return appError.NewAppError(enum.InternalServerError, "Failed to encrypt refresh token", err)
+		}
+		refreshToken = sql.NullString{String: encryptedRefresh, Valid: true}
+	}
+
+	query := `
+        UPDATE integrations SET
+            access_token = $1,
+            refresh_token = $2,
+            expiry_date = $3,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE user_id = $4 AND app_type = $5;
+    `
+	_, err = db.ExecContext(ctx, query, accessToken, refreshToken, expiryUnix, userID, appType)
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to update integration token in DB", err)
+	}
+	return nil
+}
+
+// --- Mappings ---
+
+var (
+	appTypeToProviderMap = map[enum.IntegrationAppType]enum.IntegrationProvider{
+		enum.AppGoogleMeetAndCalendar: enum.ProviderGoogle,
+		enum.AppZoomMeeting:           enum.ProviderZoom,
+		enum.AppOutlookCalendar:       enum.ProviderMicrosoft,
+	}
+
+	appTypeToCategoryMap = map[enum.IntegrationAppType]enum.IntegrationCategory{
+		enum.AppGoogleMeetAndCalendar: enum.CategoryCalendarAndVideo,
+		enum.AppZoomMeeting:           enum.CategoryVideo,
+		enum.AppOutlookCalendar:       enum.CategoryCalendar,
+	}
+
+	appTypeToTitleMap = map[enum.IntegrationAppType]string{
+		enum.AppGoogleMeetAndCalendar: "Google Meet & Calendar",
+		enum.AppZoomMeeting:           "Zoom",
+		enum.AppOutlookCalendar:       "Outlook Calendar",
+	}
+)
+
+// --- OAuth2 Configuration (Global or within Service) ---
+
+var googleOAuthConfig *oauth2.Config
+
+func init() {
+	// Initialize Google OAuth2 Config
+	googleOAuthConfig = &oauth2.Config{
+		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URI"),
+		Scopes: []string{
+			"https://www.googleapis.com/auth/calendar.events",
+			// Add other required scopes here
+		},
+		Endpoint: google.Endpoint,
+	}
+}
+
+// googleLoginOAuthConfig shares the client credentials but only asks for identity scopes.
+var googleLoginOAuthConfig *oauth2.Config
+
+func init() {
+	googleLoginOAuthConfig = &oauth2.Config{
+		ClientID:     googleOAuthConfig.ClientID,
+		ClientSecret: googleOAuthConfig.ClientSecret,
+		RedirectURL:  os.Getenv("GOOGLE_LOGIN_REDIRECT_URI"),
+		Scopes:       []string{"openid", "profile", "email"},
+		Endpoint:     google.Endpoint,
+	}
+}
+
+// zoomOAuthConfig connects and refreshes Zoom integrations, connecting is behind FEATURE_ZOOM_INTEGRATION.
+// ZOOM_REDIRECT_URI must point at /integration/zoom/callback.
+var zoomOAuthConfig *oauth2.Config
+
+func init() {
+	zoomOAuthConfig = &oauth2.Config{
+		ClientID:     os.Getenv("ZOOM_CLIENT_ID"),
+		ClientSecret: os.Getenv("ZOOM_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("ZOOM_REDIRECT_URI"),
+		Endpoint:     zoom.Endpoint,
+	}
+}
+
+func GetGoogleLoginOAuthConfig() *oauth2.Config {
+	if googleLoginOAuthConfig == nil {
+		panic("Google login OAuth2 config not initialized")
+	}
+	return googleLoginOAuthConfig
+}
+
+func GetGoogleOAuthConfig() *oauth2.Config {
+	// Ensure googleOAuthConfig is initialized (e.g., in init())
+	if googleOAuthConfig == nil {
+		panic("Google OAuth2 config not initialized")
+	}
+	return googleOAuthConfig
+}
@@ -1,368 +1,1505 @@
-package controller
-
-import (
-	"database/sql"
-	"fmt"
-	"log"
-	"net/http"
-	"slices"
-	"strings"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/helper"
-	"github.com/fazamuttaqien/calendly/internal/dto"
-	"github.com/fazamuttaqien/calendly/internal/model"
-	"github.com/fazamuttaqien/calendly/middleware"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"github.com/fazamuttaqien/calendly/pkg/validator"
-
-	"github.com/go-chi/chi/v5"
-	"google.golang.org/api/calendar/v3"
-)
-
-// GET /me/meetings
-func (m *Controller) GetUserMeetings(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	userID, ok := middleware.GetUserIDFromContext(ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewUnauthorizedError(nil))
-		return
-	}
-
-	// Get filter from query param, default to UPCOMING
-	filterQuery := r.URL.Query().Get("filter")
-	var filter enum.MeetingFilter
-
-	switch strings.ToUpper(filterQuery) {
-	case string(enum.MeetingFilterUpcoming):
-		filter = enum.MeetingFilterUpcoming
-	case string(enum.MeetingFilterPast):
-		filter = enum.MeetingFilterPast
-	case string(enum.MeetingFilterCancelled):
-		filter = enum.MeetingFilterCancelled
-	default:
-		// Default to UPCOMING if empty or invalid
-		filter = enum.MeetingFilterUpcoming
-	}
-
-	var meetings []model.Meeting
-
-	var args []any
-	args = append(args, userID)
-
-	// Base query joining meetings and events
-	baseQuery := `
-		SELECT
-			m.*,
-			e.title AS event_title, -- Alias joined event fields
-			e.description AS event_description,
-            e.location_type AS event_location_type
-            -- Add other event fields as needed
-		FROM meetings m
-		JOIN events e ON m.event_id = e.id
-		WHERE m.user_id = $1
-	`
-
-	filterClause := ""
-
-	now := time.Now()
-
-	switch filter {
-	case enum.MeetingFilterUpcoming:
-		filterClause = " AND m.status = $2 AND m.start_time > $3"
-		args = append(args, enum.Scheduled, now)
-	case enum.MeetingFilterPast:
-		filterClause = " AND m.status = $2 AND m.start_time < $3"
-		args = append(args, enum.Scheduled, now)
-	case enum.MeetingFilterCancelled:
-		filterClause = " AND m.status = $2"
-		args = append(args, enum.Cancelled)
-	default: // Default to UPCOMING if filter is invalid or not provided
-		filterClause = " AND m.status = $2 AND m.start_time > $3"
-		args = append(args, enum.Scheduled, now)
-	}
-
-	orderByClause := " ORDER BY m.start_time ASC"
-	finalQuery := baseQuery + filterClause + orderByClause + ";"
-
-	err := m.db.SelectContext(ctx, &meetings, finalQuery, args...)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("No meetings found", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve useer meetings", err))
-		return
-	}
-
-	response := map[string]any{
-		"message":  "Meetings fetched successfully",
-		"meetings": meetings,
-	}
-	helper.ResponseJson(w, http.StatusOK, response)
-}
-
-func (m *Controller) CreateBooking(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateMeetingDto](ctx)
-	if !ok {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
-		return
-	}
-
-	// 1. Parse times
-	// startTime, err := time.Parse(time.RFC3339, dto.StartTime.String()) // Assuming RFC3339 format from DTO
-	// if err != nil {
-	// 	appError.WriteError(w, appError.NewAppError(
-	// 		enums.ValidationError,
-	// 		"Invalid start time format",
-	// 		err,
-	// 	)
-	// }
-
-	// endTime, err := time.Parse(time.RFC3339, dto.EndTime.String()) // Assuming RFC3339 format from DTO
-	// if err != nil {
-	// 	appError.WriteError(w, appError.NewAppError(
-	// 		enums.ValidationError,
-	// 		"Invalid end time format",
-	// 		err,
-	// 	)
-	// }
-
-	startTime := dto.StartTime.Format(time.RFC3339)
-	endTime := dto.EndTime.Format(time.RFC3339)
-
-	// 2. Fetch Event and User
-	var event model.Event // Assuming Event model has UserID field
-	eventQuery := `SELECT e.* FROM events e WHERE e.id = $1 AND e.is_private = FALSE;`
-	err := m.db.GetContext(ctx, &event, eventQuery, dto.EventID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("Public event", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err))
-		return
-	}
-
-	// Simple validation for location type enum (can be improved)
-	isValidLocation := slices.Contains(enum.AllEventLocationType(), event.LocationType)
-	if !isValidLocation {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, fmt.Sprintf("Event has invalid location type: %s", event.LocationType), nil))
-		return
-	}
-
-	// 3. Fetch Integration for the event's use
-	var integration model.Integration
-	// Derive appType from event's locationType
-	requiredAppType, ok := IntegrationAppTypeFromEventLocation(event.LocationType)
-	if !ok {
-		// This check might be redundant if previous validation passed, but safer
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Cannot map event location to integration app type", nil))
-		return
-	}
-
-	integrationQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
-	err = m.db.GetContext(ctx, &integration, integrationQuery, event.UserID, requiredAppType)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			msg := fmt.Sprintf("Required integration '%s' not found or disconnected for the event owner.", requiredAppType)
-			appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch integration", err))
-		return
-	}
-
-	// 4. Interact with Calendar API (if applicable)
-	meetLink := ""
-	calendarEventID := ""
-	calendarAppTypeStr := ""
-
-	if event.LocationType == enum.LocationGoogleMeetAndCalendar {
-		calendarSvc, appType, err := GetCalendarClient(ctx, integration)
-		if err != nil {
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, err.Error(), err))
-			return
-		}
-		calendarAppTypeStr = string(appType) // Store the string representation
-
-		// Create Google Calendar event request
-		calEvent := &calendar.Event{
-			Summary:     fmt.Sprintf("%s - %s", dto.GuestName, event.Title),
-			Description: dto.AdditionalInfo, // Assumes DTO field matches
-			// Start:       &calendar.EventDateTime{DateTime: startTime.Format(time.RFC3339)},
-			Start: &calendar.EventDateTime{DateTime: startTime},
-			// End:         &calendar.EventDateTime{DateTime: endTime.Format(time.RFC3339)},
-			End: &calendar.EventDateTime{DateTime: endTime},
-			Attendees: []*calendar.EventAttendee{
-				{Email: dto.GuestEmail},
-				{Email: integration.User.Email}, // Assuming Integration model has UserEmail fetched or available
-			},
-			ConferenceData: &calendar.ConferenceData{
-				CreateRequest: &calendar.CreateConferenceRequest{
-					RequestId:             fmt.Sprintf("%s-%d", event.ID, time.Now().UnixNano()), // Unique request ID
-					ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"}, // Request Google Meet
-				},
-			},
-		}
-
-		createdCalEvent, err := calendarSvc.Events.Insert("primary", calEvent).ConferenceDataVersion(1).Do()
-		if err != nil {
-			// Log detailed Google API error if possible
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create calendar event", err))
-			return
-		}
-
-		// Extract results
-		meetLink = createdCalEvent.HangoutLink
-		if createdCalEvent.Id == "" {
-			// Handle case where ID might be missing unexpectedly
-			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Created calendar event missing ID", nil))
-			return
-		}
-		calendarEventID = createdCalEvent.Id
-
-	} else {
-		// Handle other location types (e.g., Zoom) if necessary
-		// For now, assume link/id remain empty if not Google
-	}
-
-	// 5. Insert Meeting into Database
-	var createdMeeting model.Meeting
-	insertQuery := `
-	INSERT INTO meetings (
-			user_id, event_id, guest_name, guest_email, additional_info,
-			start_time, end_time, meet_link, calendar_event_id, calendar_app_type,
-			status, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW(), NOW())
-		RETURNING *;
-	`
-	addInfo := sql.NullString{String: dto.AdditionalInfo, Valid: dto.AdditionalInfo != ""}
-
-	err = m.db.GetContext(ctx, &createdMeeting, insertQuery,
-		event.UserID, event.ID, dto.GuestName, dto.GuestEmail, addInfo,
-		startTime, endTime, meetLink, calendarEventID, calendarAppTypeStr,
-		enum.Scheduled, // Default status
-	)
-	if err != nil {
-		// Consider handling specific DB errors like constraint violations
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to save meeting record", err))
-		return
-	}
-
-	response := map[string]any{
-		"message": "Meeting scheduled successfully",
-		"data": map[string]any{
-			"meetLink": meetLink,
-			"meeting":  createdMeeting,
-		},
-	}
-	helper.ResponseJson(w, http.StatusCreated, response)
-}
-
-// DELETE /meetings/{meetingId}
-// NOTE: Assumes authorization is handled within the service layer based on meetingId,
-// or via a separate mechanism (like a unique cancellation token/link) if public cancellation is allowed.
-// If only the host can cancel, this route should be authenticated.
-func (m *Controller) CancelMeeting(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	meetingID := chi.URLParam(r, "meetingId")
-	if meetingID == "" {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
-		return
-	}
-	// Optional: Add UUID validation
-
-	// If host cancellation is required, get userID from context and pass to service:
-	// userID, ok := getUserIDFromContext(ctx)
-	// if !ok {
-	//     apperror.WriteError(w, apperror.NewUnauthorizedError(nil))
-	//     return
-	// }
-	// err := h.meetingService.CancelMeeting(ctx, meetingID, userID) // Modified service signature
-
-	// 1. Fetch Meeting, Event, and User info needed
-	var meeting struct {
-		model.Meeting
-		EventUserID string `db:"event_user_id"`
-	}
-	fetchQuery := `
-		SELECT m.*, e.user_id AS event_user_id
-		FROM meetings m
-		JOIN events e ON m.event_id = e.id
-		WHERE m.id = $1;
-	`
-	err := m.db.GetContext(ctx, &meeting, fetchQuery, meetingID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
-			return
-		}
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
-		return
-	}
-
-	// Check if already cancelled
-	if meeting.Status == enum.Cancelled {
-		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Meeting is already cancelled", nil))
-		return
-	}
-
-	// 2. Attempt to delete from Calendar API (best effort)
-	if meeting.CalendarEventID != "" && meeting.CalendarAppType != "" {
-		calendarAppType := enum.IntegrationAppType(meeting.CalendarAppType) // Convert string back to enum type
-
-		var integration model.Integration
-		integrationQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
-		err = m.db.GetContext(ctx, &integration, integrationQuery, meeting.EventUserID, calendarAppType)
-
-		if err != nil && err != sql.ErrNoRows {
-			// Log error fetching integration, but proceed to DB cancel
-			log.Printf("Warning: Failed to fetch integration for calendar deletion (MeetingID: %s): %v\n",
-				meetingID, err)
-		} else if err == nil { // Integration found
-			calendarSvc, _, errClient := GetCalendarClient(ctx, integration) // Pass context
-			if errClient != nil {
-				// Log error getting client, but proceed to DB cancel
-				log.Printf("Warning: Failed to get calendar client for deletion (MeetingID: %s): %v\n",
-					meetingID, errClient)
-			} else {
-				// Call delete
-				errDelete := calendarSvc.Events.Delete("primary", meeting.CalendarEventID).Do()
-				if errDelete != nil {
-					// IMPORTANT: Decide how critical calendar deletion failure is.
-					// Log it, maybe notify someone, but allow DB cancellation?
-					// Returning error here matches TS behavior.
-					log.Printf("Warning: Failed to delete calendar event (MeetingID: %s, CalID: %s): %v\n",
-						meetingID, meeting.CalendarEventID, errDelete)
-					// Optionally return a specific error:
-					// return apperror.NewAppError(enums.InternalServerError, "Failed to delete event from calendar", errDelete)
-				} else {
-					log.Printf("Successfully deleted calendar event (MeetingID: %s, CalID: %s)\n",
-						meetingID, meeting.CalendarEventID)
-				}
-			}
-		}
-	}
-
-	// 3. Update Meeting Status in DB
-	updateQuery := `UPDATE meetings SET status = $1, updated_at = NOW() WHERE id = $2;`
-	result, err := m.db.ExecContext(ctx, updateQuery, enum.Cancelled, meetingID)
-	if err != nil {
-		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update meeting status", err))
-		return
-	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		// Should not happen if fetch succeeded, but good check
-		appError.WriteError(w, appError.NewNotFoundError("Meeting (for update)", nil))
-		return
-	}
-
-	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Meeting cancelled successfully"})
-}
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/circuitbreaker"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/ical"
+	"github.com/fazamuttaqien/calendly/pkg/pubsub"
+	"github.com/fazamuttaqien/calendly/pkg/retry"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"google.golang.org/api/calendar/v3"
+)
+
+// GET /me/meetings
+func (m *Controller) GetUserMeetings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	listQuery, ok := validator.GetValidatedDTOFromContext[dto.MeetingListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	limit := listQuery.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// Get filter from query param, default to UPCOMING
+	var filter enum.MeetingFilter
+
+	switch strings.ToUpper(listQuery.Filter) {
+	case string(enum.MeetingFilterUpcoming):
+		filter = enum.MeetingFilterUpcoming
+	case string(enum.MeetingFilterPast):
+		filter = enum.MeetingFilterPast
+	case string(enum.MeetingFilterCancelled):
+		filter = enum.MeetingFilterCancelled
+	case string(enum.MeetingFilterNoShow):
+		filter = enum.MeetingFilterNoShow
+	default:
+		// Default to UPCOMING if empty or invalid
+		filter = enum.MeetingFilterUpcoming
+	}
+
+	meetings := []model.Meeting{}
+
+	var args []any
+	args = append(args, userID)
+
+	// Base query joining meetings and events
+	baseQuery := `
+		SELECT
+			m.*,
+			e.title AS event_title, -- Alias joined event fields
+			e.description AS event_description
+            -- Add other event fields as needed
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE m.user_id = $1
+	`
+
+	filterClause := ""
+
+	now := time.Now()
+
+	switch filter {
+	case enum.MeetingFilterUpcoming:
+		filterClause = " AND m.status = $2 AND m.start_time > $3"
+		args = append(args, enum.Scheduled, now)
+	case enum.MeetingFilterPast:
+		filterClause = " AND m.status = $2 AND m.start_time < $3"
+		args = append(args, enum.Scheduled, now)
+	case enum.MeetingFilterCancelled:
+		filterClause = " AND m.status = $2"
+		args = append(args, enum.Cancelled)
+	case enum.MeetingFilterNoShow:
+		filterClause = " AND m.status = $2"
+		args = append(args, enum.NoShow)
+	default: // Default to UPCOMING if filter is invalid or not provided
+		filterClause = " AND m.status = $2 AND m.start_time > $3"
+		args = append(args, enum.Scheduled, now)
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM meetings m WHERE m.user_id = $1" + filterClause + ";"
+	if err := m.readDB.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count user meetings", err))
+		return
+	}
+
+	pageArgs := append(args, limit, listQuery.Offset)
+	orderByClause := fmt.Sprintf(" ORDER BY m.start_time ASC, m.id ASC LIMIT $%d OFFSET $%d", len(pageArgs)-1, len(pageArgs))
+	finalQuery := baseQuery + filterClause + orderByClause + ";"
+
+	err := m.readDB.SelectContext(ctx, &meetings, finalQuery, pageArgs...)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve useer meetings", err))
+		return
+	}
+
+	response := helper.NewPaginatedResponse("Meetings fetched successfully", meetings, total, limit, listQuery.Offset)
+	links := helper.BuildPaginationLinks(r, limit, listQuery.Offset, total)
+	response.Links = &links
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /events/{eventId}/meetings
+// Bookings of one of the host's events, optionally narrowed down to a status.
+func (m *Controller) GetEventMeetings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// Already validated as a UUID by the params middleware
+	eventID := chi.URLParam(r, "eventId")
+
+	listQuery, ok := validator.GetValidatedDTOFromContext[dto.EventMeetingListQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	limit := listQuery.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// 1. Ownership check, other hosts' events are reported as not found
+	var exists bool
+	ownerQuery := `SELECT EXISTS(SELECT 1 FROM events WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL);`
+	if err := m.readDB.GetContext(ctx, &exists, ownerQuery, eventID, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve event", err))
+		return
+	}
+	if !exists {
+		appError.WriteError(w, appError.NewNotFoundError("Event", nil))
+		return
+	}
+
+	// 2. Count and fetch the requested page
+	whereClause := " WHERE m.event_id = $1 AND m.user_id = $2"
+	args := []any{eventID, userID}
+	if listQuery.Status != "" {
+		args = append(args, listQuery.Status)
+		whereClause += fmt.Sprintf(" AND m.status = $%d", len(args))
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM meetings m" + whereClause + ";"
+	if err := m.readDB.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count event meetings", err))
+		return
+	}
+
+	meetings := []model.Meeting{}
+	pageArgs := append(args, limit, listQuery.Offset)
+	query := fmt.Sprintf(`
+		SELECT
+			m.*,
+			e.title AS event_title,
+			e.description AS event_description
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		%s
+		ORDER BY m.start_time ASC, m.id ASC
+		LIMIT $%d OFFSET $%d;
+	`, whereClause, len(pageArgs)-1, len(pageArgs))
+
+	if err := m.readDB.SelectContext(ctx, &meetings, query, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve event meetings", err))
+		return
+	}
+
+	response := helper.NewPaginatedResponse("Event meetings fetched successfully", meetings, total, limit, listQuery.Offset)
+	links := helper.BuildPaginationLinks(r, limit, listQuery.Offset, total)
+	response.Links = &links
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /meeting/search
+func (m *Controller) SearchMeetings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.MeetingSearchQueryDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	if !dto.From.IsZero() && !dto.To.IsZero() && dto.To.Before(dto.From) {
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "'to' must not be before 'from'", nil))
+		return
+	}
+
+	limit := dto.Limit
+	if limit == 0 {
+		limit = 20
+	}
+
+	// 1. Build optional filters, every value is passed as a parameter
+	whereClause := " WHERE m.user_id = $1"
+	args := []any{userID}
+
+	if dto.GuestEmail != "" {
+		args = append(args, dto.GuestEmail)
+		whereClause += fmt.Sprintf(" AND LOWER(m.guest_email) = LOWER($%d)", len(args))
+	}
+	if dto.GuestName != "" {
+		args = append(args, "%"+escapeLikePattern(dto.GuestName)+"%")
+		whereClause += fmt.Sprintf(" AND m.guest_name ILIKE $%d", len(args))
+	}
+	if dto.EventID != "" {
+		args = append(args, dto.EventID)
+		whereClause += fmt.Sprintf(" AND m.event_id = $%d", len(args))
+	}
+	if !dto.From.IsZero() {
+		args = append(args, dto.From)
+		whereClause += fmt.Sprintf(" AND m.start_time >= $%d", len(args))
+	}
+	if !dto.To.IsZero() {
+		args = append(args, dto.To)
+		whereClause += fmt.Sprintf(" AND m.start_time <= $%d", len(args))
+	}
+
+	// 2. Count total matches for pagination
+	var total int
+	countQuery := "SELECT COUNT(*) FROM meetings m" + whereClause + ";"
+	if err := m.readDB.GetContext(ctx, &total, countQuery, args...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to count meetings", err))
+		return
+	}
+
+	// 3. Fetch the requested page, same shape as GetUserMeetings
+	meetings := []model.Meeting{}
+	pageArgs := append(args, limit, dto.Offset)
+	searchQuery := `
+		SELECT
+			m.*,
+			e.title AS event_title,
+			e.description AS event_description
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id` + whereClause +
+		fmt.Sprintf(" ORDER BY m.start_time DESC, m.id DESC LIMIT $%d OFFSET $%d;", len(pageArgs)-1, len(pageArgs))
+
+	if err := m.readDB.SelectContext(ctx, &meetings, searchQuery, pageArgs...); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to search meetings", err))
+		return
+	}
+
+	response := helper.NewPaginatedResponse("Meetings fetched successfully", meetings, total, limit, dto.Offset)
+	links := helper.BuildPaginationLinks(r, limit, dto.Offset, total)
+	response.Links = &links
+
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /meetings/{meetingId}
+func (m *Controller) GetMeeting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	meetingID := chi.URLParam(r, "meetingId")
+	if meetingID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
+		return
+	}
+
+	// Meetings of other hosts are reported as not found
+	meeting, err := m.getMeetingDetail(ctx, "m.id = $1 AND m.user_id = $2", meetingID, userID)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "Meeting fetched successfully",
+		"meeting": meeting,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /meetings/public/{token}
+// Public endpoint allowing the guest to view their meeting with the token returned on booking,
+// used by the booking confirmation page and the link in the confirmation email.
+func (m *Controller) GetMeetingByToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing token in path", nil))
+		return
+	}
+
+	if err := validator.Validate.Var(token, "uuid4"); err != nil {
+		appError.WriteError(w, appError.NewValidationError("Invalid meeting token", nil))
+		return
+	}
+
+	meeting, err := m.getMeetingDetail(ctx, "m.cancellation_token = $1", token)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// Notes are for the host only
+	meeting.Notes = sql.NullString{}
+
+	// The meet link is only useful, and only shared, while the meeting is on
+	message := "Meeting fetched successfully"
+	if meeting.Status == enum.Cancelled {
+		meeting.MeetLink = ""
+		message = "This meeting has been cancelled"
+	}
+
+	response := map[string]any{
+		"message": message,
+		"meeting": meeting,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// getMeetingDetail fetches a single meeting with event and owner details matching condition.
+func (m *Controller) getMeetingDetail(ctx context.Context, condition string, args ...any) (MeetingDetail, error) {
+	var meeting MeetingDetail
+	query := `
+		SELECT
+			m.*,
+			e.title AS event_title,
+			e.description AS event_description,
+			u.username AS owner_username,
+			u.name AS owner_name
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		JOIN users u ON m.user_id = u.id
+		WHERE ` + condition + `;`
+
+	err := m.db.GetContext(ctx, &meeting, query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return MeetingDetail{}, appError.NewNotFoundError("Meeting", nil)
+		}
+		return MeetingDetail{}, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err)
+	}
+	return meeting, nil
+}
+
+// GET /meeting/export
+func (m *Controller) ExportMeetings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// 1. Fetch every meeting of the user, regardless of status or time
+	var meetings []model.Meeting
+	query := `
+		SELECT
+			m.*,
+			e.title AS event_title,
+			e.description AS event_description
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE m.user_id = $1
+		ORDER BY m.start_time ASC;
+	`
+	if err := m.db.SelectContext(ctx, &meetings, query, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to retrieve user meetings", err))
+		return
+	}
+
+	// 2. Encode as iCalendar
+	body, err := ical.Encode(meetings)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export meetings", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", ical.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="meetings.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		log.Printf("Warning: Failed to write meetings export (UserID: %s): %v\n", userID, err)
+	}
+}
+
+func (m *Controller) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateMeetingDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Round-robin bookings are made on the event behind the round-robin event
+	if dto.RoundRobinEventID != "" {
+		eventID, err := m.getRoundRobinEventID(ctx, dto.RoundRobinEventID)
+		if err != nil {
+			appError.WriteError(w, err)
+			return
+		}
+		if dto.EventID != "" && dto.EventID != eventID {
+			appError.WriteError(w, appError.NewValidationError("Event does not match the round-robin event", nil))
+			return
+		}
+		dto.EventID = eventID
+	}
+
+	// 1. Parse times
+	// startTime, err := time.Parse(time.RFC3339, dto.StartTime.String()) // Assuming RFC3339 format from DTO
+	// if err != nil {
+	// 	appError.WriteError(w, appError.NewAppError(
+	// 		enums.ValidationError,
+	// 		"Invalid start time format",
+	// 		err,
+	// 	)
+	// }
+
+	// endTime, err := time.Parse(time.RFC3339, dto.EndTime.String()) // Assuming RFC3339 format from DTO
+	// if err != nil {
+	// 	appError.WriteError(w, appError.NewAppError(
+	// 		enums.ValidationError,
+	// 		"Invalid end time format",
+	// 		err,
+	// 	)
+	// }
+
+	// Times are the guest's wall-clock times when a timezone is given, otherwise taken as sent
+	location := time.UTC
+	if dto.Timezone != "" {
+		loaded, errLoc := time.LoadLocation(dto.Timezone)
+		if errLoc != nil {
+			appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Invalid timezone", errLoc))
+			return
+		}
+		location = loaded
+		dto.StartTime = wallClockIn(dto.StartTime, location)
+		dto.EndTime = wallClockIn(dto.EndTime, location)
+	} else {
+		log.Printf("Warning: Booking for event %s has no timezone, assuming UTC\n", dto.EventID)
+	}
+	dto.StartTime = dto.StartTime.UTC()
+	dto.EndTime = dto.EndTime.UTC()
+
+	startTime := dto.StartTime.Format(time.RFC3339)
+	endTime := dto.EndTime.Format(time.RFC3339)
+
+	// Retried requests with the same idempotency key get the original response
+	idemKey := strings.TrimSpace(r.Header.Get(idempotencyKeyHeader))
+	if len(idemKey) > idempotencyKeyMaxLength {
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Idempotency key is too long", nil))
+		return
+	}
+	if idemKey != "" {
+		idemKey = idempotencyKey(dto.EventID, idemKey)
+		stored, err := m.getIdempotentResponse(ctx, idemKey)
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check idempotency key", err))
+			return
+		}
+		if stored != nil {
+			writeIdempotentResponse(w, stored)
+			return
+		}
+	}
+
+	// 2. Fetch Event and User
+	var event struct {
+		model.Event
+		HostEmail string `db:"host_email"` // Receives the booking confirmation
+	}
+	eventQuery := `
+		SELECT e.*, u.email AS host_email
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE e.id = $1 AND e.is_private = FALSE AND e.deleted_at IS NULL;
+	`
+	err := m.db.GetContext(ctx, &event, eventQuery, dto.EventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Public event", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err))
+		return
+	}
+
+	// The guest picks one of the event's location types, events offering a single one need no choice
+	locationType := dto.SelectedLocationType
+	if locationType == "" {
+		if len(event.LocationTypes) != 1 {
+			appError.WriteError(w, appError.NewValidationError("selectedLocationType is required for events with several location types", nil))
+			return
+		}
+		locationType = event.LocationTypes[0]
+	}
+	if !slices.Contains(event.LocationTypes, locationType) {
+		appError.WriteError(w, appError.NewValidationError(fmt.Sprintf("Event does not offer location type: %s", locationType), nil))
+		return
+	}
+
+	// Simple validation for location type enum (can be improved)
+	isValidLocation := slices.Contains(enum.AllEventLocationType(), locationType)
+	if !isValidLocation {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, fmt.Sprintf("Event has invalid location type: %s", locationType), nil))
+		return
+	}
+
+	if err := validateMeetingDuration(event.Event, dto.StartTime, dto.EndTime); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+	if err := validateBookingWindow(event.Event, dto.StartTime); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// Answers must match the booking questions of the event
+	questions, err := m.getEventQuestions(ctx, event.ID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event questions", err))
+		return
+	}
+	if err := validateBookingAnswers(questions, dto.Answers); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// Fully booked events queue the guest instead when the host enabled a waitlist
+	full, err := m.isEventFull(ctx, event.Event)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check event capacity", err))
+		return
+	}
+	if full {
+		if !event.EnableWaitlist {
+			appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Event is fully booked", nil))
+			return
+		}
+		if err := m.joinWaitlist(ctx, event.ID, dto); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to join waitlist", err))
+			return
+		}
+		helper.ResponseJson(w, http.StatusAccepted, helper.SimpleMessage{Message: "You have been added to the waitlist"})
+		return
+	}
+
+	// Round-robin events go to the next free host, who then owns the meeting
+	hostID, hostEmail := event.UserID, event.HostEmail
+	if dto.RoundRobinEventID != "" {
+		host, err := m.nextRoundRobinHost(ctx, dto.RoundRobinEventID, locationType, dto.StartTime, dto.EndTime)
+		if err != nil {
+			appError.WriteError(w, err)
+			return
+		}
+		hostID, hostEmail = host.UserID, host.Email
+	}
+
+	// Make sure the requested slot doesn't overlap another scheduled meeting of the host
+	if err := m.ensureSlotAvailable(ctx, hostID, dto.StartTime, dto.EndTime, ""); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// 3. Fetch Integration for the event's use
+	var integration model.Integration
+	// Derive appType from the selected locationType
+	requiredAppType, ok := IntegrationAppTypeFromEventLocation(locationType)
+	if !ok {
+		// This check might be redundant if previous validation passed, but safer
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Cannot map event location to integration app type", nil))
+		return
+	}
+
+	integrationQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
+	err = m.db.GetContext(ctx, &integration, integrationQuery, hostID, requiredAppType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Required integration '%s' not found or disconnected for the event owner.", requiredAppType)
+			appError.WriteError(w, appError.NewAppError(enum.BadRequest, msg, nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch integration", err))
+		return
+	}
+
+	// 4. Interact with Calendar API (if applicable)
+	meetLink := ""
+	calendarEventID := ""
+	calendarAppTypeStr := ""
+
+	if locationType == enum.LocationGoogleMeetAndCalendar {
+		// Both calls reach Google, so an outage trips the breaker and later bookings fail fast
+		var createdCalEvent *calendar.Event
+		var clientErr error
+		err := googleCalendarBreaker.Execute(func() error {
+			calendarSvc, appType, err := GetCalendarClient(ctx, m.db, integration)
+			if err != nil {
+				clientErr = err
+				return err
+			}
+			calendarAppTypeStr = string(appType) // Store the string representation
+
+			newEventID, err := newCalendarEventID()
+			if err != nil {
+				clientErr = err
+				return err
+			}
+
+			// Rate limits and Google server errors are usually gone a moment later
+			return retry.Do(ctx, calendarInsertAttempts, calendarInsertBackoff, func() error {
+				createdCalEvent, err = CreateGoogleMeetEvent(
+					ctx,
+					calendarSvc,
+					newEventID,
+					fmt.Sprintf("%s - %s", dto.GuestName, event.Title),
+					dto.AdditionalInfo,
+					dto.StartTime,
+					dto.EndTime,
+					dto.GuestEmail,
+					integration.User.Email, // Assuming Integration model has UserEmail fetched or available
+				)
+				return err
+			})
+		})
+		switch {
+		case errors.Is(err, circuitbreaker.ErrOpen):
+			msg := "Calendar service temporarily unavailable. Please try again later."
+			appError.WriteError(w, appError.NewAppError(enum.ServiceUnavailable, msg, err))
+			return
+		case clientErr != nil:
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, clientErr.Error(), clientErr))
+			return
+		case err != nil:
+			appError.WriteError(w, err)
+			return
+		}
+
+		// Extract results
+		meetLink = createdCalEvent.HangoutLink
+		calendarEventID = createdCalEvent.Id
+
+	} else {
+		// Handle other location types (e.g., Zoom) if necessary
+		// For now, assume link/id remain empty if not Google
+	}
+
+	// 5. Insert Meeting into Database
+	var createdMeeting model.Meeting
+	insertQuery := `
+	INSERT INTO meetings (
+			user_id, event_id, guest_name, guest_email, additional_info,
+			start_time, end_time, meet_link, calendar_event_id, calendar_app_type,
+			status, cancellation_token, timezone, location_type, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW())
+		RETURNING *;
+	`
+	addInfo := sql.NullString{String: dto.AdditionalInfo, Valid: dto.AdditionalInfo != ""}
+
+	// Token the guest can use to cancel without an account
+	cancellationToken := uuid.NewString()
+
+	// Meeting and booking answers are saved together
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// The check above is repeated under a lock of the event, another booking may have taken the last spot since
+	hasCapacity, err := hasCapacityLocked(ctx, tx, event.Event)
+	if err != nil || !hasCapacity {
+		_ = tx.Rollback()
+		if calendarEventID != "" {
+			m.deleteOrphanedCalendarEvent(ctx, integration, calendarEventID)
+		}
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check event capacity", err))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.ValidationError, "Event is fully booked", nil))
+		return
+	}
+
+	err = tx.GetContext(ctx, &createdMeeting, insertQuery,
+		hostID, event.ID, dto.GuestName, dto.GuestEmail, addInfo,
+		startTime, endTime, meetLink, calendarEventID, calendarAppTypeStr,
+		enum.Scheduled, // Default status
+		cancellationToken,
+		location.String(),
+		locationType,
+	)
+	if err != nil {
+		// Consider handling specific DB errors like constraint violations
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to save meeting record", err))
+		return
+	}
+
+	answerQuery := `INSERT INTO booking_answers (meeting_id, question_id, answer) VALUES ($1, $2, $3);`
+	for _, answer := range dto.Answers {
+		if _, err := tx.ExecContext(ctx, answerQuery, createdMeeting.ID, answer.QuestionID, answer.Answer); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to save booking answers", err))
+			return
+		}
+	}
+
+	// Record the assignment so the next booking moves on to another host
+	if dto.RoundRobinEventID != "" {
+		assignQuery := `UPDATE round_robin_event_hosts SET last_assigned_at = NOW() WHERE round_robin_event_id = $1 AND user_id = $2;`
+		if _, err := tx.ExecContext(ctx, assignQuery, dto.RoundRobinEventID, hostID); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to record round-robin assignment", err))
+			return
+		}
+	}
+
+	response := map[string]any{
+		"message": "Meeting scheduled successfully",
+		"data": map[string]any{
+			"meetLink":          meetLink,
+			"meeting":           createdMeeting,
+			"cancellationToken": createdMeeting.CancellationToken,
+		},
+	}
+
+	if idemKey != "" {
+		stored, err := storeIdempotentResponse(ctx, tx, idemKey, http.StatusCreated, response)
+		if err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to store idempotency key", err))
+			return
+		}
+		if !stored {
+			// A concurrent request with the same key committed first, discard this one
+			_ = tx.Rollback()
+			if calendarEventID != "" {
+				m.deleteOrphanedCalendarEvent(ctx, integration, calendarEventID)
+			}
+			replay, err := m.getIdempotentResponse(ctx, idemKey)
+			if err != nil || replay == nil {
+				appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to load idempotent response", err))
+				return
+			}
+			writeIdempotentResponse(w, replay)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	// Fire and forget, a slow mail server must not hold up the committed booking
+	go m.sendBookingConfirmation(hostEmail, createdMeeting, event.Event)
+	publishAvailabilityChange(createdMeeting)
+	slackMeeting := createdMeeting
+	slackMeeting.EventTitle = event.Title
+	m.notifySlack(hostID, enum.WebhookMeetingCreated, slackMeeting)
+	m.dispatchWebhooks(event.UserID, enum.WebhookMeetingCreated, createdMeeting)
+	m.recordAudit(r, audit.AuditEntry{
+		UserID:     event.UserID,
+		Action:     audit.ActionMeetingCreate,
+		EntityType: audit.EntityMeeting,
+		EntityID:   createdMeeting.ID,
+		Metadata:   map[string]any{"eventId": event.ID, "guestEmail": createdMeeting.GuestEmail},
+	})
+
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// publishAvailabilityChange tells open availability streams of the meeting's host to refresh their slots.
+func publishAvailabilityChange(meeting model.Meeting) {
+	payload, err := json.Marshal(AvailabilityChange{
+		MeetingID: meeting.ID,
+		EventID:   meeting.EventID,
+		StartTime: meeting.StartTime,
+		EndTime:   meeting.EndTime,
+		Status:    meeting.Status,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to encode availability change (MeetingID: %s): %v\n", meeting.ID, err)
+		return
+	}
+	pubsub.Publish(meeting.UserID, payload)
+}
+
+// sendBookingConfirmation emails the guest and the host about a new meeting with an .ics invite (best effort).
+func (m *Controller) sendBookingConfirmation(hostEmail string, meeting model.Meeting, event model.Event) {
+	// The invite needs the joined event fields that RETURNING * doesn't provide
+	meeting.EventTitle = event.Title
+	meeting.EventDescription = event.Description
+
+	attachment, err := ical.Encode([]model.Meeting{meeting})
+	if err != nil {
+		log.Printf("Warning: Failed to build booking invite (MeetingID: %s): %v\n", meeting.ID, err)
+	}
+
+	if err := m.mailer.SendBookingConfirmation(meeting.GuestEmail, hostEmail, meeting, event, attachment); err != nil {
+		log.Printf("Warning: Failed to send booking confirmation (MeetingID: %s): %v\n", meeting.ID, err)
+	}
+}
+
+// deleteOrphanedCalendarEvent removes a calendar event whose meeting was never saved (best effort).
+func (m *Controller) deleteOrphanedCalendarEvent(ctx context.Context, integration model.Integration, calendarEventID string) {
+	calendarSvc, _, err := GetCalendarClient(ctx, m.db, integration)
+	if err == nil {
+		err = DeleteGoogleCalendarEvent(ctx, calendarSvc, calendarEventID)
+	}
+	if err != nil {
+		log.Printf("Warning: Failed to delete orphaned calendar event (CalID: %s): %v\n", calendarEventID, err)
+	}
+}
+
+// DELETE /meetings/{meetingId}
+func (m *Controller) CancelMeeting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	meetingID := chi.URLParam(r, "meetingId")
+	if meetingID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
+		return
+	}
+
+	// 1. Fetch Meeting, Event, and User info needed, only the meeting's host or the event owner may cancel it
+	var meeting MeetingWithOwner
+	fetchQuery := `
+		SELECT m.*, e.user_id AS event_user_id, e.title AS event_title, u.email AS host_email
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		JOIN users u ON m.user_id = u.id
+		WHERE m.id = $1 AND (m.user_id = $2 OR e.user_id = $2);
+	`
+	err := m.db.GetContext(ctx, &meeting, fetchQuery, meetingID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
+		return
+	}
+
+	// Cancelled and no-show meetings keep their final status
+	if meeting.Status != enum.Scheduled {
+		appError.WriteError(w, appError.NewValidationError("Only scheduled meetings can be cancelled", nil))
+		return
+	}
+
+	// 2. Delete calendar event and update status
+	if err := m.cancelMeeting(ctx, meeting); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	m.recordAudit(r, audit.AuditEntry{
+		UserID:     meeting.EventUserID,
+		Action:     audit.ActionMeetingCancel,
+		EntityType: audit.EntityMeeting,
+		EntityID:   meeting.ID,
+		Metadata:   map[string]any{"cancelledBy": "host"},
+	})
+
+	m.promoteWaitlist(meeting.EventID)
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Meeting cancelled successfully"})
+}
+
+// POST /meetings/cancel?token={cancellationToken}
+// Public endpoint allowing the guest to cancel with the token returned on booking.
+func (m *Controller) CancelMeetingByToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CancelByTokenDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Look up the meeting by its cancellation token
+	var meeting MeetingWithOwner
+	fetchQuery := `
+		SELECT m.*, e.user_id AS event_user_id, e.title AS event_title, u.email AS host_email
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		JOIN users u ON m.user_id = u.id
+		WHERE m.cancellation_token = $1;
+	`
+	err := m.db.GetContext(ctx, &meeting, fetchQuery, dto.Token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
+		return
+	}
+
+	// Cancelled and no-show meetings keep their final status
+	if meeting.Status != enum.Scheduled {
+		appError.WriteError(w, appError.NewValidationError("Only scheduled meetings can be cancelled", nil))
+		return
+	}
+
+	// 2. Delete calendar event and update status
+	if err := m.cancelMeeting(ctx, meeting); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	m.recordAudit(r, audit.AuditEntry{
+		UserID:     meeting.EventUserID,
+		Action:     audit.ActionMeetingCancel,
+		EntityType: audit.EntityMeeting,
+		EntityID:   meeting.ID,
+		Metadata:   map[string]any{"cancelledBy": "guest"},
+	})
+
+	m.promoteWaitlist(meeting.EventID)
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Meeting cancelled successfully"})
+}
+
+// cancelMeeting deletes the meeting's calendar event (best effort) and marks the meeting as cancelled.
+func (m *Controller) cancelMeeting(ctx context.Context, meeting MeetingWithOwner) error {
+	// 1. Attempt to delete from Calendar API (best effort)
+	if meeting.CalendarEventID != "" && meeting.CalendarAppType != "" {
+		calendarAppType := enum.IntegrationAppType(meeting.CalendarAppType) // Convert string back to enum type
+
+		var integration model.Integration
+		integrationQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
+		err := m.db.GetContext(ctx, &integration, integrationQuery, meeting.UserID, calendarAppType)
+
+		if err != nil && err != sql.ErrNoRows {
+			// Log error fetching integration, but proceed to DB cancel
+			log.Printf("Warning: Failed to fetch integration for calendar deletion (MeetingID: %s): %v\n",
+				meeting.ID, err)
+		} else if err == nil && calendarAppType == enum.AppZoomMeeting {
+			zoomClient, errClient := GetZoomClient(ctx, integration)
+			if errClient != nil {
+				log.Printf("Warning: Failed to get Zoom client for deletion (MeetingID: %s): %v\n",
+					meeting.ID, errClient)
+			} else if errDelete := zoomClient.DeleteMeeting(meeting.CalendarEventID); errDelete != nil {
+				log.Printf("Warning: Failed to delete Zoom meeting (MeetingID: %s, ZoomID: %s): %v\n",
+					meeting.ID, meeting.CalendarEventID, errDelete)
+			} else {
+				log.Printf("Successfully deleted Zoom meeting (MeetingID: %s, ZoomID: %s)\n",
+					meeting.ID, meeting.CalendarEventID)
+			}
+		} else if err == nil { // Integration found
+			var errClient error
+			errDelete := googleCalendarBreaker.Execute(func() error {
+				calendarSvc, _, err := GetCalendarClient(ctx, m.db, integration) // Pass context
+				if err != nil {
+					errClient = err
+					return err
+				}
+				return DeleteGoogleCalendarEvent(ctx, calendarSvc, meeting.CalendarEventID)
+			})
+			if errors.Is(errDelete, circuitbreaker.ErrOpen) {
+				// Google is known to be down, skip the call rather than hold up the cancellation
+				log.Printf("Warning: Calendar service unavailable, calendar event not deleted (MeetingID: %s, CalID: %s)\n",
+					meeting.ID, meeting.CalendarEventID)
+			} else if errClient != nil {
+				// Log error getting client, but proceed to DB cancel
+				log.Printf("Warning: Failed to get calendar client for deletion (MeetingID: %s): %v\n",
+					meeting.ID, errClient)
+			} else {
+				if errDelete != nil {
+					// IMPORTANT: Decide how critical calendar deletion failure is.
+					// Log it, maybe notify someone, but allow DB cancellation?
+					// Returning error here matches TS behavior.
+					log.Printf("Warning: Failed to delete calendar event (MeetingID: %s, CalID: %s): %v\n",
+						meeting.ID, meeting.CalendarEventID, errDelete)
+					// Optionally return a specific error:
+					// return apperror.NewAppError(enums.InternalServerError, "Failed to delete event from calendar", errDelete)
+				} else {
+					log.Printf("Successfully deleted calendar event (MeetingID: %s, CalID: %s)\n",
+						meeting.ID, meeting.CalendarEventID)
+				}
+			}
+		}
+	}
+
+	// 2. Update Meeting Status in DB, the status guard keeps a concurrent no-show mark from being overwritten
+	updateQuery := `UPDATE meetings SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3;`
+	result, err := m.db.ExecContext(ctx, updateQuery, enum.Cancelled, meeting.ID, enum.Scheduled)
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to update meeting status", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		// Should not happen if fetch succeeded, but good check
+		return appError.NewNotFoundError("Meeting (for update)", nil)
+	}
+
+	meeting.Status = enum.Cancelled
+	publishAvailabilityChange(meeting.Meeting)
+	m.dispatchWebhooks(meeting.EventUserID, enum.WebhookMeetingCancelled, meeting.Meeting)
+	m.notifySlack(meeting.UserID, enum.WebhookMeetingCancelled, meeting.Meeting)
+
+	// Fire and forget, a slow mail server must not hold up the response
+	go func() {
+		if err := m.mailer.SendCancellationNotification(meeting.GuestEmail, meeting.HostEmail, meeting.Meeting); err != nil {
+			log.Printf("Warning: Failed to send cancellation notification (MeetingID: %s): %v\n", meeting.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+// PATCH /meetings/{meetingId}/reschedule
+func (m *Controller) RescheduleMeeting(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	meetingID := chi.URLParam(r, "meetingId")
+	if meetingID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.RescheduleMeetingDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Only the host owning the meeting may reschedule it
+	var meeting MeetingWithOwner
+	fetchQuery := `
+		SELECT m.*, e.user_id AS event_user_id, e.title AS event_title
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE m.id = $1 AND m.user_id = $2;
+	`
+	err := m.db.GetContext(ctx, &meeting, fetchQuery, meetingID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
+		return
+	}
+
+	updatedMeeting, err := m.rescheduleMeeting(ctx, meeting, dto.StartTime, dto.EndTime)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	response := map[string]any{
+		"message": "Meeting rescheduled successfully",
+		"data": map[string]any{
+			"meetLink": updatedMeeting.MeetLink,
+			"meeting":  updatedMeeting,
+		},
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /meetings/public/{token}/reschedule
+// Public endpoint allowing the guest to reschedule with the token returned on booking.
+func (m *Controller) RescheduleMeetingByToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	token := chi.URLParam(r, "token")
+	if token == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing token in path", nil))
+		return
+	}
+	if err := validator.Validate.Var(token, "uuid4"); err != nil {
+		appError.WriteError(w, appError.NewValidationError("Invalid meeting token", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.RescheduleMeetingDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	var meeting MeetingWithOwner
+	fetchQuery := `
+		SELECT m.*, e.user_id AS event_user_id, e.title AS event_title
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE m.cancellation_token = $1;
+	`
+	err := m.db.GetContext(ctx, &meeting, fetchQuery, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
+		return
+	}
+
+	updatedMeeting, err := m.rescheduleMeeting(ctx, meeting, dto.StartTime, dto.EndTime)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+	updatedMeeting.Notes = sql.NullString{} // Notes are for the host only
+
+	response := map[string]any{
+		"message": "Meeting rescheduled successfully",
+		"data": map[string]any{
+			"meetLink": updatedMeeting.MeetLink,
+			"meeting":  updatedMeeting,
+		},
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /meetings/{meetingId}/notes
+func (m *Controller) UpdateMeetingNotes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	meetingID := chi.URLParam(r, "meetingId")
+	if meetingID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateMeetingNotesDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	notes := sql.NullString{String: dto.Notes, Valid: dto.Notes != ""}
+
+	// Only the host owning the meeting may change its notes
+	query := `UPDATE meetings SET notes = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3;`
+	result, err := m.db.ExecContext(ctx, query, notes, meetingID, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update meeting notes", err))
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Meeting notes updated successfully",
+		"notes":   notes,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /meetings/{meetingId}/no-show
+// Records that the guest did not attend a past meeting. The calendar event is kept as it happened.
+func (m *Controller) MarkMeetingNoShow(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	meetingID := chi.URLParam(r, "meetingId")
+	if meetingID == "" {
+		appError.WriteError(w, appError.NewAppError(enum.BadRequest, "Missing meetingId in path", nil))
+		return
+	}
+
+	// 1. Only the host owning the meeting may mark it
+	var meeting model.Meeting
+	fetchQuery := `SELECT * FROM meetings WHERE id = $1 AND user_id = $2;`
+	if err := m.db.GetContext(ctx, &meeting, fetchQuery, meetingID, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Meeting", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch meeting", err))
+		return
+	}
+
+	if meeting.Status != enum.Scheduled {
+		appError.WriteError(w, appError.NewValidationError("Only scheduled meetings can be marked as no-show", nil))
+		return
+	}
+	if !meeting.StartTime.Before(time.Now()) {
+		appError.WriteError(w, appError.NewValidationError("Only past meetings can be marked as no-show", nil))
+		return
+	}
+
+	// 2. The status guard keeps a concurrent cancellation from being overwritten
+	updateQuery := `
+		UPDATE meetings SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING *;
+	`
+	if err := m.db.GetContext(ctx, &meeting, updateQuery, enum.NoShow, meeting.ID, enum.Scheduled); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewValidationError("Only scheduled meetings can be marked as no-show", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update meeting status", err))
+		return
+	}
+
+	m.recordAudit(r, audit.AuditEntry{
+		UserID:     userID,
+		Action:     audit.ActionMeetingNoShow,
+		EntityType: audit.EntityMeeting,
+		EntityID:   meeting.ID,
+	})
+
+	response := map[string]any{
+		"message": "Meeting marked as no-show",
+		"meeting": meeting,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// rescheduleMeeting moves a scheduled meeting to a new slot. The DB update runs in a
+// transaction that is rolled back if the calendar event cannot be moved.
+func (m *Controller) rescheduleMeeting(ctx context.Context, meeting MeetingWithOwner, startTime, endTime time.Time) (model.Meeting, error) {
+	if meeting.Status != enum.Scheduled {
+		return model.Meeting{}, appError.NewAppError(enum.ValidationError, "Only scheduled meetings can be rescheduled", nil)
+	}
+
+	// 1. Same checks as booking, the overlap check ignoring the meeting being moved
+	var event model.Event
+	eventQuery := `SELECT id, duration, min_notice_hours, max_notice_days FROM events WHERE id = $1;`
+	if err := m.db.GetContext(ctx, &event, eventQuery, meeting.EventID); err != nil {
+		return model.Meeting{}, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err)
+	}
+	if err := validateMeetingDuration(event, startTime, endTime); err != nil {
+		return model.Meeting{}, err
+	}
+	if err := validateBookingWindow(event, startTime); err != nil {
+		return model.Meeting{}, err
+	}
+	if err := m.ensureSlotAvailable(ctx, meeting.UserID, startTime, endTime, meeting.ID); err != nil {
+		return model.Meeting{}, err
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return model.Meeting{}, appError.NewAppError(enum.InternalServerError, "Failed to start transaction", err)
+	}
+	defer tx.Rollback() // No-op once committed
+
+	// 2. Update times first, committed only if the calendar side succeeds
+	var updatedMeeting model.Meeting
+	updateQuery := `
+		UPDATE meetings SET start_time = $1, end_time = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING *;
+	`
+	if err := tx.GetContext(ctx, &updatedMeeting, updateQuery, startTime, endTime, meeting.ID); err != nil {
+		return model.Meeting{}, appError.NewAppError(enum.InternalServerError, "Failed to update meeting time", err)
+	}
+
+	// 3. Move the Google Calendar event, recreating it if it was deleted on Google's side
+	if meeting.CalendarEventID != "" && enum.IntegrationAppType(meeting.CalendarAppType) == enum.AppGoogleMeetAndCalendar {
+		createdCalEvent, err := m.moveCalendarEvent(ctx, tx, meeting, startTime, endTime)
+		if err != nil {
+			return model.Meeting{}, err
+		}
+		if createdCalEvent != nil {
+			updatedMeeting.MeetLink = createdCalEvent.HangoutLink
+			updatedMeeting.CalendarEventID = createdCalEvent.Id
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Meeting{}, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err)
+	}
+
+	publishAvailabilityChange(updatedMeeting)
+	m.dispatchWebhooks(meeting.EventUserID, enum.WebhookMeetingRescheduled, updatedMeeting)
+
+	return updatedMeeting, nil
+}
+
+// moveCalendarEvent patches the meeting's calendar event to the new slot, keeping its
+// attendee responses. Only when the event no longer exists on Google's side is a new one
+// created and its meet link and calendar event ID stored within tx; the new event is then returned.
+func (m *Controller) moveCalendarEvent(ctx context.Context, tx *sqlx.Tx, meeting MeetingWithOwner, startTime, endTime time.Time) (*calendar.Event, error) {
+	var integration model.Integration
+	integrationQuery := `SELECT * FROM integrations WHERE user_id = $1 AND app_type = $2 AND is_connected = TRUE;`
+	err := m.db.GetContext(ctx, &integration, integrationQuery, meeting.UserID, meeting.CalendarAppType)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Required integration '%s' not found or disconnected for the event owner.", meeting.CalendarAppType)
+			return nil, appError.NewAppError(enum.BadRequest, msg, nil)
+		}
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to fetch integration", err)
+	}
+
+	calendarSvc, _, err := GetCalendarClient(ctx, m.db, integration)
+	if err != nil {
+		return nil, appError.NewAppError(enum.InternalServerError, err.Error(), err)
+	}
+
+	err = UpdateGoogleCalendarEvent(ctx, calendarSvc, meeting.CalendarEventID, startTime, endTime, meeting.GuestEmail)
+	if err == nil {
+		return nil, nil
+	}
+	if !isCalendarEventGone(err) {
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to update calendar event", err)
+	}
+
+	newEventID, err := newCalendarEventID()
+	if err != nil {
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to generate calendar event ID", err)
+	}
+
+	createdCalEvent, err := CreateGoogleMeetEvent(
+		ctx,
+		calendarSvc,
+		newEventID,
+		fmt.Sprintf("%s - %s", meeting.GuestName, meeting.EventTitle),
+		meeting.AdditionalInfo,
+		startTime,
+		endTime,
+		meeting.GuestEmail,
+		integration.User.Email,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	linkQuery := `UPDATE meetings SET meet_link = $1, calendar_event_id = $2 WHERE id = $3;`
+	if _, err := tx.ExecContext(ctx, linkQuery, createdCalEvent.HangoutLink, createdCalEvent.Id, meeting.ID); err != nil {
+		// Don't leave the new calendar event behind if we can't reference it
+		if errDelete := DeleteGoogleCalendarEvent(ctx, calendarSvc, createdCalEvent.Id); errDelete != nil {
+			log.Printf("Warning: Failed to delete orphaned calendar event (MeetingID: %s, CalID: %s): %v\n",
+				meeting.ID, createdCalEvent.Id, errDelete)
+		}
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to update meeting calendar event", err)
+	}
+
+	return createdCalEvent, nil
+}
+
+// validateMeetingDuration returns a validation error unless the slot lasts as long as the event,
+// a minute either way is allowed for rounding.
+func validateMeetingDuration(event model.Event, startTime, endTime time.Time) error {
+	requestedDurationMinutes := int(endTime.Sub(startTime).Minutes())
+	if diff := requestedDurationMinutes - int(event.Duration); diff > 1 || diff < -1 {
+		msg := fmt.Sprintf("Meeting duration must be %d minutes", event.Duration)
+		return appError.NewValidationError(msg, nil)
+	}
+	return nil
+}
+
+// validateBookingWindow returns a validation error unless startTime is in the future and inside
+// the event's advance booking window.
+func validateBookingWindow(event model.Event, startTime time.Time) error {
+	now := time.Now()
+	if !startTime.After(now) {
+		return appError.NewValidationError("Meeting must start in the future", nil)
+	}
+	if startTime.Before(now.Add(time.Duration(event.MinNoticeHours) * time.Hour)) {
+		msg := fmt.Sprintf("Booking requires at least %d hours notice", event.MinNoticeHours)
+		return appError.NewValidationError(msg, nil)
+	}
+	if startTime.After(now.AddDate(0, 0, event.MaxNoticeDays)) {
+		msg := fmt.Sprintf("Booking cannot be made more than %d days in advance", event.MaxNoticeDays)
+		return appError.NewValidationError(msg, nil)
+	}
+	return nil
+}
+
+// ensureSlotAvailable returns a validation error if the host already has a scheduled
+// meeting overlapping [startTime, endTime). excludeMeetingID skips the meeting being moved.
+func (m *Controller) ensureSlotAvailable(ctx context.Context, userID string, startTime, endTime time.Time, excludeMeetingID string) error {
+	var conflict bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM meetings
+			WHERE user_id = $1 AND status = $2 AND start_time < $3 AND end_time > $4 AND id::TEXT <> $5
+		);
+	`
+	err := m.db.GetContext(ctx, &conflict, query, userID, enum.Scheduled, endTime, startTime, excludeMeetingID)
+	if err != nil {
+		return appError.NewAppError(enum.InternalServerError, "Failed to check slot availability", err)
+	}
+	if conflict {
+		return appError.NewAppError(enum.ValidationError, "The selected time slot is not available", nil)
+	}
+	return nil
+}
+
+// validateBookingAnswers checks answers against the questions of the booked event:
+// every answer must target one of the questions, at most once, and required questions must be answered.
+func validateBookingAnswers(questions []model.EventQuestion, answers []dto.BookingAnswerDto) error {
+	byID := make(map[string]model.EventQuestion, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	seen := make(map[string]bool, len(answers))
+	answered := make(map[string]bool, len(answers))
+	for _, answer := range answers {
+		question, ok := byID[answer.QuestionID]
+		if !ok {
+			return appError.NewAppError(enum.ValidationError, fmt.Sprintf("Unknown question %s for this event", answer.QuestionID), nil)
+		}
+		if seen[answer.QuestionID] {
+			return appError.NewAppError(enum.ValidationError, fmt.Sprintf("Question '%s' is answered more than once", question.Label), nil)
+		}
+		seen[answer.QuestionID] = true
+		answered[answer.QuestionID] = strings.TrimSpace(answer.Answer) != ""
+
+		switch question.FieldType {
+		case enum.QuestionFieldCheckbox:
+			if answer.Answer != "" && answer.Answer != "true" && answer.Answer != "false" {
+				return appError.NewAppError(enum.ValidationError, fmt.Sprintf("Answer to '%s' must be true or false", question.Label), nil)
+			}
+			// An unchecked required checkbox is not an answer
+			answered[answer.QuestionID] = answer.Answer == "true"
+		case enum.QuestionFieldSelect:
+			var options []string
+			if len(question.Options) > 0 {
+				if err := json.Unmarshal(question.Options, &options); err != nil {
+					return appError.NewAppError(enum.InternalServerError, "Failed to read question options", err)
+				}
+			}
+			if answer.Answer != "" && !slices.Contains(options, answer.Answer) {
+				return appError.NewAppError(enum.ValidationError, fmt.Sprintf("Answer to '%s' is not one of its options", question.Label), nil)
+			}
+		}
+	}
+
+	for _, q := range questions {
+		if q.IsRequired && !answered[q.ID] {
+			return appError.NewAppError(enum.ValidationError, fmt.Sprintf("Question '%s' is required", q.Label), nil)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+)
+
+// orgInvitationTTL is how long an organization invitation can be accepted.
+const orgInvitationTTL = 7 * 24 * time.Hour
+
+// OrganizationMemberDetail is a member of an organization with its user profile.
+type OrganizationMemberDetail struct {
+	UserID   string         `db:"user_id" json:"userId"`
+	Name     string         `db:"name" json:"name"`
+	Email    string         `db:"email" json:"email"`
+	Username string         `db:"username" json:"username"`
+	ImageURL sql.NullString `db:"image_url" json:"imageUrl"`
+	Role     enum.OrgRole   `db:"role" json:"role"`
+	JoinedAt time.Time      `db:"joined_at" json:"joinedAt"`
+}
+
+// POST /orgs
+// The creator becomes the owner and first admin of the organization.
+func (o *Controller) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateOrganizationDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// A custom slug is kept exactly, only generated slugs get a random suffix
+	customSlug := dto.Slug != ""
+	slug := helper.Slugify(dto.Name)
+	if customSlug {
+		slug = helper.SlugifyClean(dto.Slug)
+		if slug == "" {
+			appError.WriteError(w, appError.NewValidationError("Invalid slug provided", nil))
+			return
+		}
+	}
+
+	tx, err := o.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var organization model.Organization
+	query := `
+		INSERT INTO organizations (name, slug, owner_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id, name, slug, owner_id, created_at;
+	`
+
+	// Slugs are unique across organizations, retry generated ones with a fresh suffix
+	nextSlug := func() string { return helper.Slugify(dto.Name) }
+	if customSlug {
+		nextSlug = nil
+	}
+	err = withSlugRetry(ctx, tx, slug, nextSlug, func(slug string) error {
+		return tx.GetContext(ctx, &organization, query, dto.Name, slug, userID)
+	})
+	if err != nil && customSlug && isUniqueViolation(err) {
+		appError.WriteError(w, appError.NewValidationError("Slug already in use", err))
+		return
+	}
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create organization", err))
+		return
+	}
+
+	memberQuery := `INSERT INTO organization_members (org_id, user_id, role, joined_at) VALUES ($1, $2, $3, NOW());`
+	if _, err := tx.ExecContext(ctx, memberQuery, organization.ID, userID, enum.OrgRoleAdmin); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to add organization owner", err))
+		return
+	}
+
+	// The first organization becomes the one the user schedules under
+	userQuery := `UPDATE users SET organization_id = $1, updated_at = NOW() WHERE id = $2 AND organization_id IS NULL;`
+	if _, err := tx.ExecContext(ctx, userQuery, organization.ID, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update user organization", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":      "Organization created successfully",
+		"organization": organization,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// GET /orgs/{orgSlug}/members
+func (o *Controller) GetOrganizationMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.OrgSlugDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Only members see who else is in the organization
+	organization, _, err := o.getOrganizationMembership(ctx, dto.OrgSlug, userID)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	members := []OrganizationMemberDetail{}
+	query := `
+		SELECT om.user_id, u.name, u.email, u.username, u.image_url, om.role, om.joined_at
+		FROM organization_members om
+		JOIN users u ON u.id = om.user_id
+		WHERE om.org_id = $1 AND u.deleted_at IS NULL
+		ORDER BY om.joined_at ASC;
+	`
+	if err := o.db.SelectContext(ctx, &members, query, organization.ID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch organization members", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":      "Fetched organization members successfully",
+		"organization": organization,
+		"members":      members,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// POST /orgs/{orgSlug}/invite
+func (o *Controller) InviteOrganizationMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	params, ok := validator.GetValidatedDTOFromContext[dto.OrgSlugDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.InviteOrganizationMemberDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	organization, role, err := o.getOrganizationMembership(ctx, params.OrgSlug, userID)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+	if role != enum.OrgRoleAdmin {
+		appError.WriteError(w, appError.NewAppError(enum.AccessUnauthorized, "Only organization admins can invite members", nil))
+		return
+	}
+
+	inviteRole := dto.Role
+	if inviteRole == "" {
+		inviteRole = enum.OrgRoleMember
+	}
+	email := strings.ToLower(dto.Email)
+
+	var alreadyMember bool
+	memberQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM organization_members om
+			JOIN users u ON u.id = om.user_id
+			WHERE om.org_id = $1 AND LOWER(u.email) = $2
+		);
+	`
+	if err := o.db.GetContext(ctx, &alreadyMember, memberQuery, organization.ID, email); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check organization members", err))
+		return
+	}
+	if alreadyMember {
+		appError.WriteError(w, appError.NewValidationError("User is already a member of this organization", nil))
+		return
+	}
+
+	var inviterName string
+	if err := o.db.GetContext(ctx, &inviterName, `SELECT name FROM users WHERE id = $1;`, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch user", err))
+		return
+	}
+
+	var invitation model.OrganizationInvitation
+	query := `
+		INSERT INTO organization_invitations (org_id, email, role, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, org_id, email, role, token, invited_by, expires_at, accepted_at, created_at;
+	`
+	err = o.db.GetContext(ctx, &invitation, query, organization.ID, email, inviteRole, userID, time.Now().Add(orgInvitationTTL))
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create invitation", err))
+		return
+	}
+
+	// The invitation stays valid when the email fails, it can be sent again
+	if err := o.mailer.SendOrganizationInvite(invitation.Email, organization.Name, inviterName, invitation.Token); err != nil {
+		log.Printf("Warning: Failed to send organization invite (OrgID: %s): %v\n", organization.ID, err)
+	}
+
+	response := map[string]any{
+		"message":    "Invitation sent successfully",
+		"invitation": invitation,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// POST /orgs/invitations/accept?token=...
+// The invitation can only be accepted by the user whose email it was sent to.
+func (o *Controller) AcceptOrganizationInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.AcceptOrganizationInviteDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	tx, err := o.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var invitation model.OrganizationInvitation
+	query := `
+		SELECT id, org_id, email, role, token, invited_by, expires_at, accepted_at, created_at
+		FROM organization_invitations
+		WHERE token = $1 AND accepted_at IS NULL AND expires_at > NOW()
+		FOR UPDATE;
+	`
+	if err := tx.GetContext(ctx, &invitation, query, dto.Token); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Invitation", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch invitation", err))
+		return
+	}
+
+	var email string
+	if err := tx.GetContext(ctx, &email, `SELECT email FROM users WHERE id = $1 AND deleted_at IS NULL;`, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch user", err))
+		return
+	}
+	if !strings.EqualFold(email, invitation.Email) {
+		appError.WriteError(w, appError.NewAppError(enum.AccessUnauthorized, "This invitation was sent to a different email address", nil))
+		return
+	}
+
+	memberQuery := `
+		INSERT INTO organization_members (org_id, user_id, role, joined_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (org_id, user_id) DO NOTHING;
+	`
+	if _, err := tx.ExecContext(ctx, memberQuery, invitation.OrgID, userID, invitation.Role); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to join organization", err))
+		return
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE organization_invitations SET accepted_at = NOW() WHERE id = $1;`, invitation.ID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to accept invitation", err))
+		return
+	}
+
+	userQuery := `UPDATE users SET organization_id = $1, updated_at = NOW() WHERE id = $2 AND organization_id IS NULL;`
+	if _, err := tx.ExecContext(ctx, userQuery, invitation.OrgID, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update user organization", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Joined organization successfully"})
+}
+
+// getOrganizationMembership fetches the organization by slug together with the role of userID in it.
+// Non-members get a not found error so organizations are not disclosed to outsiders.
+func (o *Controller) getOrganizationMembership(ctx context.Context, orgSlug, userID string) (model.Organization, enum.OrgRole, error) {
+	var membership struct {
+		model.Organization
+		Role enum.OrgRole `db:"role"`
+	}
+
+	query := `
+		SELECT o.id, o.name, o.slug, o.owner_id, o.created_at, om.role
+		FROM organizations o
+		JOIN organization_members om ON om.org_id = o.id AND om.user_id = $2
+		WHERE o.slug = $1;
+	`
+	if err := o.db.GetContext(ctx, &membership, query, orgSlug, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return model.Organization{}, "", appError.NewNotFoundError("Organization", nil)
+		}
+		return model.Organization{}, "", appError.NewAppError(enum.InternalServerError, "Failed to fetch organization", err)
+	}
+
+	return membership.Organization, membership.Role, nil
+}
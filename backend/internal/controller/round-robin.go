@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/lib/pq"
+)
+
+// roundRobinWindow is how far back assignments are counted when picking the next host.
+const roundRobinWindow = 30 * 24 * time.Hour
+
+// RoundRobinHost is a host picked for a round-robin booking.
+type RoundRobinHost struct {
+	UserID string `db:"user_id"`
+	Email  string `db:"email"`
+}
+
+// POST /orgs/{orgSlug}/round-robin
+// Turns an event of an organization member into a round-robin event hosted by hostIds.
+func (rr *Controller) CreateRoundRobinEvent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	params, ok := validator.GetValidatedDTOFromContext[dto.OrgSlugDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateRoundRobinEventDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	organization, role, err := rr.getOrganizationMembership(ctx, params.OrgSlug, userID)
+	if err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+	if role != enum.OrgRoleAdmin {
+		appError.WriteError(w, appError.NewAppError(enum.AccessUnauthorized, "Only organization admins can create round-robin events", nil))
+		return
+	}
+
+	// The event must belong to a member of the organization
+	var eventExists bool
+	eventQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM events e
+			JOIN organization_members om ON om.user_id = e.user_id AND om.org_id = $2
+			WHERE e.id = $1 AND e.deleted_at IS NULL
+		);
+	`
+	if err := rr.db.GetContext(ctx, &eventExists, eventQuery, dto.EventID, organization.ID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch event", err))
+		return
+	}
+	if !eventExists {
+		appError.WriteError(w, appError.NewNotFoundError("Event", nil))
+		return
+	}
+
+	// Every host must be a member of the organization
+	var memberCount int
+	memberQuery := `SELECT COUNT(*) FROM organization_members WHERE org_id = $1 AND user_id = ANY($2);`
+	if err := rr.db.GetContext(ctx, &memberCount, memberQuery, organization.ID, pq.Array(dto.HostIDs)); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to check organization members", err))
+		return
+	}
+	if memberCount != len(dto.HostIDs) {
+		appError.WriteError(w, appError.NewValidationError("All hosts must be members of the organization", nil))
+		return
+	}
+
+	tx, err := rr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var roundRobinEvent model.RoundRobinEvent
+	query := `
+		INSERT INTO round_robin_events (org_id, event_id, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id, org_id, event_id, created_at;
+	`
+	if err := tx.GetContext(ctx, &roundRobinEvent, query, organization.ID, dto.EventID); err != nil {
+		if isUniqueViolation(err) {
+			appError.WriteError(w, appError.NewValidationError("Event is already a round-robin event", err))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to create round-robin event", err))
+		return
+	}
+
+	hostQuery := `INSERT INTO round_robin_event_hosts (round_robin_event_id, user_id) VALUES ($1, $2);`
+	for _, hostID := range dto.HostIDs {
+		if _, err := tx.ExecContext(ctx, hostQuery, roundRobinEvent.ID, hostID); err != nil {
+			appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to add round-robin hosts", err))
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":         "Round-robin event created successfully",
+		"roundRobinEvent": roundRobinEvent,
+		"hostIds":         dto.HostIDs,
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// getRoundRobinEventID returns the ID of the event booked through a round-robin event.
+func (rr *Controller) getRoundRobinEventID(ctx context.Context, roundRobinEventID string) (string, error) {
+	var eventID string
+	err := rr.db.GetContext(ctx, &eventID, `SELECT event_id FROM round_robin_events WHERE id = $1;`, roundRobinEventID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", appError.NewNotFoundError("Round-robin event", nil)
+		}
+		return "", appError.NewAppError(enum.InternalServerError, "Failed to fetch round-robin event", err)
+	}
+	return eventID, nil
+}
+
+// nextRoundRobinHost picks the host of a round-robin event for a booking in [startTime, endTime).
+// Only organization members with a connected calendar and no overlapping meeting are considered,
+// the one with the fewest recent assignments wins and ties go to whoever was assigned longest ago.
+func (rr *Controller) nextRoundRobinHost(ctx context.Context, roundRobinEventID string, locationType enum.EventLocationType, startTime, endTime time.Time) (RoundRobinHost, error) {
+	appType, ok := IntegrationAppTypeFromEventLocation(locationType)
+	if !ok {
+		return RoundRobinHost{}, appError.NewAppError(enum.InternalServerError, "Cannot map event location to integration app type", nil)
+	}
+
+	var host RoundRobinHost
+	query := `
+		SELECT h.user_id, u.email
+		FROM round_robin_event_hosts h
+		JOIN round_robin_events rr ON rr.id = h.round_robin_event_id
+		JOIN users u ON u.id = h.user_id AND u.deleted_at IS NULL
+		JOIN organization_members om ON om.org_id = rr.org_id AND om.user_id = h.user_id
+		JOIN integrations i ON i.user_id = h.user_id AND i.app_type = $2 AND i.is_connected = TRUE
+		WHERE h.round_robin_event_id = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM meetings m
+				WHERE m.user_id = h.user_id AND m.status = $3 AND m.start_time < $4 AND m.end_time > $5
+			)
+		ORDER BY (
+				SELECT COUNT(*) FROM meetings m
+				WHERE m.event_id = rr.event_id AND m.user_id = h.user_id AND m.created_at > $6
+			) ASC,
+			h.last_assigned_at ASC NULLS FIRST
+		LIMIT 1;
+	`
+	err := rr.db.GetContext(ctx, &host, query,
+		roundRobinEventID, appType, enum.Scheduled, endTime, startTime, time.Now().Add(-roundRobinWindow))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return RoundRobinHost{}, appError.NewValidationError("No host is available at the selected time", nil)
+		}
+		return RoundRobinHost{}, appError.NewAppError(enum.InternalServerError, "Failed to assign round-robin host", err)
+	}
+	return host, nil
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/notify"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+)
+
+// POST /me/notifications/slack
+// A host has at most one Slack webhook, posting again replaces it.
+func (s *Controller) SaveSlackWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.SaveSlackWebhookDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Both notifications are on unless turned off explicitly
+	notifyOnCreate := dto.NotifyOnCreate == nil || *dto.NotifyOnCreate
+	notifyOnCancel := dto.NotifyOnCancel == nil || *dto.NotifyOnCancel
+
+	var slackWebhook model.SlackWebhook
+	query := `
+		INSERT INTO slack_webhooks (user_id, webhook_url, notify_on_create, notify_on_cancel, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET webhook_url = EXCLUDED.webhook_url,
+			notify_on_create = EXCLUDED.notify_on_create,
+			notify_on_cancel = EXCLUDED.notify_on_cancel
+		RETURNING id, user_id, webhook_url, notify_on_create, notify_on_cancel, created_at;
+	`
+	err := s.db.GetContext(ctx, &slackWebhook, query, userID, dto.WebhookURL, notifyOnCreate, notifyOnCancel)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to save Slack webhook", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Slack notifications saved successfully",
+		"slack":   slackWebhook,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /me/notifications/slack
+func (s *Controller) GetSlackWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	var slackWebhook model.SlackWebhook
+	query := `
+		SELECT id, user_id, webhook_url, notify_on_create, notify_on_cancel, created_at
+		FROM slack_webhooks WHERE user_id = $1;
+	`
+	if err := s.db.GetContext(ctx, &slackWebhook, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("Slack webhook", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch Slack webhook", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Fetched Slack notifications successfully",
+		"slack":   slackWebhook,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// DELETE /me/notifications/slack
+func (s *Controller) DeleteSlackWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	result, err := s.db.ExecContext(ctx, `DELETE FROM slack_webhooks WHERE user_id = $1;`, userID)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete Slack webhook", err))
+		return
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		appError.WriteError(w, appError.NewNotFoundError("Slack webhook", nil))
+		return
+	}
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Slack notifications removed successfully"})
+}
+
+// notifySlack posts a booked or cancelled meeting to the Slack webhook of the host, if one is set up.
+// It runs in the background so Slack never delays or fails the triggering request.
+// The meeting is expected to carry the joined EventTitle.
+func (s *Controller) notifySlack(userID string, event enum.WebhookEvent, meeting model.Meeting) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		var slackWebhook model.SlackWebhook
+		query := `
+			SELECT id, user_id, webhook_url, notify_on_create, notify_on_cancel, created_at
+			FROM slack_webhooks WHERE user_id = $1;
+		`
+		if err := s.db.GetContext(ctx, &slackWebhook, query, userID); err != nil {
+			if err != sql.ErrNoRows {
+				log.Printf("Warning: Failed to fetch Slack webhook (UserID: %s): %v\n", userID, err)
+			}
+			return
+		}
+
+		var heading string
+		switch {
+		case event == enum.WebhookMeetingCreated && slackWebhook.NotifyOnCreate:
+			heading = "New booking"
+		case event == enum.WebhookMeetingCancelled && slackWebhook.NotifyOnCancel:
+			heading = "Meeting cancelled"
+		default:
+			return
+		}
+
+		message := fmt.Sprintf("*%s:* %s with %s\n%s - %s UTC",
+			heading,
+			meeting.EventTitle,
+			meeting.GuestName,
+			meeting.StartTime.UTC().Format("Mon, 2 Jan 2006 15:04"),
+			meeting.EndTime.UTC().Format("15:04"),
+		)
+		if meeting.MeetLink != "" && event == enum.WebhookMeetingCreated {
+			message += "\n" + meeting.MeetLink
+		}
+
+		if err := notify.SendSlackMessage(slackWebhook.WebhookURL, message); err != nil {
+			log.Printf("Warning: Failed to send Slack notification (UserID: %s, MeetingID: %s): %v\n", userID, meeting.ID, err)
+		}
+	}()
+}
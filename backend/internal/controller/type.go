@@ -1,79 +1,187 @@
-package controller
-
-import (
-	"database/sql"
-
-	"github.com/fazamuttaqien/calendly/internal/model"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-)
-
-type EventWithCount struct {
-	model.Event
-	MeetingCount int `db:"meeting_count"`
-}
-
-type UserWithEvents struct {
-	Username string `db:"username"`
-	Events   []EventWithCount
-}
-
-type PublicUserInfo struct {
-	ID       string         `db:"id"`
-	Name     string         `db:"name"`
-	ImageURL sql.NullString `db:"image_url"`
-}
-
-type PublicUserInfoWithEvents struct {
-	User   PublicUserInfo
-	Events []model.Event
-}
-
-type EventWithPublicUserInfo struct {
-	model.Event
-	User PublicUserInfo `db:"user"`
-}
-
-type AvailabilityResponse struct {
-	TimeGap int                     `json:"timeGap"`
-	Days    []DayAvailabilityDetail `json:"days"`
-}
-
-type DayAvailabilityDetail struct {
-	Day         enum.DayOfWeek `json:"day"`
-	StartTime   string         `json:"startTime"`
-	EndTime     string         `json:"endTime"`
-	IsAvailable bool           `json:"isAvailable"`
-}
-
-type DailyAvailabilitySlots struct {
-	Day         enum.DayOfWeek `json:"day"`
-	Slots       []string       `json:"slots"`
-	IsAvailable bool           `json:"isAvailable"`
-}
-
-// --- Helper Struct for DB Scan in GetUserAvailability ---
-type AvailabilityDetail struct {
-	TimeGap int            `db:"time_gap"`
-	Day     enum.DayOfWeek `db:"day"`
-	// Read TIME type as string initially, parse later
-	StartTime   string `db:"start_time"`
-	EndTime     string `db:"end_time"`
-	IsAvailable bool   `db:"is_available"`
-}
-
-type IntegrationStatus struct {
-	Provider    enum.IntegrationProvider `json:"provider"`
-	Title       string                   `json:"title"`
-	AppType     enum.IntegrationAppType  `json:"app_type"`
-	Category    enum.IntegrationCategory `json:"category"`
-	IsConnected bool                     `json:"isConnected"`
-}
-
-type CreateIntegration struct {
-	UserID       string
-	AppType      enum.IntegrationAppType
-	AccessToken  string
-	RefreshToken sql.NullString
-	ExpiryDate   sql.NullInt64
-	Metadata     any
-}
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+type EventWithCount struct {
+	model.Event
+	MeetingCount int `db:"meeting_count"`
+}
+
+type UserWithEvents struct {
+	Username string `db:"username"`
+	Events   []EventWithCount
+}
+
+// MeetingWithOwner is a meeting joined with the user ID of the event owner.
+type MeetingWithOwner struct {
+	model.Meeting
+	EventUserID string `db:"event_user_id"`
+	HostEmail   string `db:"host_email"` // Only selected where the host is notified
+}
+
+// MeetingDetail is a meeting joined with its event and the owner's username and name.
+type MeetingDetail struct {
+	model.Meeting
+	OwnerUsername string `db:"owner_username" json:"ownerUsername"`
+	OwnerName     string `db:"owner_name" json:"ownerName"`
+}
+
+type PublicUserInfo struct {
+	ID       string         `db:"id"`
+	Name     string         `db:"name"`
+	ImageURL sql.NullString `db:"image_url"`
+}
+
+type PublicUserInfoWithEvents struct {
+	User   PublicUserInfo
+	Events []model.Event
+}
+
+type EventWithPublicUserInfo struct {
+	model.Event
+	User      PublicUserInfo        `db:"user"`
+	Questions []model.EventQuestion `db:"-"`
+}
+
+type AvailabilityResponse struct {
+	TimeGap int                     `json:"timeGap"`
+	Days    []DayAvailabilityDetail `json:"days"`
+}
+
+type DayAvailabilityDetail struct {
+	Day         enum.DayOfWeek `json:"day"`
+	StartTime   string         `json:"startTime"`
+	EndTime     string         `json:"endTime"`
+	IsAvailable bool           `json:"isAvailable"`
+}
+
+// AvailabilityChange is published to the host's availability stream when one of their meetings changes.
+type AvailabilityChange struct {
+	MeetingID string             `json:"meetingId"`
+	EventID   string             `json:"eventId"`
+	StartTime time.Time          `json:"startTime"`
+	EndTime   time.Time          `json:"endTime"`
+	Status    enum.MeetingStatus `json:"status"`
+}
+
+// DailyAvailability is the public availability of a single date.
+type DailyAvailability struct {
+	IsAvailable bool     `json:"isAvailable"`
+	Slots       []string `json:"slots"`
+}
+
+// --- Helper Struct for DB Scan in GetUserAvailability ---
+type AvailabilityDetail struct {
+	TimeGap int            `db:"time_gap"`
+	Day     enum.DayOfWeek `db:"day"`
+	// Read TIME type as string initially, parse later
+	StartTime   string `db:"start_time"`
+	EndTime     string `db:"end_time"`
+	IsAvailable bool   `db:"is_available"`
+}
+
+type IntegrationStatus struct {
+	Provider    enum.IntegrationProvider `json:"provider"`
+	Title       string                   `json:"title"`
+	AppType     enum.IntegrationAppType  `json:"app_type"`
+	Category    enum.IntegrationCategory `json:"category"`
+	IsConnected bool                     `json:"isConnected"`
+}
+
+// IntegrationStatusPage is a page of the integration catalogue, split by connection state.
+type IntegrationStatusPage struct {
+	Connected []IntegrationStatus `json:"connected"`
+	Available []IntegrationStatus `json:"available"` // Not connected yet
+}
+
+// IntegrationListResponse is the standard paginated envelope of the integration catalogue, data holds the page
+// in order and connected/available hold the same entries split by connection state.
+type IntegrationListResponse struct {
+	helper.PaginatedResponse[IntegrationStatus]
+	IntegrationStatusPage
+}
+
+type CreateIntegration struct {
+	UserID       string
+	AppType      enum.IntegrationAppType
+	AccessToken  string
+	RefreshToken sql.NullString
+	ExpiryDate   sql.NullInt64
+	Metadata     any
+}
+
+// googleUserInfo is the subset of Google's OpenID userinfo response used for sign-in.
+type googleUserInfo struct {
+	Sub           string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+// HostAnalytics holds the aggregate booking stats shown on the host dashboard.
+type HostAnalytics struct {
+	TotalEvents         int             `db:"total_events" json:"totalEvents"`
+	TotalMeetings       int             `db:"total_meetings" json:"totalMeetings"`
+	CancelledMeetings   int             `db:"cancelled_meetings" json:"cancelledMeetings"`
+	UpcomingMeetings    int             `db:"upcoming_meetings" json:"upcomingMeetings"`
+	NoShowMeetings      int             `db:"no_show_meetings" json:"noShowMeetings"`
+	MeetingsPerEvent    []EventMeetings `json:"meetingsPerEvent"`
+	PopularSlots        []HourlyCount   `json:"popularSlots"`
+	AvgBookingLeadHours float64         `db:"avg_booking_lead_hours" json:"avgBookingLeadHours"`
+}
+
+// EventAnalytics holds the booking stats of a single event. The breakdowns are
+// aggregated to JSON by the database and passed through as is.
+type EventAnalytics struct {
+	TotalBookings     int             `db:"total_bookings" json:"totalBookings"`
+	CancelledBookings int             `db:"cancelled_bookings" json:"cancelledBookings"`
+	NoShowBookings    int             `db:"no_show_bookings" json:"noShowBookings"`
+	UpcomingBookings  int             `db:"upcoming_bookings" json:"upcomingBookings"`
+	BookingsByDay     json.RawMessage `db:"bookings_by_day" json:"bookingsByDay"`   // [{dayOfWeek, count}], 0 is Sunday
+	BookingsByHour    json.RawMessage `db:"bookings_by_hour" json:"bookingsByHour"` // [{hour, count}] in UTC
+	AvgLeadTimeHours  float64         `db:"avg_lead_time_hours" json:"avgLeadTimeHours"`
+	TopGuestDomains   json.RawMessage `db:"top_guest_domains" json:"topGuestDomains"` // [{domain, count}]
+	LastBookedAt      *time.Time      `db:"last_booked_at" json:"lastBookedAt"`
+}
+
+type EventMeetings struct {
+	EventID    string `db:"event_id" json:"eventId"`
+	EventTitle string `db:"event_title" json:"eventTitle"`
+	Count      int    `db:"count" json:"count"`
+}
+
+type HourlyCount struct {
+	Hour  int `db:"hour" json:"hour"`
+	Count int `db:"count" json:"count"`
+}
+
+// BusyBlock is a period the host is busy in an external calendar.
+type BusyBlock struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DataExport is the personal data document returned by GET /me/data-export.
+type DataExport struct {
+	ExportedAt   time.Time              `json:"exportedAt"`
+	User         model.User             `json:"user"`
+	Events       []model.Event          `json:"events"`
+	Meetings     []model.Meeting        `json:"meetings"`
+	Availability DataExportAvailability `json:"availability"`
+	Integrations []model.Integration    `json:"integrations"` // Token columns are never selected
+}
+
+type DataExportAvailability struct {
+	*AvailabilityResponse
+	Exceptions     []model.AvailabilityException `json:"exceptions"`
+	VacationBlocks []model.VacationBlock         `json:"vacationBlocks"`
+}
@@ -0,0 +1,537 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/audit"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	pkgJwt "github.com/fazamuttaqien/calendly/pkg/jwt"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+)
+
+// GET /me
+func (u *Controller) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// Same fields as the user returned on login, the password hash is never selected
+	var user model.User
+	query := `
+		SELECT id, name, email, username, image_url, is_verified, role, organization_id, timezone, digest_opt_out, created_at, updated_at
+		FROM users
+		WHERE id = $1 AND deleted_at IS NULL;
+	`
+	if err := u.db.GetContext(ctx, &user, query, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("User", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to fetch user", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Fetched user successfully",
+		"user":    user,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /me
+// Updates the settings of the current user, e.g. the timezone and opt-out of the daily digest.
+func (u *Controller) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.UpdateSettingsDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	var user model.User
+	query := `
+		UPDATE users
+		SET timezone = COALESCE($1, timezone), digest_opt_out = COALESCE($2, digest_opt_out), updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING id, name, email, username, image_url, is_verified, role, organization_id, timezone, digest_opt_out, created_at, updated_at;
+	`
+	if err := u.db.GetContext(ctx, &user, query, dto.Timezone, dto.DigestOptOut, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewNotFoundError("User", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update settings", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Settings updated successfully",
+		"user":    user,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// PATCH /me/password
+func (u *Controller) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+	userID := claims.UserID
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.ChangePasswordDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Fetch the current password hash
+	var user model.User
+	err := u.db.GetContext(ctx, &user, `SELECT id, password, role, google_id FROM users WHERE id = $1;`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "User not found", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to query user", err))
+		return
+	}
+
+	// 2. Compare current password
+	if err := reauthenticate(claims, user, dto.CurrentPassword); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// 3. Hash new password
+	newHashedPassword, err := helper.HashPassword(dto.NewPassword)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to hash password", err))
+		return
+	}
+
+	// 4. Update password, every token issued before now is rejected by the auth middleware
+	query := `
+		UPDATE users
+		SET password = $1, tokens_invalid_before = date_trunc('second', NOW()), updated_at = NOW()
+		WHERE id = $2;
+	`
+	if _, err := u.db.ExecContext(ctx, query, newHashedPassword, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to update password", err))
+		return
+	}
+
+	// 5. Issue a fresh token so the current session stays signed in
+	accessToken, expiresAt, err := pkgJwt.SignJwtToken(userID, user.Role)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to generate access token", err))
+		return
+	}
+
+	u.recordAudit(r, audit.AuditEntry{
+		UserID:     userID,
+		Action:     audit.ActionUserPasswordChange,
+		EntityType: audit.EntityUser,
+		EntityID:   userID,
+		Metadata:   map[string]any{"tokenExpiresAt": expiresAt},
+	})
+
+	response := map[string]any{
+		"message":     "Password changed successfully",
+		"accessToken": accessToken,
+		"expiresAt":   expiresAt,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// GET /me/analytics
+func (u *Controller) GetAnalytics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	// 1. Totals and average lead time in a single pass over the host's meetings
+	var analytics HostAnalytics
+	totalsQuery := `
+		SELECT
+			(SELECT COUNT(*) FROM events WHERE user_id = $1 AND deleted_at IS NULL) AS total_events,
+			COUNT(*) AS total_meetings,
+			COUNT(*) FILTER (WHERE status = $2) AS cancelled_meetings,
+			COUNT(*) FILTER (WHERE status = $3 AND start_time > NOW()) AS upcoming_meetings,
+			COUNT(*) FILTER (WHERE status = $4) AS no_show_meetings,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (start_time - created_at)) / 3600), 0) AS avg_booking_lead_hours
+		FROM meetings
+		WHERE user_id = $1;
+	`
+	err := u.db.GetContext(ctx, &analytics, totalsQuery, userID, enum.Cancelled, enum.Scheduled, enum.NoShow)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to compute meeting totals", err))
+		return
+	}
+
+	// 2. Meetings per event, including events without bookings
+	analytics.MeetingsPerEvent = []EventMeetings{}
+	perEventQuery := `
+		SELECT e.id AS event_id, e.title AS event_title, COUNT(m.id) AS count
+		FROM events e
+		LEFT JOIN meetings m ON m.event_id = e.id
+		WHERE e.user_id = $1 AND e.deleted_at IS NULL
+		GROUP BY e.id, e.title
+		ORDER BY count DESC, e.title ASC;
+	`
+	if err := u.db.SelectContext(ctx, &analytics.MeetingsPerEvent, perEventQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to compute meetings per event", err))
+		return
+	}
+
+	// 3. Most booked start hours, cancelled meetings excluded
+	analytics.PopularSlots = []HourlyCount{}
+	slotsQuery := `
+		SELECT EXTRACT(HOUR FROM start_time)::INT AS hour, COUNT(*) AS count
+		FROM meetings
+		WHERE user_id = $1 AND status <> $2
+		GROUP BY hour
+		ORDER BY count DESC, hour ASC;
+	`
+	if err := u.db.SelectContext(ctx, &analytics.PopularSlots, slotsQuery, userID, enum.Cancelled); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to compute popular slots", err))
+		return
+	}
+
+	response := map[string]any{
+		"message":   "Fetched analytics successfully",
+		"analytics": analytics,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
+
+// dataExportTimeout bounds the queries of a data export, the router's 60s timeout still applies on top
+const dataExportTimeout = 30 * time.Second
+
+// GET /me/data-export
+func (u *Controller) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dataExportTimeout)
+	defer cancel()
+
+	// All reads share one snapshot so the document is consistent
+	tx, err := u.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Read-only, nothing to commit
+
+	export := DataExport{
+		ExportedAt:   time.Now().UTC(),
+		Events:       []model.Event{},
+		Meetings:     []model.Meeting{},
+		Integrations: []model.Integration{},
+		Availability: DataExportAvailability{
+			Exceptions:     []model.AvailabilityException{},
+			VacationBlocks: []model.VacationBlock{},
+		},
+	}
+
+	// 1. Profile
+	userQuery := `
+		SELECT id, name, email, username, image_url, is_verified, role, organization_id, created_at, updated_at
+		FROM users WHERE id = $1;
+	`
+	if err := tx.GetContext(ctx, &export.User, userQuery, userID); err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "User not found", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export profile", err))
+		return
+	}
+
+	// 2. Events, archived ones included
+	eventsQuery := `
+		SELECT id, user_id, title, COALESCE(description, '') AS description, duration, slug,
+			is_private, location_types, color, created_at, updated_at, deleted_at
+		FROM events WHERE user_id = $1
+		ORDER BY created_at;
+	`
+	if err := tx.SelectContext(ctx, &export.Events, eventsQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export events", err))
+		return
+	}
+
+	// 3. Meetings, including guest details
+	meetingsQuery := `
+		SELECT
+			m.*,
+			e.title AS event_title,
+			COALESCE(e.description, '') AS event_description
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE m.user_id = $1
+		ORDER BY m.start_time;
+	`
+	if err := tx.SelectContext(ctx, &export.Meetings, meetingsQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export meetings", err))
+		return
+	}
+
+	// 4. Availability settings
+	var dbDetail []AvailabilityDetail
+	availabilityQuery := `
+		SELECT a.time_gap, d.day, d.start_time::TEXT, d.end_time::TEXT, d.is_available
+		FROM availability a
+		JOIN day_availability d ON a.id = d.availability_id
+		WHERE a.user_id = $1;
+	`
+	if err := tx.SelectContext(ctx, &dbDetail, availabilityQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export availability", err))
+		return
+	}
+	export.Availability.AvailabilityResponse = buildAvailabilityResponse(dbDetail)
+
+	exceptionsQuery := `
+		SELECT x.id, x.availability_id, x.exception_date, x.reason, x.is_available, x.created_at
+		FROM availability_exceptions x
+		JOIN availability a ON x.availability_id = a.id
+		WHERE a.user_id = $1
+		ORDER BY x.exception_date;
+	`
+	if err := tx.SelectContext(ctx, &export.Availability.Exceptions, exceptionsQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export availability exceptions", err))
+		return
+	}
+
+	vacationQuery := `
+		SELECT id, user_id, start_date, end_date, reason, created_at
+		FROM vacation_blocks WHERE user_id = $1
+		ORDER BY start_date;
+	`
+	if err := tx.SelectContext(ctx, &export.Availability.VacationBlocks, vacationQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export vacation blocks", err))
+		return
+	}
+
+	// 5. Integrations, token columns are deliberately left out
+	integrationsQuery := `
+		SELECT id, user_id, provider, category, app_type, metadata, is_connected, created_at, updated_at
+		FROM integrations WHERE user_id = $1
+		ORDER BY created_at;
+	`
+	if err := tx.SelectContext(ctx, &export.Integrations, integrationsQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to export integrations", err))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="data-export-%s.json"`, userID))
+	helper.ResponseJson(w, http.StatusOK, export)
+}
+
+// DELETE /me
+func (u *Controller) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	claims, ok := middleware.GetClaimsFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+	userID := claims.UserID
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.DeleteAccountDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// 1. Re-verify the password
+	var user model.User
+	err := u.db.GetContext(ctx, &user, `SELECT id, username, password, google_id FROM users WHERE id = $1 AND deleted_at IS NULL;`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			appError.WriteError(w, appError.NewAppError(enum.AuthUserNotFound, "User not found", nil))
+			return
+		}
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to query user", err))
+		return
+	}
+
+	if err := reauthenticate(claims, user, dto.Password); err != nil {
+		appError.WriteError(w, err)
+		return
+	}
+
+	// 2. Cancel scheduled meetings while the calendar integration still works (best effort),
+	// guests are notified but the host email is left out as the account is going away
+	var meetings []MeetingWithOwner
+	meetingsQuery := `
+		SELECT m.*, e.user_id AS event_user_id, e.title AS event_title
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		WHERE e.user_id = $1 AND m.status = $2;
+	`
+	if err := u.db.SelectContext(ctx, &meetings, meetingsQuery, userID, enum.Scheduled); err != nil {
+		log.Printf("Warning: Failed to fetch meetings to cancel for deleted user %s: %v\n", userID, err)
+	}
+	for _, meeting := range meetings {
+		if err := u.cancelMeeting(ctx, meeting); err != nil {
+			log.Printf("Warning: Failed to cancel meeting %s of deleted user %s: %v\n", meeting.ID, userID, err)
+		}
+	}
+
+	// 3. Revoke OAuth tokens at the providers (best effort)
+	var integrations []model.Integration
+	integrationsQuery := `
+		SELECT id, app_type, access_token, refresh_token
+		FROM integrations
+		WHERE user_id = $1 AND is_connected = TRUE;
+	`
+	if err := u.db.SelectContext(ctx, &integrations, integrationsQuery, userID); err != nil {
+		log.Printf("Warning: Failed to fetch integrations to revoke for deleted user %s: %v\n", userID, err)
+	}
+	for _, integration := range integrations {
+		revokeIntegrationToken(ctx, integration)
+	}
+
+	// 4. Soft delete, disconnect the integrations and blacklist the token used for this request together,
+	// from here on the account can't sign in or use its tokens and its events are hidden.
+	// The rows themselves are erased by the purge job once the grace period is over
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to begin transaction", err))
+		return
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// The Google ID is released so the same Google account can sign up again
+	softDeleteQuery := `
+		UPDATE users
+		SET deleted_at = NOW(), tokens_invalid_before = date_trunc('second', NOW()), google_id = NULL, updated_at = NOW()
+		WHERE id = $1;
+	`
+	if _, err := tx.ExecContext(ctx, softDeleteQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to mark account as deleted", err))
+		return
+	}
+
+	softDeleteEventsQuery := `UPDATE events SET deleted_at = NOW(), updated_at = NOW() WHERE user_id = $1 AND deleted_at IS NULL;`
+	if _, err := tx.ExecContext(ctx, softDeleteEventsQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to delete events", err))
+		return
+	}
+
+	// Their tokens were revoked above or are about to be unusable
+	disconnectQuery := `UPDATE integrations SET is_connected = FALSE, updated_at = NOW() WHERE user_id = $1;`
+	if _, err := tx.ExecContext(ctx, disconnectQuery, userID); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to disconnect integrations", err))
+		return
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	revokeQuery := `
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING;
+	`
+	if _, err := tx.ExecContext(ctx, revokeQuery, claims.ID, expiresAt); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to revoke token", err))
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to commit transaction", err))
+		return
+	}
+
+	u.publicEventsCache.Invalidate(user.Username)
+
+	// Kept without a user reference so the erasure itself stays accountable after the purge
+	u.recordAudit(r, audit.AuditEntry{
+		Action:     audit.ActionUserDelete,
+		EntityType: audit.EntityUser,
+		EntityID:   userID,
+	})
+
+	helper.ResponseJson(w, http.StatusOK, helper.SimpleMessage{Message: "Account deleted successfully"})
+}
+
+// recentLoginWindow is how old a token may be to stand in for the password, see reauthenticate
+const recentLoginWindow = 5 * time.Minute
+
+// reauthenticate confirms a sensitive change with the user's password. Accounts created through Google sign-in
+// have a random password nobody knows, so for accounts linked to Google a token issued in the last few minutes,
+// i.e. a fresh sign-in, is accepted instead.
+func reauthenticate(claims *pkgJwt.JWTCustomClaims, user model.User, password string) error {
+	if password != "" {
+		if err := helper.ComparePassword(user.Password, password); err != nil {
+			return appError.NewAppError(enum.AuthUnauthorizedAccess, "Password is incorrect", nil)
+		}
+		return nil
+	}
+
+	if user.GoogleID.Valid && claims.IssuedAt != nil && time.Since(claims.IssuedAt.Time) <= recentLoginWindow {
+		return nil
+	}
+	return appError.NewAppError(enum.AuthUnauthorizedAccess, "Password is required, accounts linked to Google may sign in with Google again instead", nil)
+}
+
+// GET /me/token-info
+// Lets clients holding a stored token find out when it expires.
+func (u *Controller) GetTokenInfo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetClaimsFromContext(r.Context())
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	var issuedAt, expiresAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	response := map[string]any{
+		"message":   "Fetched token info successfully",
+		"userId":    claims.UserID,
+		"issuedAt":  issuedAt,
+		"expiresAt": expiresAt,
+	}
+	helper.ResponseJson(w, http.StatusOK, response)
+}
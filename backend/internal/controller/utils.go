@@ -1,237 +1,697 @@
-package controller
-
-import (
-	"context"
-	"fmt"
-	"log"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/internal/model"
-	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"golang.org/x/oauth2"
-	"google.golang.org/api/calendar/v3"
-	"google.golang.org/api/option"
-)
-
-const (
-	// Layout for HH:MM format
-	layoutHM = "15:04"
-	// Layout for YYYY-MM-DD format
-	layoutDate = "2006-01-02"
-	// Layout for DB TIME format (adjust if different)
-	layoutDBTime = "15:04:05"
-)
-
-// GetNextDateForDay calculates the date of the next occurrence of a given weekday.
-func GetNextDateForDay(dayOfWeek enum.DayOfWeek) (time.Time, error) {
-	days := map[enum.DayOfWeek]time.Weekday{
-		enum.Sunday:    time.Sunday,
-		enum.Monday:    time.Monday,
-		enum.Tuesday:   time.Tuesday,
-		enum.Wednesday: time.Wednesday,
-		enum.Thursday:  time.Thursday,
-		enum.Friday:    time.Friday,
-		enum.Saturday:  time.Saturday,
-	}
-
-	targetWeekDay, ok := days[dayOfWeek]
-	if !ok {
-		return time.Time{}, fmt.Errorf("invalid day of week: %s", dayOfWeek)
-	}
-
-	today := time.Now()
-	todayWeekDay := today.Weekday()
-
-	daysUntilTarget := int(targetWeekDay-todayWeekDay+7) % 7
-
-	// If today is the target day, calculate slots for today unless daysUntilTarget is explicitly 0
-	// This logic needs refinement based on whether "next date" includes today
-	// Let's assume getNextDateForDay means "today or the next occurrence"
-
-	// if daysUntilTarget == 0 && targetWeekday != todayWeekday {
-	// 	daysUntilTarget = 7 // If target is same day next week
-	// }
-
-	// Simpler: always calculate from today
-	nextDate := today.AddDate(0, 0, daysUntilTarget)
-	// Return date part only (midnight)
-	year, month, day := nextDate.Date()
-
-	return time.Date(year, month, day, 0, 0, 0, 0, today.Location()), nil
-}
-
-// GenerateAvailableTimeSlots creates HH:MM slots based on availability, duration, and existing meetings.
-func GenerateAvailableTimeSlots(dayStartTimeStr, dayEndTimeStr string, durationMinutes, timeGapMinutes int, meetingsOnDate []model.Meeting, targetDate time.Time,
-) ([]string, error) {
-
-	// Parse the start/end times from DB format
-	dayStartParsed, err := time.Parse(layoutDBTime, dayStartTimeStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid start time format '%s': %w", dayStartTimeStr, err)
-	}
-
-	dayEndParsed, err := time.Parse(layoutDBTime, dayEndTimeStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid end time format '%s': %w", dayEndTimeStr, err)
-	}
-
-	// Combine targetDate with parsed times
-	location := targetDate.Location() // Use location of targetDate
-
-	slotStartBase := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		dayStartParsed.Hour(), dayEndParsed.Minute(), 0, 0, location)
-
-	dayEndBoundary := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		dayEndParsed.Hour(), dayEndParsed.Minute(), 0, 0, location)
-
-	slots := []string{}
-	now := time.Now().In(location)
-
-	currentSlotStart := slotStartBase
-
-	if timeGapMinutes <= 0 {
-		timeGapMinutes = 30
-	}
-
-	for currentSlotStart.Before(dayEndBoundary) {
-		slotEnd := currentSlotStart.Add(time.Minute * time.Duration(durationMinutes))
-
-		// Ensure slot doesn't exceed the day's end boundary
-		if slotEnd.After(dayEndBoundary) {
-			break
-		}
-
-		// Check if slot is in the future (relative to 'now')
-		isFutureSlot := currentSlotStart.After(now) || currentSlotStart.Equal(now) // Allow slot starting exactly now
-
-		if isFutureSlot && IsSlotAvailable(currentSlotStart, slotEnd, meetingsOnDate) {
-			slots = append(slots, currentSlotStart.Format(layoutHM))
-		}
-
-		// Move to the next potential slot start time
-		currentSlotStart = currentSlotStart.Add(time.Minute * time.Duration(timeGapMinutes))
-	}
-
-	return slots, nil
-}
-
-// GetCalendarClient helper initializes the Google Calendar client, handling token refresh.
-func GetCalendarClient(ctx context.Context, integration model.Integration) (*calendar.Service, enum.IntegrationAppType, error) {
-	appType := integration.AppType // Get app type from the integration model
-
-	switch appType {
-	case enum.AppGoogleMeetAndCalendar:
-		if !integration.RefreshToken.Valid || integration.RefreshToken.String == "" {
-			// Handle case where refresh token is missing but required
-			return nil, appType, appError.NewAppError(enum.AuthUnauthorizedAccess, "Google integration missing refresh token for offline access.", nil)
-		}
-
-		// Use the IntegrationService's token validation logic
-		// This assumes ValidateGoogleToken handles refresh and returns a valid access token
-		// AND that the IntegrationService *persists* the refreshed token if necessary.
-		validAccessToken, err := ValidateGoogleToken(
-			ctx,
-			integration.AccessToken.String,
-			integration.RefreshToken.String, // Pass the string value
-			integration.ExpiryDate.Int64,    // Pass the int64 value
-		)
-		if err != nil {
-			return nil, appType, appError.NewAppError(enum.AuthInvalidToken, "Failed to validate/refresh Google token", err)
-		}
-
-		// Prepare token struct for oauth2 client
-		token := &oauth2.Token{
-			AccessToken:  validAccessToken,
-			RefreshToken: integration.RefreshToken.String, // Include refresh token if present
-			// Expiry: time.Unix(integration.ExpiryDate.Int64, 0), // Expiry is handled by TokenSource implicitly
-		}
-		if integration.ExpiryDate.Valid {
-			token.Expiry = time.Unix(integration.ExpiryDate.Int64, 0)
-		}
-
-		// Use global oauth config (initialized in integration service or elsewhere)
-		httpClient := googleOAuthConfig.Client(ctx, token)
-
-		// Create Calendar service
-		calendarSvc, err := calendar.NewService(ctx, option.WithHTTPClient(httpClient))
-		if err != nil {
-			return nil, appType, appError.NewAppError(enum.InternalServerError, "Failed to create Google Calendar service client", err)
-		}
-		return calendarSvc, appType, nil
-
-	// case codes.AppOutlookCalendar:
-	// TODO: Implement Outlook Calendar client initialization
-	// return nil, appType, apperror.NewAppError(enums.InternalServerError, "Outlook Calendar not implemented", nil)
-
-	default:
-		msg := fmt.Sprintf("Unsupported calendar provider app type: %s", appType)
-		return nil, appType, appError.NewAppError(enum.BadRequest, msg, nil)
-	}
-}
-
-// IsSlotAvailable checks if a potential slot conflicts with existing meetings.
-func IsSlotAvailable(slotStart, slotEnd time.Time, meetings []model.Meeting) bool {
-	for _, meeting := range meetings {
-		// Check for overlap: (SlotStart < MeetingEnd) and (SlotEnd > MeetingStart)
-		if slotStart.Before(meeting.EndTime) && slotEnd.After(meeting.StartTime) {
-			return false // Slot overlaps with a meeting
-		}
-	}
-
-	return true
-}
-
-func IntegrationAppTypeFromEventLocation(loc enum.EventLocationType) (enum.IntegrationAppType, bool) {
-	switch loc {
-	case enum.LocationGoogleMeetAndCalendar:
-		return enum.AppGoogleMeetAndCalendar, true
-	case enum.LocationZoomMeeting:
-		// Assuming Zoom might relate to a Zoom integration app type
-		return enum.AppZoomMeeting, true
-	default:
-		return "", false
-	}
-}
-
-// ValidateGoogleToken checks expiry and refreshes if needed using oauth2 package.
-func ValidateGoogleToken(ctx context.Context, accessToken, refreshToken string, expiryDateUnix int64) (string, error) {
-	// Convert expiryDateUnix (assuming seconds) to time.Time
-	expiryTime := time.Unix(expiryDateUnix, 0)
-
-	if refreshToken == "" {
-		// If no refresh token, we can't refresh. Check expiry but return current token.
-		if time.Now().After(expiryTime) {
-			// Optionally return an error indicating expired token and no refresh capability
-			// return accessToken, fmt.Errorf("token expired and no refresh token available")
-		}
-		return accessToken, nil // Cannot refresh
-	}
-
-	currentToken := &oauth2.Token{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		Expiry:       expiryTime,
-	}
-
-	// Create a TokenSource with the existing token
-	tokenSource := googleOAuthConfig.TokenSource(ctx, currentToken)
-
-	// GetToken will automatically refresh if the token is expired or close to expiry
-	newToken, err := tokenSource.Token()
-	if err != nil {
-		// Handle refresh errors (e.g., invalid grant)
-		return "", appError.NewAppError(enum.AuthInvalidToken, "Failed to refresh Google token", err)
-	}
-
-	// Check if the token was actually refreshed
-	if newToken.AccessToken != accessToken {
-		log.Printf("Google token refreshed.")
-		// IMPORTANT: You should PERSIST the potentially new newToken.AccessToken,
-		// newToken.RefreshToken (if changed), and newToken.Expiry back to your database here!
-		// errUpdate := s.UpdateIntegrationToken(...)
-		// if errUpdate != nil { ... handle update error ... }
-	}
-
-	return newToken.AccessToken, nil
-}
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/circuitbreaker"
+	"github.com/fazamuttaqien/calendly/pkg/crypto"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/tracing"
+	"github.com/fazamuttaqien/calendly/pkg/zoom"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+const (
+	// Layout for HH:MM format
+	layoutHM = "15:04"
+	// Layout for YYYY-MM-DD format
+	layoutDate = "2006-01-02"
+	// Layout for DB TIME format (adjust if different)
+	layoutDBTime = "15:04:05"
+
+	// Default and maximum number of days returned by public availability
+	defaultAvailabilityRangeDays = 7
+	maxAvailabilityRangeDays     = 60
+
+	// Longest vacation block a host can create
+	maxVacationBlockDays = 365
+
+	// Default page size of the event list
+	defaultEventPageLimit = 20
+
+	// Times withSlugRetry regenerates a slug that is already taken
+	maxSlugAttempts = 5
+
+	// Postgres error code for unique constraint violations
+	pgUniqueViolation = "23505"
+)
+
+// errSlugTaken lets the insert of withSlugRetry report a taken slug that no unique constraint catches.
+var errSlugTaken = errors.New("slug already taken")
+
+// withSlugRetry calls insert with slug inside a savepoint of tx. When the slug is taken, reported by a unique
+// violation or errSlugTaken, the savepoint keeps the transaction usable and insert is retried with nextSlug(),
+// up to maxSlugAttempts times. A nil nextSlug, e.g. for a slug the user chose, returns the first error as is.
+func withSlugRetry(ctx context.Context, tx *sqlx.Tx, slug string, nextSlug func() string, insert func(slug string) error) error {
+	for attempt := 1; ; attempt++ {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT slug_attempt"); err != nil {
+			return err
+		}
+
+		err := insert(slug)
+		taken := isUniqueViolation(err) || errors.Is(err, errSlugTaken)
+		if !taken || nextSlug == nil || attempt == maxSlugAttempts {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT slug_attempt"); err != nil {
+			return err
+		}
+		slug = nextSlug()
+	}
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation
+}
+
+// GetNextDateForDay calculates the date of the next occurrence of a given weekday.
+func GetNextDateForDay(dayOfWeek enum.DayOfWeek) (time.Time, error) {
+	days := map[enum.DayOfWeek]time.Weekday{
+		enum.Sunday:    time.Sunday,
+		enum.Monday:    time.Monday,
+		enum.Tuesday:   time.Tuesday,
+		enum.Wednesday: time.Wednesday,
+		enum.Thursday:  time.Thursday,
+		enum.Friday:    time.Friday,
+		enum.Saturday:  time.Saturday,
+	}
+
+	targetWeekDay, ok := days[dayOfWeek]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid day of week: %s", dayOfWeek)
+	}
+
+	today := time.Now()
+	todayWeekDay := today.Weekday()
+
+	daysUntilTarget := int(targetWeekDay-todayWeekDay+7) % 7
+
+	// If today is the target day, calculate slots for today unless daysUntilTarget is explicitly 0
+	// This logic needs refinement based on whether "next date" includes today
+	// Let's assume getNextDateForDay means "today or the next occurrence"
+
+	// if daysUntilTarget == 0 && targetWeekday != todayWeekday {
+	// 	daysUntilTarget = 7 // If target is same day next week
+	// }
+
+	// Simpler: always calculate from today
+	nextDate := today.AddDate(0, 0, daysUntilTarget)
+	// Return date part only (midnight)
+	year, month, day := nextDate.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, today.Location()), nil
+}
+
+// DayOfWeekFromDate returns the DayOfWeek enum value for the weekday of date.
+func DayOfWeekFromDate(date time.Time) enum.DayOfWeek {
+	return enum.DayOfWeek(strings.ToUpper(date.Weekday().String()))
+}
+
+// escapeLikePattern escapes the LIKE/ILIKE wildcards in s so it is matched literally.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// dateRange is an inclusive range of YYYY-MM-DD dates.
+type dateRange struct {
+	Start string
+	End   string
+}
+
+// mergeDateRanges sorts vacation blocks and merges overlapping or adjacent ones.
+func mergeDateRanges(blocks []model.VacationBlock) []dateRange {
+	sorted := slices.Clone(blocks)
+	slices.SortFunc(sorted, func(a, b model.VacationBlock) int { return a.StartDate.Compare(b.StartDate) })
+
+	merged := make([]dateRange, 0, len(sorted))
+	for _, block := range sorted {
+		start := block.StartDate.Format(layoutDate)
+		end := block.EndDate.Format(layoutDate)
+
+		if n := len(merged); n > 0 {
+			dayBeforeStart := block.StartDate.AddDate(0, 0, -1).Format(layoutDate)
+			if start <= merged[n-1].End || dayBeforeStart == merged[n-1].End {
+				if end > merged[n-1].End {
+					merged[n-1].End = end
+				}
+				continue
+			}
+		}
+		merged = append(merged, dateRange{Start: start, End: end})
+	}
+	return merged
+}
+
+// dateInRanges reports whether the YYYY-MM-DD date falls within any of the ranges.
+func dateInRanges(date string, ranges []dateRange) bool {
+	for _, rng := range ranges {
+		if date >= rng.Start && date <= rng.End {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeEventCursor encodes the last-seen (created_at, id) pair of an event list page.
+func encodeEventCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// decodeEventCursor reverses encodeEventCursor.
+func decodeEventCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor encoding: %w", err)
+	}
+
+	createdAtStr, id, found := strings.Cut(string(raw), "|")
+	if !found || id == "" {
+		return time.Time{}, "", fmt.Errorf("invalid cursor format")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor time: %w", err)
+	}
+	return createdAt, id, nil
+}
+
+// GenerateAvailableTimeSlots creates HH:MM slots based on availability, duration, and existing meetings.
+func GenerateAvailableTimeSlots(dayStartTimeStr, dayEndTimeStr string, durationMinutes, timeGapMinutes int, meetingsOnDate []model.Meeting, busyOnDate []BusyBlock, targetDate time.Time,
+) ([]string, error) {
+
+	// Parse the start/end times from DB format
+	dayStartParsed, err := time.Parse(layoutDBTime, dayStartTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time format '%s': %w", dayStartTimeStr, err)
+	}
+
+	dayEndParsed, err := time.Parse(layoutDBTime, dayEndTimeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end time format '%s': %w", dayEndTimeStr, err)
+	}
+
+	// Combine targetDate with parsed times
+	location := targetDate.Location() // Use location of targetDate
+
+	slotStartBase := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
+		dayStartParsed.Hour(), dayEndParsed.Minute(), 0, 0, location)
+
+	dayEndBoundary := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
+		dayEndParsed.Hour(), dayEndParsed.Minute(), 0, 0, location)
+
+	slots := []string{}
+	now := time.Now().In(location)
+
+	currentSlotStart := slotStartBase
+
+	if timeGapMinutes <= 0 {
+		timeGapMinutes = 30
+	}
+
+	for currentSlotStart.Before(dayEndBoundary) {
+		slotEnd := currentSlotStart.Add(time.Minute * time.Duration(durationMinutes))
+
+		// Ensure slot doesn't exceed the day's end boundary
+		if slotEnd.After(dayEndBoundary) {
+			break
+		}
+
+		// Check if slot is in the future (relative to 'now')
+		isFutureSlot := currentSlotStart.After(now) || currentSlotStart.Equal(now) // Allow slot starting exactly now
+
+		if isFutureSlot && IsSlotAvailable(currentSlotStart, slotEnd, meetingsOnDate, busyOnDate) {
+			slots = append(slots, currentSlotStart.Format(layoutHM))
+		}
+
+		// Move to the next potential slot start time
+		currentSlotStart = currentSlotStart.Add(time.Minute * time.Duration(timeGapMinutes))
+	}
+
+	return slots, nil
+}
+
+// googleCalendarBreaker guards Google Calendar calls, once Google keeps failing bookings
+// fail fast instead of each waiting for a timeout.
+var googleCalendarBreaker = circuitbreaker.New(
+	"google-calendar",
+	helper.GetEnvInt("GOOGLE_CALENDAR_BREAKER_THRESHOLD", circuitbreaker.DefaultFailureThreshold),
+	helper.GetEnvSeconds("GOOGLE_CALENDAR_BREAKER_RESET_SECONDS", circuitbreaker.DefaultResetTimeout),
+	isCalendarOutage,
+)
+
+// Retry policy of the Google Calendar insert made when a meeting is booked
+const (
+	calendarInsertAttempts = 3
+	calendarInsertBackoff  = 500 * time.Millisecond
+)
+
+// isCalendarOutage reports whether err means Google is unavailable rather than a problem with
+// a single integration or request, only those errors count towards opening googleCalendarBreaker.
+func isCalendarOutage(err error) bool {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) {
+		return retrieveErr.Response != nil && retrieveErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// GetCalendarClient helper initializes the Google Calendar client, handling token refresh.
+// Refreshed tokens are written back to the integration through db, which must be the primary.
+func GetCalendarClient(ctx context.Context, db *database.DB, integration model.Integration) (_ *calendar.Service, _ enum.IntegrationAppType, err error) {
+	ctx, span := tracing.Start(ctx, "google.calendar.client", attribute.String("integration.app_type", string(integration.AppType)))
+	defer func() { tracing.End(span, err) }()
+
+	appType := integration.AppType // Get app type from the integration model
+
+	switch appType {
+	case enum.AppGoogleMeetAndCalendar:
+		if !integration.RefreshToken.Valid || integration.RefreshToken.String == "" {
+			// Handle case where refresh token is missing but required
+			return nil, appType, appError.NewAppError(enum.AuthUnauthorizedAccess, "Google integration missing refresh token for offline access.", nil)
+		}
+
+		// Tokens are encrypted at rest
+		accessToken, err := crypto.DecryptString(integration.AccessToken.String)
+		if err != nil {
+			return nil, appType, appError.NewAppError(enum.InternalServerError, "Failed to decrypt Google access token", err)
+		}
+		refreshToken, err := crypto.DecryptString(integration.RefreshToken.String)
+		if err != nil {
+			return nil, appType, appError.NewAppError(enum.InternalServerError, "Failed to decrypt Google refresh token", err)
+		}
+
+		token, err := ValidateGoogleToken(
+			ctx,
+			accessToken,
+			refreshToken,
+			integration.ExpiryDate.Int64, // Pass the int64 value
+		)
+		if err != nil {
+			return nil, appType, appError.NewAppError(enum.AuthInvalidToken, "Failed to validate/refresh Google token", err)
+		}
+
+		// Google may rotate the refresh token, losing it makes the next refresh fail with invalid_grant.
+		// The refreshed token is still used for this client when persisting fails.
+		if token.AccessToken != accessToken || token.RefreshToken != refreshToken {
+			if err := UpdateIntegrationToken(ctx, db, integration.UserID, appType, token); err != nil {
+				log.Printf("Warning: Failed to persist refreshed Google token (IntegrationID: %s): %v\n", integration.ID, err)
+			}
+		}
+
+		// Use global oauth config (initialized in integration service or elsewhere)
+		httpClient := googleOAuthConfig.Client(ctx, token)
+
+		// Create Calendar service
+		calendarSvc, err := calendar.NewService(ctx, option.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, appType, appError.NewAppError(enum.InternalServerError, "Failed to create Google Calendar service client", err)
+		}
+		return calendarSvc, appType, nil
+
+	// case codes.AppOutlookCalendar:
+	// TODO: Implement Outlook Calendar client initialization
+	// return nil, appType, apperror.NewAppError(enums.InternalServerError, "Outlook Calendar not implemented", nil)
+
+	default:
+		msg := fmt.Sprintf("Unsupported calendar provider app type: %s", appType)
+		return nil, appType, appError.NewAppError(enum.BadRequest, msg, nil)
+	}
+}
+
+// CreateGoogleMeetEvent inserts a calendar event with a Google Meet conference into the primary calendar.
+// calendarEventID must stay the same across retries, a 409 then means the event already exists and it is returned.
+func CreateGoogleMeetEvent(ctx context.Context, calendarSvc *calendar.Service, calendarEventID, summary, description string, startTime, endTime time.Time, attendeeEmails ...string) (_ *calendar.Event, err error) {
+	ctx, span := tracing.Start(ctx, "google.calendar.insert", attribute.String("calendar.event_id", calendarEventID))
+	defer func() { tracing.End(span, err) }()
+
+	attendees := make([]*calendar.EventAttendee, 0, len(attendeeEmails))
+	for _, email := range attendeeEmails {
+		attendees = append(attendees, &calendar.EventAttendee{Email: email})
+	}
+
+	// Create Google Calendar event request
+	calEvent := &calendar.Event{
+		Id:          calendarEventID,
+		Summary:     summary,
+		Description: description,
+		Start:       &calendar.EventDateTime{DateTime: startTime.Format(time.RFC3339)},
+		End:         &calendar.EventDateTime{DateTime: endTime.Format(time.RFC3339)},
+		Attendees:   attendees,
+		ConferenceData: &calendar.ConferenceData{
+			CreateRequest: &calendar.CreateConferenceRequest{
+				RequestId:             calendarEventID,                                       // Same ID on retries, so only one conference is created
+				ConferenceSolutionKey: &calendar.ConferenceSolutionKey{Type: "hangoutsMeet"}, // Request Google Meet
+			},
+		},
+	}
+
+	createdCalEvent, err := calendarSvc.Events.Insert("primary", calEvent).ConferenceDataVersion(1).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusConflict {
+		// An earlier attempt already created the event, its response just never reached us
+		createdCalEvent, err = calendarSvc.Events.Get("primary", calendarEventID).Context(ctx).Do()
+	}
+	if err != nil {
+		// Log detailed Google API error if possible
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to create calendar event", err)
+	}
+
+	if createdCalEvent.Id == "" {
+		// Handle case where ID might be missing unexpectedly
+		return nil, appError.NewAppError(enum.InternalServerError, "Created calendar event missing ID", nil)
+	}
+
+	return createdCalEvent, nil
+}
+
+// newCalendarEventID returns a random ID for a Google Calendar event. Hex digits are valid
+// base32hex, the alphabet Google requires. Generated once per booking and reused on retries,
+// so an insert that timed out but went through is found again instead of duplicated.
+func newCalendarEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DeleteGoogleCalendarEvent removes an event from the primary calendar.
+func DeleteGoogleCalendarEvent(ctx context.Context, calendarSvc *calendar.Service, calendarEventID string) (err error) {
+	ctx, span := tracing.Start(ctx, "google.calendar.delete", attribute.String("calendar.event_id", calendarEventID))
+	defer func() { tracing.End(span, err) }()
+
+	return calendarSvc.Events.Delete("primary", calendarEventID).Context(ctx).Do()
+}
+
+// UpdateGoogleCalendarEvent moves an event of the primary calendar to a new slot.
+// Only the start, end and attendees are patched so the event keeps its history and conference link.
+// The existing attendees, the host included, are kept and only the guest's entry is reset to await a new response.
+func UpdateGoogleCalendarEvent(ctx context.Context, calendarSvc *calendar.Service, calendarEventID string, newStart, newEnd time.Time, guestEmail string) (err error) {
+	ctx, span := tracing.Start(ctx, "google.calendar.patch", attribute.String("calendar.event_id", calendarEventID))
+	defer func() { tracing.End(span, err) }()
+
+	existing, err := calendarSvc.Events.Get("primary", calendarEventID).Context(ctx).Do()
+	if err != nil {
+		return err
+	}
+
+	// Patch replaces the attendee list as a whole, so it is rebuilt from the current one
+	attendees := make([]*calendar.EventAttendee, 0, len(existing.Attendees)+1)
+	guestFound := false
+	for _, attendee := range existing.Attendees {
+		if strings.EqualFold(attendee.Email, guestEmail) {
+			guestFound = true
+			attendee.ResponseStatus = "needsAction"
+		}
+		attendees = append(attendees, attendee)
+	}
+	if !guestFound {
+		attendees = append(attendees, &calendar.EventAttendee{Email: guestEmail})
+	}
+
+	patchedEvent := &calendar.Event{
+		Start:     &calendar.EventDateTime{DateTime: newStart.Format(time.RFC3339)},
+		End:       &calendar.EventDateTime{DateTime: newEnd.Format(time.RFC3339)},
+		Attendees: attendees,
+	}
+
+	_, err = calendarSvc.Events.Patch("primary", calendarEventID, patchedEvent).Context(ctx).Do()
+	return err
+}
+
+// isCalendarEventGone reports whether a Calendar API error means the event no longer exists.
+func isCalendarEventGone(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && (apiErr.Code == http.StatusNotFound || apiErr.Code == http.StatusGone)
+}
+
+// wallClockIn returns the instant at which loc shows the same date and clock time as t.
+// Any offset t carries is ignored, so "09:00Z" becomes 09:00 in loc.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// GetZoomClient returns a Zoom API client authorized with the integration's tokens.
+// The access token is refreshed on demand when it has expired.
+func GetZoomClient(ctx context.Context, integration model.Integration) (*zoom.ZoomClient, error) {
+	if integration.AppType != enum.AppZoomMeeting {
+		msg := fmt.Sprintf("Integration %s is not a Zoom integration", integration.ID)
+		return nil, appError.NewAppError(enum.BadRequest, msg, nil)
+	}
+
+	// Tokens are encrypted at rest
+	accessToken, err := crypto.DecryptString(integration.AccessToken.String)
+	if err != nil {
+		return nil, appError.NewAppError(enum.InternalServerError, "Failed to decrypt Zoom access token", err)
+	}
+	token := &oauth2.Token{AccessToken: accessToken}
+	if integration.RefreshToken.Valid && integration.RefreshToken.String != "" {
+		refreshToken, err := crypto.DecryptString(integration.RefreshToken.String)
+		if err != nil {
+			return nil, appError.NewAppError(enum.InternalServerError, "Failed to decrypt Zoom refresh token", err)
+		}
+		token.RefreshToken = refreshToken
+	}
+	if integration.ExpiryDate.Valid {
+		token.Expiry = time.Unix(integration.ExpiryDate.Int64, 0)
+	}
+
+	return zoom.NewClient(zoomOAuthConfig.Client(ctx, token)), nil
+}
+
+// describeIntegrationError turns a provider error into a short reason safe to show to the user.
+func describeIntegrationError(err error) string {
+	var retrieveErr *oauth2.RetrieveError
+	if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+		return "token revoked"
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusUnauthorized:
+			return "token revoked"
+		case http.StatusForbidden:
+			return "insufficient permissions"
+		}
+	}
+
+	return "provider request failed"
+}
+
+// IsSlotAvailable checks if a potential slot conflicts with existing meetings or external busy times.
+func IsSlotAvailable(slotStart, slotEnd time.Time, meetings []model.Meeting, busy []BusyBlock) bool {
+	for _, meeting := range meetings {
+		// Check for overlap: (SlotStart < MeetingEnd) and (SlotEnd > MeetingStart)
+		if slotStart.Before(meeting.EndTime) && slotEnd.After(meeting.StartTime) {
+			return false // Slot overlaps with a meeting
+		}
+	}
+
+	for _, block := range busy {
+		if slotStart.Before(block.End) && slotEnd.After(block.Start) {
+			return false // Slot overlaps with a calendar event outside the app
+		}
+	}
+
+	return true
+}
+
+// GetBusyTimesFromGoogleCalendar returns the busy periods of the integration's primary calendar in [startTime, endTime).
+func GetBusyTimesFromGoogleCalendar(ctx context.Context, db *database.DB, integration model.Integration, startTime, endTime time.Time) (_ []BusyBlock, err error) {
+	ctx, span := tracing.Start(ctx, "google.calendar.freebusy")
+	defer func() { tracing.End(span, err) }()
+
+	calendarSvc, _, err := GetCalendarClient(ctx, db, integration)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &calendar.FreeBusyRequest{
+		TimeMin: startTime.Format(time.RFC3339),
+		TimeMax: endTime.Format(time.RFC3339),
+		Items:   []*calendar.FreeBusyRequestItem{{Id: "primary"}},
+	}
+	resp, err := calendarSvc.Freebusy.Query(request).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query free/busy: %w", err)
+	}
+
+	primary, ok := resp.Calendars["primary"]
+	if !ok {
+		return nil, nil
+	}
+	if len(primary.Errors) > 0 {
+		return nil, fmt.Errorf("free/busy query for primary calendar failed: %s", primary.Errors[0].Reason)
+	}
+
+	blocks := make([]BusyBlock, 0, len(primary.Busy))
+	for _, period := range primary.Busy {
+		start, errStart := time.Parse(time.RFC3339, period.Start)
+		end, errEnd := time.Parse(time.RFC3339, period.End)
+		if errStart != nil || errEnd != nil {
+			log.Printf("Warning: Skipping unparsable busy period %s - %s\n", period.Start, period.End)
+			continue
+		}
+		blocks = append(blocks, BusyBlock{Start: start, End: end})
+	}
+
+	return blocks, nil
+}
+
+func IntegrationAppTypeFromEventLocation(loc enum.EventLocationType) (enum.IntegrationAppType, bool) {
+	switch loc {
+	case enum.LocationGoogleMeetAndCalendar:
+		return enum.AppGoogleMeetAndCalendar, true
+	case enum.LocationZoomMeeting:
+		// Assuming Zoom might relate to a Zoom integration app type
+		return enum.AppZoomMeeting, true
+	default:
+		return "", false
+	}
+}
+
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// RevokeGoogleToken revokes an access or refresh token at Google.
+func RevokeGoogleToken(ctx context.Context, token string) (err error) {
+	ctx, span := tracing.Start(ctx, "google.oauth.revoke")
+	defer func() { tracing.End(span, err) }()
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, googleRevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revoke request failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+const googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+// fetchGoogleUserInfo loads the signed-in Google account's profile using the login token.
+func fetchGoogleUserInfo(ctx context.Context, token *oauth2.Token) (_ googleUserInfo, err error) {
+	ctx, span := tracing.Start(ctx, "google.oauth.userinfo")
+	defer func() { tracing.End(span, err) }()
+
+	client := GetGoogleLoginOAuthConfig().Client(ctx, token)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, googleUserInfoURL, nil)
+	if err != nil {
+		return googleUserInfo{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return googleUserInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return googleUserInfo{}, fmt.Errorf("userinfo request failed with status %d", resp.StatusCode)
+	}
+
+	var info googleUserInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return googleUserInfo{}, fmt.Errorf("failed to decode userinfo: %w", err)
+	}
+	return info, nil
+}
+
+// ValidateGoogleToken checks expiry and refreshes if needed using oauth2 package.
+// The returned token carries the new access token, expiry and refresh token, the latter
+// is the given one unless Google rotated it. A token rather than an access/refresh pair is
+// returned so callers persist the new expiry along with both tokens, see GetCalendarClient.
+func ValidateGoogleToken(ctx context.Context, accessToken, refreshToken string, expiryDateUnix int64) (_ *oauth2.Token, err error) {
+	ctx, span := tracing.Start(ctx, "google.oauth.token")
+	defer func() { tracing.End(span, err) }()
+
+	currentToken := &oauth2.Token{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Expiry:       time.Unix(expiryDateUnix, 0),
+	}
+
+	if refreshToken == "" {
+		// Without a refresh token the current token is all there is, expired or not
+		return currentToken, nil
+	}
+
+	// Token refreshes when the token is expired or close to expiry, and keeps
+	// the old refresh token when the response doesn't include a new one
+	newToken, err := googleOAuthConfig.TokenSource(ctx, currentToken).Token()
+	if err != nil {
+		// Handle refresh errors (e.g., invalid grant)
+		return nil, appError.NewAppError(enum.AuthInvalidToken, "Failed to refresh Google token", err)
+	}
+
+	return newToken, nil
+}
+
+// buildAvailabilityResponse converts the rows of an availability query into an
+// AvailabilityResponse, trimming "HH:MM:SS" times to "HH:MM".
+func buildAvailabilityResponse(dbDetail []AvailabilityDetail) *AvailabilityResponse {
+	availability := &AvailabilityResponse{
+		Days: make([]DayAvailabilityDetail, 0, len(dbDetail)),
+	}
+	if len(dbDetail) > 0 {
+		availability.TimeGap = dbDetail[0].TimeGap // TimeGap is the same for all rows of a user
+	}
+
+	for _, detail := range dbDetail {
+		// Parse HH:MM:SS string from DB into HH:MM
+		startTimeHM, _, _ := strings.Cut(detail.StartTime, ":")     // Get HH
+		startTimeMM, _, _ := strings.Cut(detail.StartTime[3:], ":") // Get MM
+		endTimeHM, _, _ := strings.Cut(detail.EndTime, ":")         // Get HH
+		endTimeMM, _, _ := strings.Cut(detail.EndTime[3:], ":")     // Get MM
+
+		availability.Days = append(availability.Days, DayAvailabilityDetail{
+			Day:         detail.Day,
+			StartTime:   fmt.Sprintf("%s:%s", startTimeHM, startTimeMM), // Format HH:MM
+			EndTime:     fmt.Sprintf("%s:%s", endTimeHM, endTimeMM),     // Format HH:MM
+			IsAvailable: detail.IsAvailable,
+		})
+	}
+
+	return availability
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/jmoiron/sqlx"
+)
+
+// isEventFull reports whether the event has reached its MaxBookings.
+// Only scheduled meetings that haven't ended count against the limit.
+func (m *Controller) isEventFull(ctx context.Context, event model.Event) (bool, error) {
+	if !event.MaxBookings.Valid {
+		return false, nil
+	}
+
+	var active int64
+	query := `SELECT COUNT(*) FROM meetings WHERE event_id = $1 AND status = $2 AND end_time > NOW();`
+	if err := m.db.GetContext(ctx, &active, query, event.ID, enum.Scheduled); err != nil {
+		return false, fmt.Errorf("failed to count bookings of event %s: %w", event.ID, err)
+	}
+	return active >= event.MaxBookings.Int64, nil
+}
+
+// hasCapacityLocked locks the event row until tx ends and reports whether the event still has room.
+// Concurrent bookings of the same event wait for each other here, so MaxBookings can't be exceeded.
+func hasCapacityLocked(ctx context.Context, tx *sqlx.Tx, event model.Event) (bool, error) {
+	if !event.MaxBookings.Valid {
+		return true, nil
+	}
+
+	var eventID string
+	if err := tx.GetContext(ctx, &eventID, `SELECT id FROM events WHERE id = $1 FOR UPDATE;`, event.ID); err != nil {
+		return false, fmt.Errorf("failed to lock event %s: %w", event.ID, err)
+	}
+
+	var active int64
+	query := `SELECT COUNT(*) FROM meetings WHERE event_id = $1 AND status = $2 AND end_time > NOW();`
+	if err := tx.GetContext(ctx, &active, query, event.ID, enum.Scheduled); err != nil {
+		return false, fmt.Errorf("failed to count bookings of event %s: %w", event.ID, err)
+	}
+	return active < event.MaxBookings.Int64, nil
+}
+
+// joinWaitlist queues the guest on the event, a guest already waiting keeps their place.
+func (m *Controller) joinWaitlist(ctx context.Context, eventID string, booking dto.CreateMeetingDto) error {
+	query := `
+		INSERT INTO waitlist (event_id, guest_name, guest_email, additional_info, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (event_id, guest_email) DO NOTHING;
+	`
+	addInfo := sql.NullString{String: booking.AdditionalInfo, Valid: booking.AdditionalInfo != ""}
+	if _, err := m.db.ExecContext(ctx, query, eventID, booking.GuestName, booking.GuestEmail, addInfo); err != nil {
+		return fmt.Errorf("failed to join waitlist of event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// promoteWaitlist emails the first waiting guest once the event has a free spot again (best effort).
+// It runs in the background so the mail server never delays the triggering cancellation.
+func (m *Controller) promoteWaitlist(eventID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		m.promoteNextOnWaitlist(ctx, eventID)
+	}()
+}
+
+// promoteNextOnWaitlist does the work of promoteWaitlist.
+// Guests are notified one at a time, the spot goes to whoever books first.
+func (m *Controller) promoteNextOnWaitlist(ctx context.Context, eventID string) {
+	var event struct {
+		model.Event
+		Username string `db:"username"`
+	}
+	eventQuery := `
+		SELECT e.*, u.username
+		FROM events e
+		JOIN users u ON e.user_id = u.id
+		WHERE e.id = $1 AND e.enable_waitlist = TRUE AND e.deleted_at IS NULL;
+	`
+	if err := m.db.GetContext(ctx, &event, eventQuery, eventID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Warning: Failed to fetch event for waitlist promotion (EventID: %s): %v\n", eventID, err)
+		}
+		return
+	}
+
+	full, err := m.isEventFull(ctx, event.Event)
+	if err != nil {
+		log.Printf("Warning: %v\n", err)
+		return
+	}
+	if full {
+		return
+	}
+
+	// Claim the oldest entry, concurrent cancellations never notify the same guest twice
+	var entry model.WaitlistEntry
+	claimQuery := `
+		UPDATE waitlist
+		SET notified_at = NOW()
+		WHERE id = (
+			SELECT id FROM waitlist
+			WHERE event_id = $1 AND notified_at IS NULL
+			ORDER BY created_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *;
+	`
+	if err := m.db.GetContext(ctx, &entry, claimQuery, eventID); err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Warning: Failed to claim waitlist entry (EventID: %s): %v\n", eventID, err)
+		}
+		return
+	}
+
+	bookingURL := fmt.Sprintf("%s/%s/%s", m.frontendUrl, event.Username, event.Slug)
+	if err := m.mailer.SendWaitlistPromotion(entry.GuestEmail, entry.GuestName, event.Title, bookingURL); err != nil {
+		log.Printf("Warning: Failed to send waitlist promotion (WaitlistID: %s): %v\n", entry.ID, err)
+	}
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/middleware"
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/fazamuttaqien/calendly/pkg/webhook"
+
+	"github.com/lib/pq"
+)
+
+// POST /webhooks
+func (wh *Controller) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		return
+	}
+
+	dto, ok := validator.GetValidatedDTOFromContext[dto.CreateWebhookDto](ctx)
+	if !ok {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Validated DTO not found in context", nil))
+		return
+	}
+
+	// Refuse targets inside our own network, deliveries are checked again when dialing
+	if err := webhook.ValidateURL(ctx, dto.URL); err != nil {
+		appError.WriteError(w, appError.NewValidationError("Webhook URL must be a public https URL", nil))
+		return
+	}
+
+	// Secret used by the receiver to verify the X-Calendly-Signature header
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to generate webhook secret", err))
+		return
+	}
+	secret := "whsec_" + hex.EncodeToString(secretBytes)
+
+	events := make(pq.StringArray, len(dto.Events))
+	for i, event := range dto.Events {
+		events[i] = event.String()
+	}
+
+	var createdWebhook model.Webhook
+	query := `
+		INSERT INTO webhooks (user_id, url, events, secret, is_active, created_at)
+		VALUES ($1, $2, $3, $4, TRUE, NOW())
+		RETURNING id, user_id, url, events, secret, is_active, created_at;
+	`
+	err := wh.db.GetContext(ctx, &createdWebhook, query, userID, dto.URL, events, secret)
+	if err != nil {
+		appError.WriteError(w, appError.NewAppError(enum.InternalServerError, "Failed to register webhook", err))
+		return
+	}
+
+	response := map[string]any{
+		"message": "Webhook registered successfully",
+		"webhook": createdWebhook,
+		"secret":  createdWebhook.Secret, // Only returned once
+	}
+	helper.ResponseJson(w, http.StatusCreated, response)
+}
+
+// dispatchWebhooks delivers event to every active webhook of the user subscribed to it.
+// Delivery runs in the background so it never delays or fails the triggering request.
+func (wh *Controller) dispatchWebhooks(userID string, event enum.WebhookEvent, data any) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		var webhooks []model.Webhook
+		query := `
+			SELECT id, user_id, url, events, secret, is_active, created_at
+			FROM webhooks
+			WHERE user_id = $1 AND is_active = TRUE AND $2 = ANY(events);
+		`
+		if err := wh.db.SelectContext(ctx, &webhooks, query, userID, event); err != nil {
+			log.Printf("Warning: Failed to fetch webhooks (UserID: %s, Event: %s): %v\n", userID, event, err)
+			return
+		}
+
+		payload := webhook.Payload{
+			Event:     event,
+			CreatedAt: time.Now().UTC(),
+			Data:      data,
+		}
+
+		for _, hook := range webhooks {
+			if err := webhook.WebhookDelivery(ctx, hook.URL, hook.Secret, payload); err != nil {
+				log.Printf("Warning: Failed to deliver webhook (WebhookID: %s, Event: %s): %v\n", hook.ID, event, err)
+			}
+		}
+	}()
+}
@@ -1,130 +1,410 @@
-package dto
-
-import (
-	"database/sql"
-	"regexp"
-	"strings"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	"github.com/go-playground/validator/v10"
-)
-
-// --- Auth DTO ---
-
-type RegisterDto struct {
-	Name     string `json:"name" validate:"required"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-}
-
-type LoginDto struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-}
-
-// --- Availability DTO ---
-
-type DayAvailabilityDto struct {
-	Day         enum.DayOfWeek `json:"day" validate:"required,oneof=SUNDAY MONDAY TUESDAY WEDNESDAY THURSDAY FRIDAY SATURDAY"`
-	StartTime   string         `json:"startTime" validate:"required,time_hm"`
-	EndTime     string         `json:"endTime" validate:"required,time_hm"`
-	IsAvailable bool           `json:"isAvailable" validate:"required,boolean"`
-}
-
-type UpdateAvailabilityDto struct {
-	TimeGap int                  `json:"timeGap" validate:"required,gte=0"`
-	Days    []DayAvailabilityDto `json:"days" validate:"required,dive"`
-}
-
-// --- Event DTO ---
-
-type CreateEventDto struct {
-	Title        string                 `json:"title" validate:"required"`
-	Description  string                 `json:"description" validate:"omitempty"`
-	Duration     int                    `json:"duration" validate:"required,gte=1"`
-	LocationType enum.EventLocationType `json:"locationType" validate:"required,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
-}
-
-type UserEventScanDto struct {
-	// User fields (guaranteed non-null if row exists)
-	UserID   string `db:"user_id"`
-	Username string `db:"username"`
-
-	// Event fields (nullable due to LEFT JOIN)
-	EventID           sql.NullString `db:"event_id"`
-	EventTitle        sql.NullString `db:"event_title"`
-	EventDescription  sql.NullString `db:"event_description"`
-	EventDuration     sql.NullInt64  `db:"event_duration"`
-	EventSlug         sql.NullString `db:"event_slug"`
-	EventIsPrivate    sql.NullBool   `db:"event_is_private"`
-	EventLocationType sql.NullString `db:"event_location_type"`
-	EventCreatedAt    sql.NullTime   `db:"event_created_at"`
-	EventUpdatedAt    sql.NullTime   `db:"event_updated_at"`
-}
-
-// Note: For 'oneof', list the *string* values of the enum constants.
-
-// --- Param/Query DTO (Often used for URL parameters or query strings) ---
-
-// EventIdDto is typically used for path parameters like /events/{eventId}
-type EventIdDto struct {
-	EventID string `param:"eventId" validate:"required,uuid4"`
-}
-
-// UserNameDto is typically used for path parameters like /users/{username}
-type UserNameDto struct {
-	Username string `param:"username" validate:"required"`
-}
-
-// UserNameAndSlugDto is typically used for path parameters like /users/{username}/events/{slug}
-type UserNameAndSlugDto struct {
-	Username string `param:"username" validate:"required"`
-	Slug     string `param:"slug" validate:"required"`
-}
-
-// AppTypeDTO could be used for query parameters like /integrations?appType=...
-type AppTypeDTO struct {
-	AppType enum.IntegrationAppType `query:"appType" validate:"required,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING OUTLOOK_CALENDAR"`
-}
-
-// --- Meeting DTO ---
-
-// Note on IsDateString: Go validator common use is RFC3339 format.
-// Use 'datetime=YYYY-MM-DDTHH:mm:ssZ07:00' or a specific layout.
-// Often, you validate it's a string, then parse to time.Time in the handler.
-// Here, we'll use a basic datetime validation. Adjust format as needed.
-const rfc3339Full = "2006-01-02T15:04:05Z07:00"
-
-type CreateMeetingDto struct {
-	EventID        string    `json:"eventId" validate:"required,uuid4"`
-	StartTime      time.Time `json:"startTime" validate:"required"`
-	EndTime        time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
-	GuestName      string    `json:"guestName" validate:"required"`
-	GuestEmail     string    `json:"guestEmail" validate:"required,email"`
-	AdditionalInfo string    `json:"additionalInfo" validate:"omitempty"`
-}
-
-// MeetingIdDto is typically used for path parameters like /meetings/{meetingId}
-type MeetingIdDto struct {
-	MeetingID string `param:"meetingId" validate:"required,uuid4"`
-}
-
-// --- Helper to add custom time validation ---
-// You would register this with your validator instance
-
-var timeRegex = regexp.MustCompile(`^([01]\d|2[0-3]):([0-5]\d)$`)
-
-func ValidateTimeHM(fl validator.FieldLevel) bool {
-	return timeRegex.MatchString(fl.Field().String())
-}
-
-// In your init() where you create the validator:
-// validate.RegisterValidation("time_hm", ValidateTimeHM)
-
-// --- Helper to join enum values for 'oneof' tag ---
-// (Could be generated or put in a utility package)
-// Usage example in tags: validate:"required,oneof="+joinEnumValues(codes.IntegrationAppTypeValues())
-func joinEnumValues(values []string) string {
-	return strings.Join(values, " ")
-}
+package dto
+
+import (
+	"database/sql"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	appValidator "github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/go-playground/validator/v10"
+)
+
+// --- Auth DTO ---
+
+type RegisterDto struct {
+	Name     string `json:"name" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type LoginDto struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type ChangePasswordDto struct {
+	CurrentPassword string `json:"currentPassword" validate:"omitempty,min=6"` // Optional after a recent Google sign-in
+	NewPassword     string `json:"newPassword" validate:"required,min=6"`
+}
+
+// UpdateSettingsDto uses pointers so omitted settings are left unchanged
+type UpdateSettingsDto struct {
+	Timezone     *string `json:"timezone" validate:"omitempty,timezone"`
+	DigestOptOut *bool   `json:"digestOptOut"`
+}
+
+type DeleteAccountDto struct {
+	Password string `json:"password"` // Optional after a recent Google sign-in
+}
+
+// VerifyEmailDto is used for query parameters like /auth/verify-email?token=...
+type VerifyEmailDto struct {
+	Token string `query:"token" validate:"required"`
+}
+
+// ResendVerificationDto is used for /auth/resend-verification
+type ResendVerificationDto struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// --- Availability DTO ---
+
+type DayAvailabilityDto struct {
+	Day         enum.DayOfWeek `json:"day" validate:"required,oneof=SUNDAY MONDAY TUESDAY WEDNESDAY THURSDAY FRIDAY SATURDAY"`
+	StartTime   string         `json:"startTime" validate:"required,time_hm"`
+	EndTime     string         `json:"endTime" validate:"required,time_hm"`
+	IsAvailable bool           `json:"isAvailable" validate:"required,boolean"`
+}
+
+type UpdateAvailabilityDto struct {
+	TimeGap int                  `json:"timeGap" validate:"required,gte=0"`
+	Days    []DayAvailabilityDto `json:"days" validate:"required,dive"`
+}
+
+// UpdateTimeGapDto uses a pointer so an explicit 0 passes the required check.
+type UpdateTimeGapDto struct {
+	TimeGap *int `json:"timeGap" validate:"required,gte=0,lte=120"`
+}
+
+// DayParamDto is used for path parameters like /availability/day/{day}
+type DayParamDto struct {
+	Day enum.DayOfWeek `param:"day" validate:"required,oneof=SUNDAY MONDAY TUESDAY WEDNESDAY THURSDAY FRIDAY SATURDAY"`
+}
+
+// UpdateDayAvailabilityDto uses a pointer so an explicit false passes the required check.
+type UpdateDayAvailabilityDto struct {
+	StartTime   string `json:"startTime" validate:"required,time_hm"`
+	EndTime     string `json:"endTime" validate:"required,time_hm"`
+	IsAvailable *bool  `json:"isAvailable" validate:"required"`
+}
+
+type CreateAvailabilityExceptionDto struct {
+	Date        string `json:"date" validate:"required,datetime=2006-01-02"`
+	Reason      string `json:"reason" validate:"omitempty,max=255"`
+	IsAvailable bool   `json:"isAvailable"`
+}
+
+type CreateVacationBlockDto struct {
+	StartDate string `json:"startDate" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"endDate" validate:"required,datetime=2006-01-02"`
+	Reason    string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// --- Event DTO ---
+
+type CreateEventDto struct {
+	Title       string `json:"title" validate:"required"`
+	Description string `json:"description" validate:"omitempty"`
+	Duration    int    `json:"duration" validate:"required,gte=1"`
+	// LocationTypes are offered to the guest, who picks one when booking
+	LocationTypes []enum.EventLocationType `json:"locationTypes" validate:"required,min=1,max=3,unique,dive,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+	Questions     []EventQuestionDto       `json:"questions" validate:"omitempty,dive"`
+	Color         enum.EventColor          `json:"color" validate:"omitempty,oneof=RED ORANGE YELLOW GREEN BLUE PURPLE PINK GRAY"`
+	// MaxBookings limits the upcoming meetings of the event, unlimited when omitted
+	MaxBookings    *int `json:"maxBookings" validate:"omitempty,gte=1"`
+	EnableWaitlist bool `json:"enableWaitlist" validate:"excluded_without=MaxBookings"`
+	// Advance booking window, the column defaults (0 hours, 60 days) apply when omitted
+	MinNoticeHours *int `json:"minNoticeHours" validate:"omitempty,gte=0,lte=8760"`
+	MaxNoticeDays  *int `json:"maxNoticeDays" validate:"omitempty,gte=1,lte=365"`
+	// Slug is used as-is instead of one generated from the title when set
+	Slug string `json:"slug" validate:"omitempty,lowercase,alphanum_dash,max=80"`
+}
+
+type UpdateEventDto struct {
+	Title         string                   `json:"title" validate:"required"`
+	Description   string                   `json:"description" validate:"omitempty"`
+	Duration      int                      `json:"duration" validate:"required,gte=1"`
+	LocationTypes []enum.EventLocationType `json:"locationTypes" validate:"required,min=1,max=3,unique,dive,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+	Color         enum.EventColor          `json:"color" validate:"omitempty,oneof=RED ORANGE YELLOW GREEN BLUE PURPLE PINK GRAY"` // Left unchanged when omitted
+	// Questions replaces the whole set of booking questions of the event
+	Questions []EventQuestionDto `json:"questions" validate:"omitempty,dive"`
+	// Advance booking window, left unchanged when omitted
+	MinNoticeHours *int `json:"minNoticeHours" validate:"omitempty,gte=0,lte=8760"`
+	MaxNoticeDays  *int `json:"maxNoticeDays" validate:"omitempty,gte=1,lte=365"`
+}
+
+type CloneAvailabilityDto struct {
+	// SourceEventID is the event whose owner's availability is copied
+	SourceEventID string `json:"sourceEventId" validate:"omitempty,uuid4"`
+}
+
+type EventQuestionDto struct {
+	// ID of an existing question to update, questions without one are created
+	ID         string                 `json:"id" validate:"omitempty,uuid4"`
+	Label      string                 `json:"label" validate:"required,max=255"`
+	FieldType  enum.QuestionFieldType `json:"fieldType" validate:"required,oneof=text select checkbox"`
+	Options    []string               `json:"options" validate:"required_if=FieldType select,dive,required"`
+	IsRequired bool                   `json:"isRequired"`
+	SortOrder  int                    `json:"sortOrder" validate:"gte=0"`
+}
+
+type UserEventScanDto struct {
+	// User fields (guaranteed non-null if row exists)
+	UserID   string `db:"user_id"`
+	Username string `db:"username"`
+
+	// Event fields (nullable due to LEFT JOIN)
+	EventID            sql.NullString          `db:"event_id"`
+	EventTitle         sql.NullString          `db:"event_title"`
+	EventDescription   sql.NullString          `db:"event_description"`
+	EventDuration      sql.NullInt64           `db:"event_duration"`
+	EventSlug          sql.NullString          `db:"event_slug"`
+	EventIsPrivate     sql.NullBool            `db:"event_is_private"`
+	EventLocationTypes enum.EventLocationTypes `db:"event_location_types"`
+	EventColor         sql.NullString          `db:"event_color"`
+	EventCreatedAt     sql.NullTime            `db:"event_created_at"`
+	EventUpdatedAt     sql.NullTime            `db:"event_updated_at"`
+}
+
+// Note: For 'oneof', list the *string* values of the enum constants.
+
+// --- Param/Query DTO (Often used for URL parameters or query strings) ---
+
+// EventIdDto is typically used for path parameters like /events/{eventId}
+type EventIdDto struct {
+	EventID string `param:"eventId" validate:"required,uuid4"`
+}
+
+// UserNameDto is typically used for path parameters like /users/{username}
+type UserNameDto struct {
+	Username string `param:"username" validate:"required"`
+}
+
+// UserNameAndSlugDto is typically used for path parameters like /users/{username}/events/{slug}
+type UserNameAndSlugDto struct {
+	Username string `param:"username" validate:"required"`
+	Slug     string `param:"slug" validate:"required"`
+}
+
+// EventListQueryDto is used for query parameters like /event?sort_by=title&sort_order=asc&q=...&cursor=...
+type EventListQueryDto struct {
+	SortBy       string                 `query:"sort_by" validate:"omitempty,oneof=created_at title duration meeting_count"`
+	SortOrder    string                 `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	LocationType enum.EventLocationType `query:"location_type" validate:"omitempty,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+	Q            string                 `query:"q" validate:"omitempty,max=100"`
+	Cursor       string                 `query:"cursor" validate:"omitempty,max=512"` // Only valid with created_at ordering
+	Limit        int                    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// EventSearchQueryDto is used for query parameters like /event/search?q=...&durationMax=60
+type EventSearchQueryDto struct {
+	Q                string                 `query:"q" validate:"omitempty,max=100"`
+	LocationTypeCode enum.EventLocationType `query:"locationTypeCode" validate:"omitempty,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+	DurationMin      int                    `query:"durationMin" validate:"omitempty,gte=1"`
+	DurationMax      int                    `query:"durationMax" validate:"omitempty,gte=1,gtefield=DurationMin"`
+	Limit            int                    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset           int                    `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// PublicEventListQueryDto is used for query parameters like /event/public?page=2&locationType=ZOOM_MEETING
+type PublicEventListQueryDto struct {
+	Q            string                 `query:"q" validate:"omitempty,max=100"`
+	LocationType enum.EventLocationType `query:"locationType" validate:"omitempty,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+	DurationMin  int                    `query:"durationMin" validate:"omitempty,gte=1"`
+	DurationMax  int                    `query:"durationMax" validate:"omitempty,gte=1,gtefield=DurationMin"`
+	Page         int                    `query:"page" validate:"omitempty,gte=1"`
+	Limit        int                    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// PublicEventListItem is a single public event returned by /event/public, with its owner.
+type PublicEventListItem struct {
+	Title         string                  `db:"title" json:"title"`
+	Slug          string                  `db:"slug" json:"slug"`
+	Description   string                  `db:"description" json:"description"`
+	Duration      int64                   `db:"duration" json:"duration"`
+	LocationTypes enum.EventLocationTypes `db:"location_types" json:"locationTypes"`
+	Color         enum.EventColor         `db:"color" json:"color"`
+	CreatedAt     time.Time               `db:"created_at" json:"createdAt"`
+	Username      string                  `db:"username" json:"username"`
+	Name          string                  `db:"name" json:"name"`
+}
+
+// PublicEventSearchResult is a single public event returned by /event/search.
+type PublicEventSearchResult struct {
+	Slug          string                  `db:"slug" json:"slug"`
+	Username      string                  `db:"username" json:"username"`
+	Name          string                  `db:"name" json:"name"`
+	Title         string                  `db:"title" json:"title"`
+	Duration      int64                   `db:"duration" json:"duration"`
+	LocationTypes enum.EventLocationTypes `db:"location_types" json:"locationTypes"`
+	Color         enum.EventColor         `db:"color" json:"color"`
+	MeetingCount  int                     `db:"meeting_count" json:"meetingCount"`
+}
+
+// AvailabilityRangeDto is used for query parameters like /availability/public/{eventId}?startDate=...&endDate=...
+type AvailabilityRangeDto struct {
+	StartDate string `query:"startDate" validate:"omitempty,datetime=2006-01-02"`
+	EndDate   string `query:"endDate" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// AppTypeDTO could be used for query parameters like /integrations?appType=...
+type AppTypeDTO struct {
+	AppType enum.IntegrationAppType `query:"appType" validate:"required,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING OUTLOOK_CALENDAR"`
+}
+
+// --- Meeting DTO ---
+
+// Note on IsDateString: Go validator common use is RFC3339 format.
+// Use 'datetime=YYYY-MM-DDTHH:mm:ssZ07:00' or a specific layout.
+// Often, you validate it's a string, then parse to time.Time in the handler.
+// Here, we'll use a basic datetime validation. Adjust format as needed.
+const rfc3339Full = "2006-01-02T15:04:05Z07:00"
+
+type CreateMeetingDto struct {
+	EventID string `json:"eventId" validate:"required_without=RoundRobinEventID,omitempty,uuid4"`
+	// RoundRobinEventID books the event of a round-robin template with the next free host
+	RoundRobinEventID string             `json:"roundRobinEventId" validate:"omitempty,uuid4"`
+	StartTime         time.Time          `json:"startTime" validate:"required"`
+	EndTime           time.Time          `json:"endTime" validate:"required,gtfield=StartTime"`
+	GuestName         string             `json:"guestName" validate:"required"`
+	GuestEmail        string             `json:"guestEmail" validate:"required,email"`
+	AdditionalInfo    string             `json:"additionalInfo" validate:"omitempty"`
+	Answers           []BookingAnswerDto `json:"answers" validate:"omitempty,dive"`
+	// Timezone is the guest's IANA timezone, StartTime and EndTime are wall-clock times in it
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`
+	// SelectedLocationType is one of the event's location types, optional when the event offers only one
+	SelectedLocationType enum.EventLocationType `json:"selectedLocationType" validate:"omitempty,oneof=GOOGLE_MEET_AND_CALENDAR ZOOM_MEETING"`
+}
+
+type BookingAnswerDto struct {
+	QuestionID string `json:"questionId" validate:"required,uuid4"`
+	Answer     string `json:"answer" validate:"max=2000"`
+}
+
+type RescheduleMeetingDto struct {
+	StartTime time.Time `json:"startTime" validate:"required"`
+	EndTime   time.Time `json:"endTime" validate:"required,gtfield=StartTime"`
+}
+
+// UpdateMeetingNotesDto replaces the host's notes of a meeting, empty clears them.
+type UpdateMeetingNotesDto struct {
+	Notes string `json:"notes" validate:"omitempty,max=5000"`
+}
+
+// MeetingListQueryDto is used for query parameters like /meeting?filter=PAST&limit=20&offset=40
+type MeetingListQueryDto struct {
+	Filter string `query:"filter"` // Unknown values fall back to UPCOMING
+	Limit  int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int    `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// EventMeetingListQueryDto is used for query parameters like /events/{eventId}/meetings?status=CANCELLED&limit=20
+type EventMeetingListQueryDto struct {
+	Status enum.MeetingStatus `query:"status" validate:"omitempty,oneof=SCHEDULED CANCELLED NO_SHOW"`
+	Limit  int                `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int                `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// IntegrationListQueryDto is used for query parameters like /integration?limit=10&offset=10
+type IntegrationListQueryDto struct {
+	Limit  int `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset int `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// MeetingSearchQueryDto is used for query parameters like /meeting/search?guestEmail=...&from=...
+type MeetingSearchQueryDto struct {
+	GuestEmail string    `query:"guestEmail" validate:"omitempty,max=255"`
+	GuestName  string    `query:"guestName" validate:"omitempty,max=255"`
+	EventID    string    `query:"eventId" validate:"omitempty,uuid4"`
+	From       time.Time `query:"from"` // RFC 3339, parsed by the query binder
+	To         time.Time `query:"to"`   // RFC 3339, parsed by the query binder
+	Limit      int       `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset     int       `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// MeetingIdDto is typically used for path parameters like /meetings/{meetingId}
+type MeetingIdDto struct {
+	MeetingID string `param:"meetingId" validate:"required,uuid4"`
+}
+
+// CancelByTokenDto is used for guest self-cancellation like /meeting/cancel?token=...
+type CancelByTokenDto struct {
+	Token string `query:"token" validate:"required,uuid4"`
+}
+
+// --- Webhook DTO ---
+
+type CreateWebhookDto struct {
+	URL    string              `json:"url" validate:"required,url"`
+	Events []enum.WebhookEvent `json:"events" validate:"required,min=1,dive,oneof=meeting.created meeting.cancelled meeting.rescheduled"`
+}
+
+// SaveSlackWebhookDto sets up Slack notifications, both kinds are on when omitted.
+type SaveSlackWebhookDto struct {
+	WebhookURL     string `json:"webhookUrl" validate:"required,url,startswith=https://hooks.slack.com/"`
+	NotifyOnCreate *bool  `json:"notifyOnCreate"`
+	NotifyOnCancel *bool  `json:"notifyOnCancel"`
+}
+
+// --- Organization DTO ---
+
+type CreateOrganizationDto struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Slug string `json:"slug" validate:"omitempty,lowercase,alphanum_dash,max=80"` // Derived from the name when omitted
+}
+
+// OrgSlugDto is used for path parameters like /orgs/{orgSlug}
+type OrgSlugDto struct {
+	OrgSlug string `param:"orgSlug" validate:"required,max=80"`
+}
+
+type InviteOrganizationMemberDto struct {
+	Email string       `json:"email" validate:"required,email"`
+	Role  enum.OrgRole `json:"role" validate:"omitempty,oneof=admin member"` // Defaults to member
+}
+
+// AcceptOrganizationInviteDto is used for query parameters like /orgs/invitations/accept?token=...
+type AcceptOrganizationInviteDto struct {
+	Token string `query:"token" validate:"required,uuid4"`
+}
+
+type CreateRoundRobinEventDto struct {
+	EventID string   `json:"eventId" validate:"required,uuid4"`
+	HostIDs []string `json:"hostIds" validate:"required,min=1,max=50,unique,dive,uuid4"`
+}
+
+// --- Admin DTO ---
+
+// AdminUserListQueryDto is used for query parameters like /admin/users?q=...&limit=20&offset=40
+type AdminUserListQueryDto struct {
+	Q         string `query:"q" validate:"omitempty,max=100"`
+	SortBy    string `query:"sort_by" validate:"omitempty,oneof=created_at name email username"`
+	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Limit     int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+	Offset    int    `query:"offset" validate:"omitempty,gte=0"`
+}
+
+type UpdateUserRoleDto struct {
+	Role enum.UserRole `json:"role" validate:"required,oneof=user admin"`
+}
+
+// --- Audit DTO ---
+
+// AuditLogQueryDto is used for query parameters like /admin/audit-logs?userId=...&page=...
+type AuditLogQueryDto struct {
+	UserID string `query:"userId" validate:"omitempty,uuid4"`
+	Action string `query:"action" validate:"omitempty,max=100"`
+	Page   int    `query:"page" validate:"omitempty,gte=1"`
+	Limit  int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// --- Helper to add custom time validation ---
+
+var timeRegex = regexp.MustCompile(`^([01]\d|2[0-3]):([0-5]\d)$`)
+
+func ValidateTimeHM(fl validator.FieldLevel) bool {
+	return timeRegex.MatchString(fl.Field().String())
+}
+
+func init() {
+	appValidator.Validate.RegisterValidation("time_hm", ValidateTimeHM)
+}
+
+// --- Helper to join enum values for 'oneof' tag ---
+// (Could be generated or put in a utility package)
+// Usage example in tags: validate:"required,oneof="+joinEnumValues(codes.IntegrationAppTypeValues())
+func joinEnumValues(values []string) string {
+	return strings.Join(values, " ")
+}
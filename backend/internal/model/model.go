@@ -1,95 +1,246 @@
-package model
-
-import (
-	"database/sql"
-	"encoding/json"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-)
-
-type User struct {
-	ID        string         `db:"id" json:"id"`
-	Name      string         `db:"name" json:"name"`
-	Username  string         `db:"username" json:"username"`
-	Email     string         `db:"email" json:"email"`
-	Password  string         `db:"password" json:"-"`
-	ImageURL  sql.NullString `db:"image_url" json:"imageUrl"`
-	CreatedAt time.Time      `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time      `db:"updated_at" json:"updatedAt"`
-}
-
-type Availability struct {
-	ID        string    `db:"id" json:"id"`
-	UserID    string    `db:"user_id" json:"userId"`
-	TimeGap   int       `db:"time_gap" json:"timeGap"`
-	CreatedAt time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
-}
-
-type DayAvailability struct {
-	ID             string         `db:"id" json:"id"`
-	AvailabilityID string         `db:"availability_id" json:"availabilityId"`
-	Day            enum.DayOfWeek `db:"day" json:"day"`
-	StartTime      string         `db:"start_time" json:"startTime"`
-	EndTime        string         `db:"end_time" json:"endTime"`
-	IsAvailable    bool           `db:"is_available" json:"isAvailable"`
-	CreatedAt      time.Time      `db:"created_at" json:"createdAt"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updatedAt"`
-}
-
-type Event struct {
-	ID           string                 `db:"id" json:"id"`
-	UserID       string                 `db:"user_id" json:"userId"`
-	Title        string                 `db:"title" json:"title"`
-	Description  string                 `db:"description" json:"description"`
-	Duration     int64                  `db:"duration" json:"duration"`
-	Slug         string                 `db:"slug" json:"slug"`
-	IsPrivate    bool                   `db:"is_private" json:"isPrivate"`
-	LocationType enum.EventLocationType `db:"location_type" json:"locationType"`
-	CreatedAt    time.Time              `db:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time              `db:"updated_at" json:"updatedAt"`
-}
-
-// Integration represents the 'integrations' table.
-type Integration struct {
-	ID           string                   `db:"id" json:"id"`
-	UserID       string                   `db:"user_id" json:"userId"` // Foreign key
-	Provider     enum.IntegrationProvider `db:"provider" json:"provider"`
-	Category     enum.IntegrationCategory `db:"category" json:"category"`
-	AppType      enum.IntegrationAppType  `db:"app_type" json:"appType"`
-	AccessToken  sql.NullString           `db:"access_token" json:"-"`              // Often sensitive, exclude from default JSON
-	RefreshToken sql.NullString           `db:"refresh_token" json:"-"`             // Often sensitive, exclude from default JSON
-	ExpiryDate   sql.NullInt64            `db:"expiry_date" json:"-"`               // Exclude expiry details from default JSON
-	Metadata     json.RawMessage          `db:"metadata" json:"metadata,omitempty"` // Use json.RawMessage for flexibility with JSONB
-	IsConnected  bool                     `db:"is_connected" json:"isConnected"`
-	CreatedAt    time.Time                `db:"created_at" json:"createdAt"`
-	UpdatedAt    time.Time                `db:"updated_at" json:"updatedAt"`
-	User         User                     `db:"user" json:"-"` // Example: Add if frequently needed via JOIN, exclude from JSON
-}
-
-// Meeting represents the 'meetings' table.
-type Meeting struct {
-	ID              string             `db:"id" json:"id"`
-	UserID          string             `db:"user_id" json:"userId"` // User who owns the event slot
-	EventID         string             `db:"event_id" json:"eventId"`
-	GuestName       string             `db:"guest_name" json:"guestName"`
-	GuestEmail      string             `db:"guest_email" json:"guestEmail"`
-	AdditionalInfo  string             `db:"additional_info" json:"additionalInfo,omitempty"`
-	StartTime       time.Time          `db:"start_time" json:"startTime"`
-	EndTime         time.Time          `db:"end_time" json:"endTime"`
-	MeetLink        string             `db:"meet_link" json:"meetLink"`                // Assuming not nullable based on SQL
-	CalendarEventID string             `db:"calendar_event_id" json:"calendarEventId"` // Assuming not nullable
-	CalendarAppType string             `db:"calendar_app_type" json:"calendarAppType"` // Assuming not nullable
-	Status          enum.MeetingStatus `db:"status" json:"status"`
-	CreatedAt       time.Time          `db:"created_at" json:"createdAt"`
-	UpdatedAt       time.Time          `db:"updated_at" json:"updatedAt"`
-	// Event           Event               `db:"event" json:"event"` // Example: Add if frequently needed via JOIN, exclude from JSON
-
-	// --- Example fields if joining Event data often ---
-	// These require specific SELECT aliases (e.g., "e.title AS event_title")
-
-	EventTitle        string                 `db:"event_title" json:"eventTitle,omitempty"`
-	EventDescription  string                 `db:"event_description" json:"eventDescription,omitempty"`
-	EventLocationType enum.EventLocationType `db:"event_location_type" json:"eventLocationType,omitempty"` // Needs alias
-}
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/lib/pq"
+)
+
+type User struct {
+	ID         string         `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Username   string         `db:"username" json:"username"`
+	Email      string         `db:"email" json:"email"`
+	Password   string         `db:"password" json:"-"`
+	ImageURL   sql.NullString `db:"image_url" json:"imageUrl"`
+	IsVerified bool           `db:"is_verified" json:"isVerified"`
+	Role       enum.UserRole  `db:"role" json:"role"`
+	GoogleID   sql.NullString `db:"google_id" json:"-"` // Google "sub" identifier, set after signing in with Google
+	// OrganizationID is the organization the user schedules under, NULL for single users
+	OrganizationID sql.NullString `db:"organization_id" json:"organizationId"`
+	// Timezone is the IANA timezone the daily digest is sent in
+	Timezone     string    `db:"timezone" json:"timezone"`
+	DigestOptOut bool      `db:"digest_opt_out" json:"digestOptOut"`
+	CreatedAt    time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type Availability struct {
+	ID        string    `db:"id" json:"id"`
+	UserID    string    `db:"user_id" json:"userId"`
+	TimeGap   int       `db:"time_gap" json:"timeGap"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+type DayAvailability struct {
+	ID             string         `db:"id" json:"id"`
+	AvailabilityID string         `db:"availability_id" json:"availabilityId"`
+	Day            enum.DayOfWeek `db:"day" json:"day"`
+	StartTime      string         `db:"start_time" json:"startTime"`
+	EndTime        string         `db:"end_time" json:"endTime"`
+	IsAvailable    bool           `db:"is_available" json:"isAvailable"`
+	CreatedAt      time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// AvailabilityException overrides the weekly rules for a single date.
+type AvailabilityException struct {
+	ID             string         `db:"id" json:"id"`
+	AvailabilityID string         `db:"availability_id" json:"availabilityId"`
+	ExceptionDate  time.Time      `db:"exception_date" json:"exceptionDate"`
+	Reason         sql.NullString `db:"reason" json:"reason"`
+	IsAvailable    bool           `db:"is_available" json:"isAvailable"` // FALSE blocks the date, TRUE opens it
+	CreatedAt      time.Time      `db:"created_at" json:"createdAt"`
+}
+
+// VacationBlock marks an inclusive date range where the host takes no bookings.
+type VacationBlock struct {
+	ID        string         `db:"id" json:"id"`
+	UserID    string         `db:"user_id" json:"userId"`
+	StartDate time.Time      `db:"start_date" json:"startDate"`
+	EndDate   time.Time      `db:"end_date" json:"endDate"`
+	Reason    sql.NullString `db:"reason" json:"reason"`
+	CreatedAt time.Time      `db:"created_at" json:"createdAt"`
+}
+
+type Event struct {
+	ID            string                  `db:"id" json:"id"`
+	UserID        string                  `db:"user_id" json:"userId"`
+	Title         string                  `db:"title" json:"title"`
+	Description   string                  `db:"description" json:"description"`
+	Duration      int64                   `db:"duration" json:"duration"`
+	Slug          string                  `db:"slug" json:"slug"`
+	SlugAliases   pq.StringArray          `db:"slug_aliases" json:"slugAliases,omitempty"` // Previous slugs, still resolved by public links
+	IsPrivate     bool                    `db:"is_private" json:"isPrivate"`
+	LocationTypes enum.EventLocationTypes `db:"location_types" json:"locationTypes"` // Guests pick one when booking
+	Color         enum.EventColor         `db:"color" json:"color"`                  // Label shown on dashboards
+	CreatedAt     time.Time               `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time               `db:"updated_at" json:"updatedAt"`
+	DeletedAt     *time.Time              `db:"deleted_at" json:"deletedAt,omitempty"` // Set when the event is archived (soft deleted)
+	// MaxBookings caps the scheduled meetings that haven't ended yet, NULL means unlimited
+	MaxBookings    sql.NullInt64 `db:"max_bookings" json:"maxBookings"`
+	EnableWaitlist bool          `db:"enable_waitlist" json:"enableWaitlist"` // Queue guests once MaxBookings is reached
+	// Bookings must start at least MinNoticeHours and at most MaxNoticeDays from now
+	MinNoticeHours int `db:"min_notice_hours" json:"minNoticeHours"`
+	MaxNoticeDays  int `db:"max_notice_days" json:"maxNoticeDays"`
+}
+
+// WaitlistEntry is a guest waiting for a spot on a fully booked event.
+type WaitlistEntry struct {
+	ID             string         `db:"id" json:"id"`
+	EventID        string         `db:"event_id" json:"eventId"`
+	GuestName      string         `db:"guest_name" json:"guestName"`
+	GuestEmail     string         `db:"guest_email" json:"guestEmail"`
+	AdditionalInfo sql.NullString `db:"additional_info" json:"additionalInfo"`
+	CreatedAt      time.Time      `db:"created_at" json:"createdAt"`
+	NotifiedAt     *time.Time     `db:"notified_at" json:"notifiedAt,omitempty"` // Set once the guest was told a spot opened
+}
+
+// EventQuestion represents the 'event_questions' table.
+type EventQuestion struct {
+	ID         string                 `db:"id" json:"id"`
+	EventID    string                 `db:"event_id" json:"eventId"`
+	Label      string                 `db:"label" json:"label"`
+	FieldType  enum.QuestionFieldType `db:"field_type" json:"fieldType"`
+	Options    json.RawMessage        `db:"options" json:"options,omitempty"` // JSONB array of choices for 'select'
+	IsRequired bool                   `db:"is_required" json:"isRequired"`
+	SortOrder  int                    `db:"sort_order" json:"sortOrder"`
+}
+
+// BookingAnswer represents the 'booking_answers' table.
+type BookingAnswer struct {
+	MeetingID  string `db:"meeting_id" json:"meetingId"`
+	QuestionID string `db:"question_id" json:"questionId"`
+	Answer     string `db:"answer" json:"answer"`
+}
+
+// Integration represents the 'integrations' table.
+type Integration struct {
+	ID           string                   `db:"id" json:"id"`
+	UserID       string                   `db:"user_id" json:"userId"` // Foreign key
+	Provider     enum.IntegrationProvider `db:"provider" json:"provider"`
+	Category     enum.IntegrationCategory `db:"category" json:"category"`
+	AppType      enum.IntegrationAppType  `db:"app_type" json:"appType"`
+	AccessToken  sql.NullString           `db:"access_token" json:"-"`              // Often sensitive, exclude from default JSON
+	RefreshToken sql.NullString           `db:"refresh_token" json:"-"`             // Often sensitive, exclude from default JSON
+	ExpiryDate   sql.NullInt64            `db:"expiry_date" json:"-"`               // Exclude expiry details from default JSON
+	Metadata     json.RawMessage          `db:"metadata" json:"metadata,omitempty"` // Use json.RawMessage for flexibility with JSONB
+	IsConnected  bool                     `db:"is_connected" json:"isConnected"`
+	CreatedAt    time.Time                `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time                `db:"updated_at" json:"updatedAt"`
+	User         User                     `db:"user" json:"-"` // Example: Add if frequently needed via JOIN, exclude from JSON
+}
+
+// Meeting represents the 'meetings' table.
+type Meeting struct {
+	ID              string             `db:"id" json:"id"`
+	UserID          string             `db:"user_id" json:"userId"` // User who owns the event slot
+	EventID         string             `db:"event_id" json:"eventId"`
+	GuestName       string             `db:"guest_name" json:"guestName"`
+	GuestEmail      string             `db:"guest_email" json:"guestEmail"`
+	AdditionalInfo  string             `db:"additional_info" json:"additionalInfo,omitempty"`
+	StartTime       time.Time          `db:"start_time" json:"startTime"`
+	EndTime         time.Time          `db:"end_time" json:"endTime"`
+	MeetLink        string             `db:"meet_link" json:"meetLink"`                // Assuming not nullable based on SQL
+	CalendarEventID string             `db:"calendar_event_id" json:"calendarEventId"` // Assuming not nullable
+	CalendarAppType string             `db:"calendar_app_type" json:"calendarAppType"` // Assuming not nullable
+	Status          enum.MeetingStatus `db:"status" json:"status"`
+	Timezone        string             `db:"timezone" json:"timezone"` // IANA timezone the guest booked in, for display
+	// Notes are the host's internal notes, cleared before a meeting is shown to the guest
+	Notes sql.NullString `db:"notes" json:"notes"`
+	// CancellationToken lets the guest cancel without an account, only exposed on booking
+	CancellationToken string    `db:"cancellation_token" json:"-"`
+	CreatedAt         time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updatedAt"`
+	// Event           Event               `db:"event" json:"event"` // Example: Add if frequently needed via JOIN, exclude from JSON
+
+	// --- Example fields if joining Event data often ---
+	// These require specific SELECT aliases (e.g., "e.title AS event_title")
+
+	EventTitle       string                 `db:"event_title" json:"eventTitle,omitempty"`
+	EventDescription string                 `db:"event_description" json:"eventDescription,omitempty"`
+	LocationType     enum.EventLocationType `db:"location_type" json:"locationType,omitempty"` // Picked by the guest from the event's location types
+}
+
+// Webhook represents the 'webhooks' table.
+type Webhook struct {
+	ID        string         `db:"id" json:"id"`
+	UserID    string         `db:"user_id" json:"userId"`
+	URL       string         `db:"url" json:"url"`
+	Events    pq.StringArray `db:"events" json:"events"`
+	Secret    string         `db:"secret" json:"-"` // Only returned once on registration
+	IsActive  bool           `db:"is_active" json:"isActive"`
+	CreatedAt time.Time      `db:"created_at" json:"createdAt"`
+}
+
+// SlackWebhook represents the 'slack_webhooks' table.
+type SlackWebhook struct {
+	ID             string    `db:"id" json:"id"`
+	UserID         string    `db:"user_id" json:"userId"`
+	WebhookURL     string    `db:"webhook_url" json:"webhookUrl"`
+	NotifyOnCreate bool      `db:"notify_on_create" json:"notifyOnCreate"`
+	NotifyOnCancel bool      `db:"notify_on_cancel" json:"notifyOnCancel"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Organization represents the 'organizations' table.
+type Organization struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	OwnerID   string    `db:"owner_id" json:"ownerId"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// OrganizationMember represents the 'organization_members' table.
+type OrganizationMember struct {
+	OrgID    string       `db:"org_id" json:"orgId"`
+	UserID   string       `db:"user_id" json:"userId"`
+	Role     enum.OrgRole `db:"role" json:"role"`
+	JoinedAt time.Time    `db:"joined_at" json:"joinedAt"`
+}
+
+// OrganizationInvitation represents the 'organization_invitations' table.
+type OrganizationInvitation struct {
+	ID         string       `db:"id" json:"id"`
+	OrgID      string       `db:"org_id" json:"orgId"`
+	Email      string       `db:"email" json:"email"`
+	Role       enum.OrgRole `db:"role" json:"role"`
+	Token      string       `db:"token" json:"-"`
+	InvitedBy  string       `db:"invited_by" json:"invitedBy"`
+	ExpiresAt  time.Time    `db:"expires_at" json:"expiresAt"`
+	AcceptedAt sql.NullTime `db:"accepted_at" json:"acceptedAt"`
+	CreatedAt  time.Time    `db:"created_at" json:"createdAt"`
+}
+
+// RoundRobinEvent represents the 'round_robin_events' table.
+type RoundRobinEvent struct {
+	ID        string    `db:"id" json:"id"`
+	OrgID     string    `db:"org_id" json:"orgId"`
+	EventID   string    `db:"event_id" json:"eventId"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// RoundRobinEventHost represents the 'round_robin_event_hosts' table.
+type RoundRobinEventHost struct {
+	RoundRobinEventID string       `db:"round_robin_event_id" json:"roundRobinEventId"`
+	UserID            string       `db:"user_id" json:"userId"`
+	LastAssignedAt    sql.NullTime `db:"last_assigned_at" json:"lastAssignedAt"`
+}
+
+// AuditLog represents the 'audit_logs' table.
+type AuditLog struct {
+	ID         string          `db:"id" json:"id"`
+	UserID     sql.NullString  `db:"user_id" json:"userId"`
+	Action     string          `db:"action" json:"action"`
+	EntityType string          `db:"entity_type" json:"entityType"`
+	EntityID   string          `db:"entity_id" json:"entityId"`
+	IPAddress  string          `db:"ip_address" json:"ipAddress"`
+	UserAgent  string          `db:"user_agent" json:"userAgent"`
+	Metadata   json.RawMessage `db:"metadata" json:"metadata"`
+	CreatedAt  time.Time       `db:"created_at" json:"createdAt"`
+}
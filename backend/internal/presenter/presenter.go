@@ -1,17 +1,19 @@
-package presenter
-
-import (
-	"github.com/fazamuttaqien/calendly/internal/controller"
-	"github.com/jmoiron/sqlx"
-)
-
-type Presenter struct {
-	Controllers *controller.Controller
-}
-
-func New(db *sqlx.DB) Presenter {
-	controllers := controller.New(db)
-	return Presenter{
-		Controllers: controllers,
-	}
-}
+package presenter
+
+import (
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/internal/controller"
+)
+
+type Presenter struct {
+	Controllers *controller.Controller
+	DB          *database.DB
+}
+
+func New(db *database.DB) Presenter {
+	controllers := controller.New(db)
+	return Presenter{
+		Controllers: controllers,
+		DB:          db,
+	}
+}
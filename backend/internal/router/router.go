@@ -1,161 +1,426 @@
-package router
-
-import (
-	"net/http"
-	"os"
-	"time"
-
-	"github.com/fazamuttaqien/calendly/internal/dto"
-	"github.com/fazamuttaqien/calendly/internal/presenter"
-	"github.com/fazamuttaqien/calendly/middleware"
-	"github.com/fazamuttaqien/calendly/pkg/validator"
-
-	"github.com/go-chi/chi/v5"
-	chiMiddleware "github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-)
-
-func New(presenters presenter.Presenter) *chi.Mux {
-	r := chi.NewRouter()
-
-	// Basic CORS
-	// for more ideas, see: https://developer.github.com/v3/#cross-origin-resource-sharing
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins: []string{os.Getenv("FRONTEND_ORIGIN")}, // Use this to allow specific origin hosts
-		// AllowedOrigins:   []string{"https://*", "http://*"},
-		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	// Initialize middlewares
-	authMiddleware := middleware.AuthMiddleware
-	errorHandlerMiddleware := middleware.ErrorMiddleware
-
-	// Global middleware stack
-	r.Use(chiMiddleware.RequestID)
-	r.Use(chiMiddleware.RealIP)
-	r.Use(chiMiddleware.Logger)
-	r.Use(chiMiddleware.Recoverer)
-	r.Use(chiMiddleware.Timeout(60 * time.Second))
-	r.Use(errorHandlerMiddleware)
-	r.Use(securityHeadersMiddleware)
-
-	// API routes
-	r.Route("/api", func(r chi.Router) {
-		// --- Auth Routes (Public) ---
-		r.Route("/auth", func(r chi.Router) {
-			r.With(middleware.WithValidation[dto.RegisterDto](validator.SourceBody)).
-				Post("/register", presenters.Controllers.Register)
-
-			r.With(middleware.WithValidation[dto.LoginDto](validator.SourceBody)).
-				Post("/login", presenters.Controllers.Login)
-		})
-
-		// --- Availability Routes ---
-		r.Route("/availability", func(r chi.Router) {
-			// Public availability endpoints
-			r.Route("/public", func(r chi.Router) {
-				r.Get("/{eventId}", presenters.Controllers.GetPublicEventAvailability)
-			})
-
-			// Protected availability endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(authMiddleware)
-				r.Get("/", presenters.Controllers.GetUserAvailability)
-				r.With(middleware.WithValidation[dto.UpdateAvailabilityDto](validator.SourceBody)).
-					Put("/", presenters.Controllers.UpdateAvailability)
-			})
-		})
-
-		// --- Event Routes ---
-		r.Route("/event", func(r chi.Router) {
-			// Public event endpoints
-			r.Route("/public", func(r chi.Router) {
-				r.Get("/{username}", presenters.Controllers.GetPublicByUsername)
-				r.Get("/{username}/{slug}", presenters.Controllers.GetPublicBySlug)
-			})
-
-			// Protected event endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(authMiddleware)
-
-				r.Get("/", presenters.Controllers.GetUserEvents)
-
-				r.With(middleware.WithValidation[dto.CreateEventDto](validator.SourceBody)).
-					Post("/", presenters.Controllers.CreateEvent)
-
-				r.Route("/{eventId}", func(r chi.Router) {
-					r.Put("/toggle-privacy", presenters.Controllers.TogglePrivacy)
-					r.Delete("/", presenters.Controllers.DeleteEvent)
-				})
-			})
-		})
-
-		// --- Integration Routes ---
-		r.Route("/integration", func(r chi.Router) {
-
-			r.Get("/google/callback", presenters.Controllers.GoogleOAuthCallback)
-
-			// Protected integration endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(authMiddleware)
-				r.Get("/", presenters.Controllers.GetUserIntegrations)
-				r.Get("/check/{appType}", presenters.Controllers.CheckIntegration)
-				r.Get("/connect/{appType}", presenters.Controllers.ConnectApp)
-			})
-		})
-
-		// --- Meeting Routes ---
-		r.Route("/meeting", func(r chi.Router) {
-			// Public meeting endpoints
-			r.Route("/public", func(r chi.Router) {
-				r.With(middleware.WithValidation[dto.CreateMeetingDto](validator.SourceBody)).
-					Post("/", presenters.Controllers.CreateBooking)
-			})
-
-			// Protected meeting endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(authMiddleware)
-				r.Get("/", presenters.Controllers.GetUserMeetings)
-				r.Delete("/{meetingId}", presenters.Controllers.CancelMeeting)
-			})
-		})
-	})
-
-	// Health check endpoint for monitoring
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
-
-	return r
-}
-
-// Enhanced security headers middleware
-func securityHeadersMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Basic security headers
-		w.Header().Set("X-XSS-Protection", "1; mode=block")
-		w.Header().Set("X-Frame-Options", "DENY")
-		w.Header().Set("X-Content-Type-Options", "nosniff")
-		w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains; preload")
-
-		// Enhanced CSP policy
-		w.Header().Set("Content-Security-Policy",
-			"default-src 'self'; script-src 'self'; connect-src 'self'; img-src 'self'; style-src 'self';")
-
-		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		w.Header().Set("Permissions-Policy", "geolocation=(self), microphone=(), camera=(), payment=()")
-
-		// Cache control for API responses
-		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
-		w.Header().Set("Pragma", "no-cache")
-		w.Header().Set("Expires", "0")
-
-		next.ServeHTTP(w, r)
-	})
-}
+package router
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/dto"
+	"github.com/fazamuttaqien/calendly/internal/presenter"
+	"github.com/fazamuttaqien/calendly/middleware"
+	"github.com/fazamuttaqien/calendly/pkg/validator"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/time/rate"
+)
+
+// New builds the HTTP handler. adminAllowedCIDRs are the networks allowed to reach /admin, /metrics
+// and the other admin-only routes, all of which are disabled when it is empty.
+func New(presenters presenter.Presenter, adminAllowedCIDRs []string) *chi.Mux {
+	r := chi.NewRouter()
+
+	// Basic CORS
+	// for more ideas, see: https://developer.github.com/v3/#cross-origin-resource-sharing
+	allowedOrigins, allowOriginFunc := corsOrigins()
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   allowedOrigins,
+		AllowOriginFunc:  allowOriginFunc, // Only set when ALLOWED_ORIGINS_REGEX is, it then replaces AllowedOrigins
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Idempotency-Key"},
+		ExposedHeaders:   []string{"Link", "Deprecation", "Sunset"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// Initialize middlewares
+	authMiddleware := middleware.AuthMiddleware(presenters.DB)
+	// 10 requests per minute per IP against credential endpoints
+	authRateLimit := middleware.RateLimitMiddleware(rate.Every(time.Minute/10), 10)
+	// Same budget for unauthenticated lookups by meeting token, kept in separate buckets
+	meetingTokenRateLimit := middleware.RateLimitMiddleware(rate.Every(time.Minute/10), 10)
+	// 10 availability streams per minute per IP, clients reconnect about once a minute
+	streamRateLimit := middleware.RateLimitMiddleware(rate.Every(time.Minute/10), 10)
+	// 30 requests per minute per IP for browsing all public events
+	publicEventsRateLimit := middleware.RateLimitMiddleware(rate.Every(time.Minute/30), 30)
+	streamConcurrency := middleware.MaxConcurrency(helper.GetEnvInt("MAX_CONCURRENT_STREAMS", middleware.DefaultMaxConcurrentStreams), nil)
+	adminMiddleware := middleware.AdminMiddleware
+	adminAllowlist := middleware.IPAllowlist(adminAllowedCIDRs)
+	errorHandlerMiddleware := middleware.ErrorMiddleware
+
+	// Global middleware stack
+	r.Use(chiMiddleware.RequestID)
+	r.Use(middleware.MetricsMiddleware)
+	middleware.RegisterDBStatsMetrics(presenters.DB.Stats)
+	r.Use(middleware.TracingMiddleware)
+	// Forwarded client IPs are only trusted from our own reverse proxies
+	r.Use(middleware.RealIP(helper.GetEnvList("TRUSTED_PROXY_CIDRS")))
+	r.Use(middleware.SlogLogger)
+	r.Use(chiMiddleware.Recoverer)
+	// Shed load before it reaches the database connection pool. Availability streams stay open for
+	// close to a minute, they get their own smaller limit so they can't starve regular requests.
+	r.Use(middleware.MaxConcurrency(helper.GetEnvInt("MAX_CONCURRENT_REQUESTS", middleware.DefaultMaxConcurrentRequests), isAvailabilityStream))
+	r.Use(middleware.CompressMiddleware)
+	r.Use(middleware.MaxBodySizeMiddleware(int64(helper.GetEnvInt("MAX_REQUEST_BODY_BYTES", int(middleware.DefaultMaxBodyBytes)))))
+	// Timeouts are set per route group below, see middleware.WithTimeout
+	r.Use(errorHandlerMiddleware)
+	r.Use(securityHeadersMiddleware)
+
+	// v1 API routes, breaking changes go into a separate v2 tree so v1 keeps working
+	apiV1 := func(r chi.Router) {
+		// Every mutation endpoint takes a JSON body, others are rejected before validation
+		r.Use(middleware.RequireJSONContentType)
+
+		// --- Auth Routes (Public) ---
+		r.Route("/auth", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.AuthRequestTimeout))
+
+			r.With(authRateLimit, middleware.WithValidation[dto.RegisterDto](validator.SourceBody)).
+				Post("/register", presenters.Controllers.Register)
+
+			r.With(authRateLimit, middleware.WithValidation[dto.LoginDto](validator.SourceBody)).
+				Post("/login", presenters.Controllers.Login)
+
+			r.With(middleware.WithValidation[dto.VerifyEmailDto](validator.SourceQuery)).
+				Get("/verify-email", presenters.Controllers.VerifyEmail)
+
+			r.With(authRateLimit, middleware.WithValidation[dto.ResendVerificationDto](validator.SourceBody)).
+				Post("/resend-verification", presenters.Controllers.ResendVerificationEmail)
+
+			r.With(authMiddleware).Post("/logout", presenters.Controllers.Logout)
+
+			// Sign in with Google
+			r.Get("/google/login", presenters.Controllers.GoogleLogin)
+			r.Get("/google/login/callback", presenters.Controllers.GoogleLoginCallback)
+		})
+
+		// Public feature flags of this deployment
+		r.With(middleware.WithTimeout(middleware.DefaultRequestTimeout)).Get("/features", presenters.Controllers.GetFeatures)
+
+		// --- Current User Routes ---
+		r.Route("/me", func(r chi.Router) {
+			r.Use(authMiddleware)
+
+			// The export builds the whole account as one document
+			r.With(middleware.WithTimeout(middleware.DataExportRequestTimeout)).
+				Get("/data-export", presenters.Controllers.ExportUserData)
+
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout))
+				r.Get("/", presenters.Controllers.GetCurrentUser)
+				r.With(middleware.WithValidation[dto.UpdateSettingsDto](validator.SourceBody)).
+					Patch("/", presenters.Controllers.UpdateSettings)
+				r.With(middleware.WithValidation[dto.DeleteAccountDto](validator.SourceBody)).
+					Delete("/", presenters.Controllers.DeleteAccount)
+				r.With(middleware.WithValidation[dto.ChangePasswordDto](validator.SourceBody)).
+					Patch("/password", presenters.Controllers.ChangePassword)
+				r.Get("/analytics", presenters.Controllers.GetAnalytics)
+				r.Get("/token-info", presenters.Controllers.GetTokenInfo)
+				r.Get("/booking-preview/{slug}", presenters.Controllers.GetBookingPreview)
+
+				r.Route("/notifications/slack", func(r chi.Router) {
+					r.Get("/", presenters.Controllers.GetSlackWebhook)
+					r.With(middleware.WithValidation[dto.SaveSlackWebhookDto](validator.SourceBody)).
+						Post("/", presenters.Controllers.SaveSlackWebhook)
+					r.Delete("/", presenters.Controllers.DeleteSlackWebhook)
+				})
+			})
+		})
+
+		// --- Availability Routes ---
+		r.Route("/availability", func(r chi.Router) {
+			// Public availability endpoints
+			r.Route("/public", func(r chi.Router) {
+				availabilityTimeout := middleware.WithTimeout(middleware.AvailabilityRequestTimeout)
+
+				// Event IDs are UUIDs; anything else is treated as a username
+				r.With(availabilityTimeout, middleware.WithValidation[dto.AvailabilityRangeDto](validator.SourceQuery)).
+					Get("/{eventId:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}}", presenters.Controllers.GetPublicEventAvailability)
+				// Live updates of the same slots as server-sent events, the stream ends shortly before
+				// the timeout and clients reconnect, so it keeps the longer default
+				r.With(streamRateLimit, streamConcurrency, middleware.WithTimeout(middleware.DefaultRequestTimeout), middleware.WithValidation[dto.AvailabilityRangeDto](validator.SourceQuery)).
+					Get("/{eventId:[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}}/stream", presenters.Controllers.StreamPublicEventAvailability)
+				r.With(availabilityTimeout).Get("/{username}", presenters.Controllers.GetPublicUserAvailability)
+			})
+
+			// Protected availability endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.WithTimeout(middleware.AvailabilityRequestTimeout), authMiddleware)
+				r.Get("/", presenters.Controllers.GetUserAvailability)
+				r.With(middleware.WithValidation[dto.UpdateAvailabilityDto](validator.SourceBody)).
+					Put("/", presenters.Controllers.UpdateAvailability)
+				r.With(middleware.WithValidation[dto.UpdateTimeGapDto](validator.SourceBody)).
+					Patch("/time-gap", presenters.Controllers.UpdateTimeGap)
+				r.With(
+					middleware.WithValidation[dto.DayParamDto](validator.SourceParams),
+					middleware.WithValidation[dto.UpdateDayAvailabilityDto](validator.SourceBody),
+				).Patch("/day/{day}", presenters.Controllers.UpdateDayAvailability)
+
+				r.Route("/exceptions", func(r chi.Router) {
+					r.Get("/", presenters.Controllers.GetAvailabilityExceptions)
+					r.With(middleware.WithValidation[dto.CreateAvailabilityExceptionDto](validator.SourceBody)).
+						Post("/", presenters.Controllers.CreateAvailabilityException)
+					r.Delete("/{id}", presenters.Controllers.DeleteAvailabilityException)
+				})
+
+				r.Route("/vacation", func(r chi.Router) {
+					r.Get("/", presenters.Controllers.GetVacationBlocks)
+					r.With(middleware.WithValidation[dto.CreateVacationBlockDto](validator.SourceBody)).
+						Post("/", presenters.Controllers.CreateVacationBlock)
+					r.Delete("/{id}", presenters.Controllers.DeleteVacationBlock)
+				})
+			})
+		})
+
+		// --- Event Routes ---
+		r.Route("/event", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout))
+
+			// Public event endpoints
+			r.Route("/public", func(r chi.Router) {
+				// Discovery across all users, rate limited as it is open to scraping
+				r.With(publicEventsRateLimit, middleware.WithValidation[dto.PublicEventListQueryDto](validator.SourceQuery)).
+					Get("/", presenters.Controllers.ListPublicEvents)
+				r.Get("/{username}", presenters.Controllers.GetPublicByUsername)
+				r.Get("/{username}/{slug}", presenters.Controllers.GetPublicBySlug)
+			})
+
+			// Public event discovery
+			r.With(middleware.WithValidation[dto.EventSearchQueryDto](validator.SourceQuery)).
+				Get("/search", presenters.Controllers.SearchPublicEvents)
+
+			// Protected event endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware)
+
+				r.With(middleware.WithValidation[dto.EventListQueryDto](validator.SourceQuery)).
+					Get("/", presenters.Controllers.GetUserEvents)
+
+				r.With(middleware.WithValidation[dto.CreateEventDto](validator.SourceBody)).
+					Post("/", presenters.Controllers.CreateEvent)
+
+				r.Route("/{eventId}", func(r chi.Router) {
+					r.Use(middleware.WithValidation[dto.EventIdDto](validator.SourceParams))
+
+					r.With(middleware.WithValidation[dto.UpdateEventDto](validator.SourceBody)).
+						Put("/", presenters.Controllers.UpdateEvent)
+					r.With(middleware.WithValidation[dto.UpdateEventDto](validator.SourceBody)).
+						Patch("/", presenters.Controllers.UpdateEvent)
+					r.Put("/toggle-privacy", presenters.Controllers.TogglePrivacy)
+					r.With(middleware.WithValidation[dto.CloneAvailabilityDto](validator.SourceBody)).
+						Post("/clone-availability", presenters.Controllers.CloneAvailability)
+					r.Post("/copy", presenters.Controllers.CopyEvent)
+					r.Get("/analytics", presenters.Controllers.GetEventAnalytics)
+					r.With(middleware.WithValidation[dto.EventMeetingListQueryDto](validator.SourceQuery)).
+						Get("/meetings", presenters.Controllers.GetEventMeetings)
+					r.Delete("/", presenters.Controllers.DeleteEvent)
+					r.With(adminAllowlist, adminMiddleware).Delete("/hard", presenters.Controllers.HardDeleteEvent)
+				})
+			})
+		})
+
+		// --- Integration Routes ---
+		r.Route("/integration", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout))
+
+			r.Get("/google/callback", presenters.Controllers.GoogleOAuthCallback)
+			r.Get("/zoom/callback", presenters.Controllers.GoogleOAuthCallback)
+
+			// Protected integration endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware)
+				r.With(middleware.WithValidation[dto.IntegrationListQueryDto](validator.SourceQuery)).
+					Get("/", presenters.Controllers.GetUserIntegrations)
+				r.Get("/check/{appType}", presenters.Controllers.CheckIntegration)
+				r.Get("/connect/{appType}", presenters.Controllers.ConnectApp)
+				r.Delete("/{appType}", presenters.Controllers.DisconnectIntegration)
+				r.Get("/{appType}/health", presenters.Controllers.CheckIntegrationHealth)
+			})
+		})
+
+		// --- Meeting Routes ---
+		r.Route("/meeting", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout))
+
+			// Public meeting endpoints
+			r.Route("/public", func(r chi.Router) {
+				r.With(middleware.WithValidation[dto.CreateMeetingDto](validator.SourceBody)).
+					Post("/", presenters.Controllers.CreateBooking)
+
+				r.With(meetingTokenRateLimit).Get("/{token}", presenters.Controllers.GetMeetingByToken)
+
+				r.With(meetingTokenRateLimit, middleware.WithValidation[dto.RescheduleMeetingDto](validator.SourceBody)).
+					Patch("/{token}/reschedule", presenters.Controllers.RescheduleMeetingByToken)
+			})
+
+			// Guest self-cancellation using the token returned on booking
+			r.With(middleware.WithValidation[dto.CancelByTokenDto](validator.SourceQuery)).
+				Post("/cancel", presenters.Controllers.CancelMeetingByToken)
+
+			// Protected meeting endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware)
+				r.With(middleware.WithValidation[dto.MeetingListQueryDto](validator.SourceQuery)).
+					Get("/", presenters.Controllers.GetUserMeetings)
+				r.Get("/export", presenters.Controllers.ExportMeetings)
+				r.With(middleware.WithValidation[dto.MeetingSearchQueryDto](validator.SourceQuery)).
+					Get("/search", presenters.Controllers.SearchMeetings)
+				r.Get("/{meetingId}", presenters.Controllers.GetMeeting)
+				r.Delete("/{meetingId}", presenters.Controllers.CancelMeeting)
+				r.With(middleware.WithValidation[dto.RescheduleMeetingDto](validator.SourceBody)).
+					Patch("/{meetingId}/reschedule", presenters.Controllers.RescheduleMeeting)
+				r.With(middleware.WithValidation[dto.UpdateMeetingNotesDto](validator.SourceBody)).
+					Patch("/{meetingId}/notes", presenters.Controllers.UpdateMeetingNotes)
+				r.Patch("/{meetingId}/no-show", presenters.Controllers.MarkMeetingNoShow)
+			})
+		})
+
+		// --- Organization Routes ---
+		r.Route("/orgs", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout), authMiddleware)
+			r.With(middleware.WithValidation[dto.CreateOrganizationDto](validator.SourceBody)).
+				Post("/", presenters.Controllers.CreateOrganization)
+			r.With(middleware.WithValidation[dto.AcceptOrganizationInviteDto](validator.SourceQuery)).
+				Post("/invitations/accept", presenters.Controllers.AcceptOrganizationInvite)
+
+			r.Route("/{orgSlug}", func(r chi.Router) {
+				r.Use(middleware.WithValidation[dto.OrgSlugDto](validator.SourceParams))
+				r.Get("/members", presenters.Controllers.GetOrganizationMembers)
+				r.With(middleware.WithValidation[dto.InviteOrganizationMemberDto](validator.SourceBody)).
+					Post("/invite", presenters.Controllers.InviteOrganizationMember)
+				r.With(middleware.WithValidation[dto.CreateRoundRobinEventDto](validator.SourceBody)).
+					Post("/round-robin", presenters.Controllers.CreateRoundRobinEvent)
+			})
+		})
+
+		// --- Admin Routes ---
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.AdminRequestTimeout), adminAllowlist, authMiddleware, adminMiddleware)
+			r.With(middleware.WithValidation[dto.AuditLogQueryDto](validator.SourceQuery)).
+				Get("/audit-logs", presenters.Controllers.GetAuditLogs)
+
+			r.With(middleware.WithValidation[dto.AdminUserListQueryDto](validator.SourceQuery)).
+				Get("/users", presenters.Controllers.ListUsers)
+			r.With(middleware.WithValidation[dto.UpdateUserRoleDto](validator.SourceBody)).
+				Patch("/users/{userId}/role", presenters.Controllers.UpdateUserRole)
+		})
+
+		// --- Webhook Routes ---
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(middleware.WithTimeout(middleware.DefaultRequestTimeout), authMiddleware)
+			r.With(middleware.WithValidation[dto.CreateWebhookDto](validator.SourceBody)).
+				Post("/", presenters.Controllers.CreateWebhook)
+		})
+	}
+
+	// Deprecation and Sunset headers are only sent once the dates are configured
+	v1Deprecation := middleware.DeprecationMiddleware(
+		helper.GetEnvTime("API_V1_DEPRECATED_AT", time.Time{}),
+		helper.GetEnvTime("API_V1_SUNSET_AT", time.Time{}),
+	)
+	r.With(v1Deprecation).Route("/api/v1", apiV1)
+	// Unversioned paths are served by v1 until existing clients have moved to /api/v1
+	r.With(v1Deprecation).Route("/api", apiV1)
+
+	// Health check endpoint for monitoring, kept for existing monitors, prefer /health/live
+	r.With(middleware.WithTimeout(middleware.ReadinessRequestTimeout)).Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+
+	// Prometheus metrics from the default registry, only scrapers on the admin networks may read them
+	r.With(middleware.WithTimeout(middleware.DefaultRequestTimeout), adminAllowlist).Handle("/metrics", promhttp.Handler())
+
+	// Orchestrator probes are served before any middleware, so logging, rate limits
+	// and timeouts never affect them. Everything else goes through r.
+	root := chi.NewRouter()
+
+	// Liveness probe, the process is up when it answers at all
+	root.Get("/health/live", func(w http.ResponseWriter, r *http.Request) {
+		helper.ResponseJson(w, http.StatusOK, map[string]any{"status": "healthy"})
+	})
+
+	// Readiness probe, only ready when the database is reachable
+	root.With(middleware.WithTimeout(middleware.ReadinessRequestTimeout)).Get("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		if err := presenters.DB.PingContext(ctx); err != nil {
+			helper.ResponseJson(w, http.StatusServiceUnavailable, map[string]any{
+				"status": "unhealthy",
+				"reason": "database unavailable",
+			})
+			return
+		}
+
+		helper.ResponseJson(w, http.StatusOK, map[string]any{"status": "healthy"})
+	})
+
+	root.Mount("/", r)
+
+	return root
+}
+
+// isAvailabilityStream reports whether r opens a server-sent event stream of public availability
+func isAvailabilityStream(r *http.Request) bool {
+	return strings.Contains(r.URL.Path, "/availability/public/") && strings.HasSuffix(r.URL.Path, "/stream")
+}
+
+// corsOrigins reads the origins allowed to call the API: the comma-separated ALLOWED_ORIGINS
+// plus the legacy single FRONTEND_ORIGIN. ALLOWED_ORIGINS_REGEX additionally allows every origin
+// matching the whole pattern, e.g. https://[a-z0-9-]+\.preview\.example\.com, through the returned func.
+func corsOrigins() ([]string, func(r *http.Request, origin string) bool) {
+	origins := helper.GetEnvList("ALLOWED_ORIGINS")
+	if legacy := os.Getenv("FRONTEND_ORIGIN"); legacy != "" && !slices.Contains(origins, legacy) {
+		origins = append(origins, legacy)
+	}
+
+	pattern := os.Getenv("ALLOWED_ORIGINS_REGEX")
+	if pattern == "" {
+		return origins, nil
+	}
+	// Anchored so a pattern for example.com does not also match example.com.attacker.net
+	originRegex, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		slog.Error("Ignoring invalid ALLOWED_ORIGINS_REGEX", "pattern", pattern, "error", err)
+		return origins, nil
+	}
+
+	// cors ignores AllowedOrigins once AllowOriginFunc is set, so the list is checked here too
+	return origins, func(r *http.Request, origin string) bool {
+		return slices.Contains(origins, origin) || originRegex.MatchString(origin)
+	}
+}
+
+// Enhanced security headers middleware
+func securityHeadersMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Basic security headers
+		w.Header().Set("X-XSS-Protection", "1; mode=block")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains; preload")
+
+		// Enhanced CSP policy
+		w.Header().Set("Content-Security-Policy",
+			"default-src 'self'; script-src 'self'; connect-src 'self'; img-src 'self'; style-src 'self';")
+
+		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
+		w.Header().Set("Permissions-Policy", "geolocation=(self), microphone=(), camera=(), payment=()")
+
+		// Cache control for API responses
+		w.Header().Set("Cache-Control", "no-store, no-cache, must-revalidate, max-age=0")
+		w.Header().Set("Pragma", "no-cache")
+		w.Header().Set("Expires", "0")
+
+		next.ServeHTTP(w, r)
+	})
+}
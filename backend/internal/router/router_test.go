@@ -0,0 +1,54 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fazamuttaqien/calendly/database"
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/controller"
+	"github.com/fazamuttaqien/calendly/internal/presenter"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestGetPublicByUsernameUnknownUser(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "http://localhost:3000")
+
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	defer mockDB.Close()
+
+	sqlxDB := sqlx.NewDb(mockDB, "postgres")
+	db := &database.DB{DB: sqlxDB, ReadDB: sqlxDB}
+	handler := New(presenter.Presenter{Controllers: controller.New(db), DB: db}, nil)
+
+	mock.ExpectQuery(`FROM users u\s+LEFT JOIN events e`).
+		WithArgs("nonexistentuser").
+		WillReturnRows(sqlmock.NewRows([]string{"u_id", "u_name", "u_image_url"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/event/public/nonexistentuser", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusNotFound, rec.Body.String())
+	}
+
+	var body helper.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body.Code != string(enum.ResourceNotFound) {
+		t.Errorf("code = %q, want %q", body.Code, enum.ResourceNotFound)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet expectations: %v", err)
+	}
+}
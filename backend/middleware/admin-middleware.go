@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+)
+
+// AdminMiddleware only lets tokens carrying the admin role through, it must run after AuthMiddleware.
+// Changing a role revokes the user's existing tokens, so the claim cannot outlive a demotion.
+func AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetClaimsFromContext(r.Context())
+		if !ok || !claims.IsAdmin() {
+			appError.WriteError(w, appError.NewUnauthorizedError(nil))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
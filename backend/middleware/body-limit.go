@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+// DefaultMaxBodyBytes is the request body limit used when MAX_REQUEST_BODY_BYTES is not set.
+const DefaultMaxBodyBytes int64 = 1 << 20 // 1 MB
+
+// MaxBodySizeMiddleware caps request bodies at maxBytes.
+// Requests declaring a larger Content-Length are rejected up front with 413,
+// others fail with *http.MaxBytesError once reading passes the limit.
+func MaxBodySizeMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				appError.WriteError(w, appError.NewAppError(enum.RequestTooLarge, "Request body too large", nil))
+				return
+			}
+
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// compressMinBytes is the smallest body worth gzipping, below it the overhead outweighs the savings
+const compressMinBytes = 1024
+
+var gzipWriterPool = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// CompressMiddleware gzips JSON responses of at least compressMinBytes for clients sending Accept-Encoding: gzip.
+// Responses are buffered until the threshold is reached, handlers that Flush before that are streamed uncompressed.
+func CompressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(cw, r)
+		// Not deferred: after a panic the recoverer must be able to write its own response
+		cw.close()
+	})
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for part := range strings.SplitSeq(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of a response to decide whether to gzip it.
+type compressWriter struct {
+	http.ResponseWriter
+	status      int
+	buf         bytes.Buffer
+	decided     bool
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = code
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	cw.wroteHeader = true
+	if cw.decided {
+		if cw.gz != nil {
+			return cw.gz.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+
+	n, _ := cw.buf.Write(p)
+	if cw.buf.Len() >= compressMinBytes {
+		if err := cw.decide(cw.shouldCompress()); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Flush marks the response as streaming: whatever has not been compressed yet is sent as is.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(false); err != nil {
+			return
+		}
+	}
+	if cw.gz != nil {
+		cw.gz.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *compressWriter) shouldCompress() bool {
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
+
+// decide sends the status line and the buffered bytes, through gzip when compress is set.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+
+		cw.gz = gzipWriterPool.Get().(*gzip.Writer)
+		cw.gz.Reset(cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if cw.gz != nil {
+		_, err = cw.gz.Write(cw.buf.Bytes())
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf.Bytes())
+	}
+	cw.buf.Reset()
+	return err
+}
+
+// close sends small responses uncompressed and finishes the gzip stream of large ones.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		cw.decide(false)
+	}
+	if cw.gz != nil {
+		cw.gz.Close()
+		gzipWriterPool.Put(cw.gz)
+		cw.gz = nil
+	}
+}
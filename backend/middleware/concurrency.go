@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultMaxConcurrentRequests is the concurrency limit used when MAX_CONCURRENT_REQUESTS is not set.
+const DefaultMaxConcurrentRequests = 100
+
+// DefaultMaxConcurrentStreams is the limit on open server-sent event streams used when MAX_CONCURRENT_STREAMS is not set.
+const DefaultMaxConcurrentStreams = 50
+
+var concurrencyLimiterInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "http_concurrency_limiter_in_flight",
+	Help: "Number of HTTP requests currently admitted by the concurrency limiter.",
+})
+
+// MaxConcurrency serves at most maxRequests requests at once. Requests over the limit are not queued,
+// they get 503 with Retry-After right away so a burst cannot exhaust the database connection pool.
+// A non-positive maxRequests falls back to DefaultMaxConcurrentRequests. Requests for which exempt
+// returns true bypass the limit, e.g. long-lived streams that are limited separately.
+func MaxConcurrency(maxRequests int, exempt func(*http.Request) bool) func(http.Handler) http.Handler {
+	if maxRequests <= 0 {
+		maxRequests = DefaultMaxConcurrentRequests
+	}
+	semaphore := make(chan struct{}, maxRequests)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt != nil && exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case semaphore <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				appError.WriteError(w, appError.NewAppError(enum.ServiceUnavailable, "Server is busy, please try again later", nil))
+				return
+			}
+			concurrencyLimiterInFlight.Inc()
+			defer func() {
+				concurrencyLimiterInFlight.Dec()
+				<-semaphore
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+// RequireJSONContentType rejects POST, PUT and PATCH requests whose body is not declared as
+// application/json with 415, instead of letting the body decoding fail with a confusing error.
+// Requests without a body (e.g. POST /auth/logout) are let through.
+func RequireJSONContentType(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			appError.WriteError(w, appError.NewAppError(enum.UnsupportedMediaType, "Content-Type must be application/json", err))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
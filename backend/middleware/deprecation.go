@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DeprecationMiddleware announces that an API version is going away.
+// Deprecation uses the RFC 9745 "@<unix seconds>" form and Sunset the RFC 8594 HTTP-date,
+// a zero time leaves the matching header out.
+func DeprecationMiddleware(deprecatedAt, sunsetAt time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !deprecatedAt.IsZero() {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecatedAt.Unix(), 10))
+			}
+			if !sunsetAt.IsZero() {
+				w.Header().Set("Sunset", sunsetAt.UTC().Format(http.TimeFormat))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+// IPAllowlist only lets requests from the given CIDR ranges through and answers others with 403.
+// The client IP is r.RemoteAddr, which RealIP only replaces with forwarded headers of trusted proxies.
+// Invalid ranges are logged and ignored. With no valid range every request gets 503, the routes are disabled.
+func IPAllowlist(allowedCIDRs []string) func(http.Handler) http.Handler {
+	networks := parseCIDRs(allowedCIDRs, "IP allowlist")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(networks) == 0 {
+				appError.WriteError(w, appError.NewAppError(enum.ServiceUnavailable, "This endpoint is disabled", nil))
+				return
+			}
+
+			ip := net.ParseIP(clientIP(r))
+			for _, network := range networks {
+				if ip != nil && network.Contains(ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			slog.Warn("Request rejected by IP allowlist", "ip", r.RemoteAddr, "path", r.URL.Path)
+			appError.WriteError(w, appError.NewUnauthorizedError(nil))
+		})
+	}
+}
+
+// parseCIDRs parses cidrs, logging and skipping invalid ranges of the named list
+func parseCIDRs(cidrs []string, list string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			slog.Error("Ignoring invalid CIDR in "+list, "cidr", cidr, "error", err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "path", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests in seconds by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+
+	httpRequestsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served.",
+	})
+)
+
+// MetricsMiddleware records Prometheus metrics for every request.
+// Paths are labeled by their chi route pattern to keep label cardinality bounded.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpRequestsInFlight.Inc()
+		defer httpRequestsInFlight.Dec()
+
+		start := time.Now()
+		ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		// The pattern is only complete once routing has finished
+		path := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			path = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		httpRequestsTotal.WithLabelValues(r.Method, path, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
+	})
+}
+
+var registerDBStatsOnce sync.Once
+
+// RegisterDBStatsMetrics publishes connection pool statistics as gauges, read from stats on every scrape.
+func RegisterDBStatsMetrics(stats func() sql.DBStats) {
+	registerDBStatsOnce.Do(func() {
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "db_open_connections",
+			Help: "Number of established database connections, both in use and idle.",
+		}, func() float64 { return float64(stats().OpenConnections) })
+
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "db_wait_count",
+			Help: "Total number of connections waited for because the pool was exhausted.",
+		}, func() float64 { return float64(stats().WaitCount) })
+
+		promauto.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "db_max_idle_time_closed",
+			Help: "Total number of connections closed due to the max idle time.",
+		}, func() float64 { return float64(stats().MaxIdleTimeClosed) })
+	})
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+
+	"golang.org/x/time/rate"
+)
+
+// visitorIdleTimeout is how long an IP is remembered after its last request
+const visitorIdleTimeout = 10 * time.Minute
+
+type visitor struct {
+	limiter *rate.Limiter
+	mu      sync.Mutex
+	seen    time.Time
+}
+
+// RateLimitMiddleware limits requests per client IP to limit per second with the given burst.
+// Clients over the limit get 429 AuthTooManyAttempts with a Retry-After header.
+// It relies on the RealIP middleware having resolved r.RemoteAddr.
+func RateLimitMiddleware(limit rate.Limit, burst int) func(http.Handler) http.Handler {
+	var visitors sync.Map // client IP -> *visitor
+
+	// Forget idle visitors so the map doesn't grow without bound
+	go func() {
+		ticker := time.NewTicker(visitorIdleTimeout)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			visitors.Range(func(key, value any) bool {
+				v := value.(*visitor)
+				v.mu.Lock()
+				idle := time.Since(v.seen) > visitorIdleTimeout
+				v.mu.Unlock()
+				if idle {
+					visitors.Delete(key)
+				}
+				return true
+			})
+		}
+	}()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := clientIP(r)
+
+			value, _ := visitors.LoadOrStore(ip, &visitor{limiter: rate.NewLimiter(limit, burst)})
+			v := value.(*visitor)
+
+			v.mu.Lock()
+			v.seen = time.Now()
+			v.mu.Unlock()
+
+			reservation := v.limiter.Reserve()
+			if delay := reservation.Delay(); delay > 0 {
+				// Don't consume a token for a rejected request
+				reservation.Cancel()
+
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+				appError.WriteError(w, appError.NewAppError(enum.AuthTooManyAttempts, "Too many requests, please try again later", nil))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP strips the port from r.RemoteAddr when present
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RealIP sets r.RemoteAddr to the client IP forwarded by a trusted reverse proxy.
+// X-Real-IP and X-Forwarded-For are only honoured when the connection comes from one of
+// trustedProxyCIDRs, otherwise anyone could spoof their address past IPAllowlist and the rate limits.
+// With no trusted proxy configured the peer address of the connection is kept.
+func RealIP(trustedProxyCIDRs []string) func(http.Handler) http.Handler {
+	proxies := parseCIDRs(trustedProxyCIDRs, "trusted proxy list")
+	trusted := func(ip net.IP) bool {
+		for _, network := range proxies {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if trusted(net.ParseIP(clientIP(r))) {
+				if ip := forwardedIP(r, trusted); ip != "" {
+					r.RemoteAddr = ip
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns X-Real-IP, or else the rightmost X-Forwarded-For entry that is not a trusted proxy.
+// Entries left of it were supplied by the client and can't be trusted.
+func forwardedIP(r *http.Request, trusted func(net.IP) bool) string {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return ""
+		}
+		if !trusted(ip) {
+			return ip.String()
+		}
+	}
+	return ""
+}
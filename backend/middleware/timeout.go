@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Request timeouts of the route groups, every route sits in exactly one of them
+// since nested timeouts can only shorten the outer one.
+const (
+	DefaultRequestTimeout      = 60 * time.Second
+	AuthRequestTimeout         = 10 * time.Second
+	AvailabilityRequestTimeout = 30 * time.Second
+	DataExportRequestTimeout   = 120 * time.Second
+	AdminRequestTimeout        = 30 * time.Second
+	ReadinessRequestTimeout    = 3 * time.Second
+)
+
+// WithTimeout cancels the request context after d and answers 504 Gateway Timeout
+// if the handler has not written a response by then.
+func WithTimeout(d time.Duration) func(http.Handler) http.Handler {
+	return chiMiddleware.Timeout(d)
+}
@@ -1,92 +1,243 @@
-package middleware
-
-import (
-	"context"
-	"encoding/json"
-	"errors"
-	"fmt"
-	"log"
-	"net/http"
-
-	"github.com/fazamuttaqien/calendly/pkg/enum"
-	pkgValidator "github.com/fazamuttaqien/calendly/pkg/validator"
-	"github.com/fazamuttaqien/calendly/types"
-
-	"github.com/go-playground/validator/v10"
-)
-
-// WithValidation creates middleware to validate request data against a struct (DTO).
-// T is the type of the struct to validate against.
-// source indicates where to find the data ("body", "query", "params").
-func WithValidation[T any](source string) func(http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Create a zero instance of the target struct type T
-			var dto T // dto := new(T) works too but T is often cleaner
-
-			var err error
-			switch source {
-			case pkgValidator.SourceBody:
-				// Decode JSON body
-				if r.Body == nil {
-					err = fmt.Errorf("request body is empty")
-				} else {
-					decoder := json.NewDecoder(r.Body)
-					// decoder.DisallowUnknownFields() // Optional: Be strict about fields
-					if decodeErr := decoder.Decode(&dto); decodeErr != nil {
-						err = fmt.Errorf("failed to decode request body: %w", decodeErr)
-					}
-					// NOTE: Consider closing r.Body if necessary, though Decode might handle it.
-				}
-				if err != nil {
-					// Use your error handling mechanism, here we write response directly
-					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Invalid request body.", nil)
-					return
-				}
-
-			case pkgValidator.SourceQuery:
-				// TODO: Implement query parameter parsing and mapping to dto.
-				// This is more complex. Libraries like 'gorilla/schema' can help.
-				// For now, return an error indicating it's not implemented.
-				pkgValidator.WriteValidationErrorResponse(w, http.StatusNotImplemented, enum.InternalServerError, "Query parameter validation not implemented.", nil)
-				return
-
-			case pkgValidator.SourceParams:
-				// TODO: Implement path parameter parsing (requires router integration)
-				// and mapping to dto fields. This often involves manual mapping.
-				// Example (using chi): chi.URLParam(r, "id")
-				pkgValidator.WriteValidationErrorResponse(w, http.StatusNotImplemented, enum.InternalServerError, "Path parameter validation not implemented.", nil)
-				return
-
-			default:
-				// Invalid source configuration
-				pkgValidator.WriteValidationErrorResponse(w, http.StatusInternalServerError, enum.InternalServerError, "Internal server error: Invalid validation source.", nil)
-				return
-			}
-
-			// Perform validation
-			validationErr := pkgValidator.Validate.Struct(dto)
-			if validationErr != nil {
-				// Check if it's validation errors
-				var ve validator.ValidationErrors
-				if ok := errors.As(validationErr, &ve); ok {
-					// Format errors and send response
-					formattedErrors := pkgValidator.FormatValidationErrors(ve)
-					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Validation failed", formattedErrors)
-					return
-				} else {
-					// Handle unexpected error during validation itself
-					pkgValidator.WriteValidationErrorResponse(w, http.StatusInternalServerError, enum.InternalServerError, "Error during validation process.", nil)
-					log.Fatalf("Unexpected validation error: %v\n", validationErr)
-					return
-				}
-			}
-
-			// Validation successful! Store the validated DTO in the context.
-			ctx := context.WithValue(r.Context(), types.ValidatedDTOKey, dto)
-
-			// Call the next handler with the updated context.
-			next.ServeHTTP(w, r.WithContext(ctx))
-		})
-	}
-}
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	appError "github.com/fazamuttaqien/calendly/pkg/app-error"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	pkgValidator "github.com/fazamuttaqien/calendly/pkg/validator"
+	"github.com/fazamuttaqien/calendly/types"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+)
+
+// WithValidation creates middleware to validate request data against a struct (DTO).
+// T is the type of the struct to validate against.
+// source indicates where to find the data ("body", "query", "params").
+// A DTO validated by an inner route replaces one stored by an outer route.
+func WithValidation[T any](source string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Create a zero instance of the target struct type T
+			var dto T // dto := new(T) works too but T is often cleaner
+
+			var err error
+			switch source {
+			case pkgValidator.SourceBody:
+				// Decode JSON body
+				if r.Body == nil {
+					err = fmt.Errorf("request body is empty")
+				} else {
+					decoder := json.NewDecoder(r.Body)
+					// decoder.DisallowUnknownFields() // Optional: Be strict about fields
+					// An empty body decodes to the zero DTO, required fields are still caught by validation
+					if decodeErr := decoder.Decode(&dto); decodeErr != nil && !errors.Is(decodeErr, io.EOF) {
+						err = fmt.Errorf("failed to decode request body: %w", decodeErr)
+					}
+					// NOTE: Consider closing r.Body if necessary, though Decode might handle it.
+				}
+				// The body was cut off by MaxBodySizeMiddleware
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					appError.WriteError(w, appError.NewAppError(enum.RequestTooLarge, "Request body too large", err))
+					return
+				}
+				if err != nil {
+					// Use your error handling mechanism, here we write response directly
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Invalid request body.", nil)
+					return
+				}
+
+			case pkgValidator.SourceQuery:
+				// Map query string values onto fields tagged with `query:"..."`
+				query := r.URL.Query()
+				if bindErr := bindTaggedValues(&dto, "query", func(name string) []string { return query[name] }); bindErr != nil {
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Invalid query parameters.", []pkgValidator.ValidationErrorDetail{
+						{Field: bindErr.Field, Message: bindErr.Error()},
+					})
+					return
+				}
+
+			case pkgValidator.SourceParams:
+				// Map chi URL params onto fields tagged with `param:"..."`
+				rctx := chi.RouteContext(r.Context())
+				if rctx == nil {
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusInternalServerError, enum.InternalServerError, "Internal server error: Route context not found.", nil)
+					return
+				}
+				params := rctx.URLParams
+				lookup := func(name string) []string {
+					for i := len(params.Keys) - 1; i >= 0; i-- {
+						if params.Keys[i] == name {
+							return []string{params.Values[i]}
+						}
+					}
+					return nil
+				}
+				if bindErr := bindTaggedValues(&dto, "param", lookup); bindErr != nil {
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Invalid path parameters.", []pkgValidator.ValidationErrorDetail{
+						{Field: bindErr.Field, Message: bindErr.Error()},
+					})
+					return
+				}
+
+			default:
+				// Invalid source configuration
+				pkgValidator.WriteValidationErrorResponse(w, http.StatusInternalServerError, enum.InternalServerError, "Internal server error: Invalid validation source.", nil)
+				return
+			}
+
+			// Perform validation
+			validationErr := pkgValidator.Validate.Struct(dto)
+			if validationErr != nil {
+				// Check if it's validation errors
+				var ve validator.ValidationErrors
+				if ok := errors.As(validationErr, &ve); ok {
+					// Format errors and send response
+					formattedErrors := pkgValidator.FormatValidationErrors(ve)
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusBadRequest, enum.ValidationError, "Validation failed", formattedErrors)
+					return
+				} else {
+					// Handle unexpected error during validation itself
+					pkgValidator.WriteValidationErrorResponse(w, http.StatusInternalServerError, enum.InternalServerError, "Error during validation process.", nil)
+					// Only the DTO type is logged, its values may hold emails or passwords
+					slog.Error("Unexpected validation error", "dto", fmt.Sprintf("%T", dto), "error", validationErr)
+					return
+				}
+			}
+
+			// Validation successful! Store the validated DTO in the context.
+			ctx := context.WithValue(r.Context(), types.ValidatedDTOKey, dto)
+
+			// Call the next handler with the updated context.
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bindError describes a value that could not be converted to its target field type.
+type bindError struct {
+	Field string
+	Err   error
+}
+
+func (e *bindError) Error() string {
+	return fmt.Sprintf("invalid value for '%s': %v", e.Field, e.Err)
+}
+
+// bindTaggedValues populates the fields of the struct pointed to by dst using the
+// values returned by lookup for each field's `tag` struct tag. Fields without the
+// tag or without a value are left untouched so defaults and `omitempty` still apply.
+func bindTaggedValues(dst any, tag string, lookup func(name string) []string) *bindError {
+	rv := reflect.ValueOf(dst).Elem()
+	if rv.Kind() != reflect.Struct {
+		return &bindError{Field: tag, Err: fmt.Errorf("target must be a struct, got %s", rv.Kind())}
+	}
+
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		values := lookup(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setFieldValue(rv.Field(i), values); err != nil {
+			return &bindError{Field: name, Err: err}
+		}
+	}
+
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// setFieldValue converts raw string values into the field's type.
+// Slices accept repeated values as well as a single comma-separated value.
+func setFieldValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		if err := setFieldValue(ptr.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+
+	if field.Kind() == reflect.Slice {
+		raw := values
+		if len(values) == 1 {
+			raw = strings.Split(values[0], ",")
+		}
+		slice := reflect.MakeSlice(field.Type(), 0, len(raw))
+		for _, v := range raw {
+			item := reflect.New(field.Type().Elem()).Elem()
+			if err := setFieldValue(item, []string{strings.TrimSpace(v)}); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, item)
+		}
+		field.Set(slice)
+		return nil
+	}
+
+	value := values[0]
+
+	if field.Type() == timeType {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package appError
+
+import "github.com/fazamuttaqien/calendly/pkg/enum"
+
+// Sentinel errors for matching with errors.Is, e.g. errors.Is(err, appError.ErrNotFound).
+// They only carry a Code and are never meant to be written as a response themselves.
+var (
+	ErrNotFound             = &AppError{Code: enum.ResourceNotFound}
+	ErrUnauthorized         = &AppError{Code: enum.AccessUnauthorized}
+	ErrUnauthenticated      = &AppError{Code: enum.AuthUnauthorizedAccess}
+	ErrInvalidToken         = &AppError{Code: enum.AuthInvalidToken}
+	ErrEmailAlreadyExists   = &AppError{Code: enum.AuthEmailAlreadyExists}
+	ErrTooManyAttempts      = &AppError{Code: enum.AuthTooManyAttempts}
+	ErrValidation           = &AppError{Code: enum.ValidationError}
+	ErrBadRequest           = &AppError{Code: enum.BadRequest}
+	ErrRequestTooLarge      = &AppError{Code: enum.RequestTooLarge}
+	ErrUnsupportedMediaType = &AppError{Code: enum.UnsupportedMediaType}
+	ErrInternal             = &AppError{Code: enum.InternalServerError}
+	ErrServiceUnavailable   = &AppError{Code: enum.ServiceUnavailable}
+)
+
+// Is reports whether target is an *AppError with the same Code, so any
+// AppError matches the sentinel of its code regardless of message or cause.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && e.Code == t.Code
+}
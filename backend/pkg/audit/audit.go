@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Actions recorded in audit_logs.action
+const (
+	ActionUserRegister          = "user.register"
+	ActionUserLogin             = "user.login"
+	ActionUserGoogleLogin       = "user.google_login"
+	ActionUserLogout            = "user.logout"
+	ActionUserPasswordChange    = "user.password_change"
+	ActionUserRoleChange        = "user.role_change"
+	ActionUserDelete            = "user.delete"
+	ActionIntegrationConnect    = "integration.connect"
+	ActionIntegrationDisconnect = "integration.disconnect"
+	ActionMeetingCreate         = "meeting.create"
+	ActionMeetingCancel         = "meeting.cancel"
+	ActionMeetingNoShow         = "meeting.no_show"
+)
+
+// Entity types recorded in audit_logs.entity_type
+const (
+	EntityUser        = "user"
+	EntityIntegration = "integration"
+	EntityMeeting     = "meeting"
+)
+
+// AuditEntry is a single audit record. An empty UserID is stored as NULL.
+type AuditEntry struct {
+	UserID     string
+	Action     string
+	EntityType string
+	EntityID   string
+	IPAddress  string
+	UserAgent  string
+	Metadata   map[string]any
+}
+
+// Log inserts entry into audit_logs.
+func Log(ctx context.Context, db *sqlx.DB, entry AuditEntry) error {
+	metadata := []byte("{}")
+	if len(entry.Metadata) > 0 {
+		var err error
+		metadata, err = json.Marshal(entry.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit metadata: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO audit_logs (user_id, action, entity_type, entity_id, ip_address, user_agent, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW());
+	`
+	_, err := db.ExecContext(ctx, query,
+		nullString(entry.UserID), entry.Action, entry.EntityType, entry.EntityID,
+		entry.IPAddress, entry.UserAgent, metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert audit log: %w", err)
+	}
+	return nil
+}
+
+// FromRequest returns the client IP and user agent for an entry. The IP comes from RemoteAddr,
+// which the RealIP middleware has already resolved from the headers of trusted proxies.
+func FromRequest(r *http.Request) (ipAddress, userAgent string) {
+	ipAddress = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ipAddress = host
+	}
+	return ipAddress, r.UserAgent()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
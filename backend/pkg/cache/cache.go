@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache is a key-value store whose entries may expire.
+type Cache[K comparable, V any] interface {
+	// Get returns the value stored for key, false if it is missing or expired.
+	Get(key K) (V, bool)
+	// Set stores value for key, replacing any previous entry.
+	Set(key K, value V)
+	// Invalidate removes the entry for key, if any.
+	Invalidate(key K)
+}
+
+type entry[V any] struct {
+	value     V
+	expiredAt time.Time
+}
+
+// TTLCache is an in-process Cache where every entry lives for the same duration.
+// Expired entries are dropped lazily when they are read.
+type TTLCache[K comparable, V any] struct {
+	items sync.Map
+	ttl   time.Duration
+}
+
+// NewTTL returns an empty TTLCache whose entries expire after ttl.
+func NewTTL[K comparable, V any](ttl time.Duration) *TTLCache[K, V] {
+	return &TTLCache[K, V]{ttl: ttl}
+}
+
+func (c *TTLCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	item, ok := c.items.Load(key)
+	if !ok {
+		return zero, false
+	}
+
+	e := item.(*entry[V])
+	if time.Now().After(e.expiredAt) {
+		c.items.CompareAndDelete(key, item) // Keeps an entry stored concurrently by Set
+		return zero, false
+	}
+	return e.value, true
+}
+
+func (c *TTLCache[K, V]) Set(key K, value V) {
+	c.items.Store(key, &entry[V]{value: value, expiredAt: time.Now().Add(c.ttl)})
+}
+
+func (c *TTLCache[K, V]) Invalidate(key K) {
+	c.items.Delete(key)
+}
@@ -0,0 +1,146 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureThreshold is the number of consecutive failures that opens the breaker
+	DefaultFailureThreshold = 5
+	// DefaultResetTimeout is how long an open breaker rejects calls before letting a trial call through
+	DefaultResetTimeout = 30 * time.Second
+)
+
+// ErrOpen is returned by Execute without calling the function while the breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a CircuitBreaker.
+type State string
+
+const (
+	// StateClosed lets every call through and counts consecutive failures
+	StateClosed State = "closed"
+	// StateOpen rejects every call until the reset timeout has passed
+	StateOpen State = "open"
+	// StateHalfOpen lets a single trial call through, its outcome closes or reopens the breaker
+	StateHalfOpen State = "half-open"
+)
+
+// CircuitBreaker stops calling a failing dependency for a while, so callers fail fast
+// instead of each waiting for a timeout. It is safe for concurrent use.
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	resetTimeout     time.Duration
+	// isFailure decides which errors count as failures, every error counts when nil
+	isFailure func(error) bool
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool // A half-open trial call is in flight
+}
+
+// New returns a closed breaker. Non-positive thresholds and timeouts fall back to the defaults.
+// isFailure filters the errors counted against the breaker, e.g. to ignore client errors; nil counts all.
+func New(name string, failureThreshold int, resetTimeout time.Duration, isFailure func(error) bool) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if resetTimeout <= 0 {
+		resetTimeout = DefaultResetTimeout
+	}
+
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		resetTimeout:     resetTimeout,
+		isFailure:        isFailure,
+		state:            StateClosed,
+	}
+}
+
+// Execute calls fn unless the breaker is open, in which case ErrOpen is returned.
+// The error of fn is returned unchanged after being recorded.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if !cb.allow() {
+		return ErrOpen
+	}
+
+	err := fn()
+	cb.record(err != nil && (cb.isFailure == nil || cb.isFailure(err)))
+	return err
+}
+
+// State returns the current state of the breaker.
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen && time.Since(cb.openedAt) >= cb.resetTimeout {
+		return StateHalfOpen
+	}
+	return cb.state
+}
+
+// allow reports whether a call may go through, moving an expired open breaker to half-open.
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateOpen {
+		if time.Since(cb.openedAt) < cb.resetTimeout {
+			return false
+		}
+		cb.state = StateHalfOpen
+		cb.probing = false
+	}
+
+	if cb.state == StateHalfOpen {
+		if cb.probing {
+			return false
+		}
+		cb.probing = true
+	}
+
+	return true
+}
+
+// record updates the breaker with the outcome of a call.
+func (cb *CircuitBreaker) record(failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == StateHalfOpen {
+		cb.probing = false
+		if failed {
+			cb.open()
+			return
+		}
+		slog.Info("Circuit breaker closed", "name", cb.name)
+		cb.state = StateClosed
+		cb.failures = 0
+		return
+	}
+
+	if !failed {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.state == StateClosed && cb.failures >= cb.failureThreshold {
+		cb.open()
+	}
+}
+
+// open trips the breaker, the caller must hold mu.
+func (cb *CircuitBreaker) open() {
+	slog.Warn("Circuit breaker opened", "name", cb.name, "failures", cb.failures, "resetTimeout", cb.resetTimeout)
+	cb.state = StateOpen
+	cb.openedAt = time.Now()
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// KeySize is the key length required for AES-256.
+const KeySize = 32
+
+// Sizes of the nonce and tag cipher.NewGCM uses
+const (
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+var ErrCiphertextTooShort = errors.New("ciphertext too short")
+
+// Encrypt seals plaintext with AES-256-GCM. The random nonce is prepended
+// to the returned ciphertext.
+func Encrypt(plaintext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt.
+func Decrypt(ciphertext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrCiphertextTooShort
+	}
+
+	nonce, sealed := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return plaintext, nil
+}
+
+// EncryptString encrypts plaintext with the ENCRYPTION_KEY and returns it
+// base64-encoded, ready to be stored in a TEXT column.
+func EncryptString(plaintext string) (string, error) {
+	key, err := KeyFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := Encrypt([]byte(plaintext), key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptString reverses EncryptString.
+func DecryptString(encoded string) (string, error) {
+	key, err := KeyFromEnv()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	plaintext, err := Decrypt(ciphertext, key)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether s has the shape of an EncryptString result: base64 long enough
+// to hold a nonce and an authentication tag. OAuth tokens stored before encryption was introduced
+// are not valid standard base64 (they contain '.', '-' or '_'), so they are told apart by this.
+func IsEncrypted(s string) bool {
+	ciphertext, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && len(ciphertext) >= gcmNonceSize+gcmTagSize
+}
+
+// KeyFromEnv reads ENCRYPTION_KEY, a hex-encoded 32-byte key.
+func KeyFromEnv() ([]byte, error) {
+	encoded := os.Getenv("ENCRYPTION_KEY")
+	if encoded == "" {
+		return nil, errors.New("ENCRYPTION_KEY is not set")
+	}
+
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEY must be hex-encoded: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("ENCRYPTION_KEY must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size: want %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
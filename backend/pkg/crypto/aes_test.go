@@ -0,0 +1,126 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+)
+
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func TestEncryptDecryptRoundtrip(t *testing.T) {
+	key := newTestKey(t)
+
+	for _, plaintext := range [][]byte{[]byte("ya29.a0AfH6SMBx-token_value"), {}} {
+		ciphertext, err := Encrypt(plaintext, key)
+		if err != nil {
+			t.Fatalf("Encrypt() error = %v", err)
+		}
+		if len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext) {
+			t.Fatalf("ciphertext contains the plaintext")
+		}
+
+		decrypted, err := Decrypt(ciphertext, key)
+		if err != nil {
+			t.Fatalf("Decrypt() error = %v", err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("Decrypt() = %q, want %q", decrypted, plaintext)
+		}
+	}
+}
+
+func TestEncryptUsesFreshNonce(t *testing.T) {
+	key := newTestKey(t)
+
+	first, err := Encrypt([]byte("token"), key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	second, err := Encrypt([]byte("token"), key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Equal(first, second) {
+		t.Fatal("encrypting twice gave the same ciphertext")
+	}
+}
+
+func TestDecryptRejectsTamperedOrForeignCiphertext(t *testing.T) {
+	key := newTestKey(t)
+
+	ciphertext, err := Encrypt([]byte("token"), key)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := Decrypt(tampered, key); err == nil {
+		t.Error("Decrypt() of tampered ciphertext succeeded")
+	}
+
+	if _, err := Decrypt(ciphertext, newTestKey(t)); err == nil {
+		t.Error("Decrypt() with another key succeeded")
+	}
+
+	if _, err := Decrypt(ciphertext[:4], key); err != ErrCiphertextTooShort {
+		t.Errorf("Decrypt() of short ciphertext error = %v, want %v", err, ErrCiphertextTooShort)
+	}
+}
+
+func TestEncryptStringRoundtrip(t *testing.T) {
+	t.Setenv("ENCRYPTION_KEY", hex.EncodeToString(newTestKey(t)))
+
+	encrypted, err := EncryptString("1//0gLegacyRefresh-token_value")
+	if err != nil {
+		t.Fatalf("EncryptString() error = %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Errorf("IsEncrypted(%q) = false, want true", encrypted)
+	}
+
+	decrypted, err := DecryptString(encrypted)
+	if err != nil {
+		t.Fatalf("DecryptString() error = %v", err)
+	}
+	if decrypted != "1//0gLegacyRefresh-token_value" {
+		t.Errorf("DecryptString() = %q, want the original token", decrypted)
+	}
+}
+
+func TestIsEncryptedRejectsLegacyPlaintextTokens(t *testing.T) {
+	for _, token := range []string{
+		"ya29.a0AfH6SMBx-token_value",                  // Google access token
+		"1//0gLegacyRefresh-token_value",               // Google refresh token
+		"eyJhbGciOiJIUzUxMiJ9.eyJzdWIiOiIxMjMifQ.c2ln", // Zoom JWT
+		"dG9rZW4=", // Valid base64 but too short to hold a nonce and tag
+	} {
+		if IsEncrypted(token) {
+			t.Errorf("IsEncrypted(%q) = true, want false", token)
+		}
+	}
+}
+
+func TestKeyFromEnvValidatesKey(t *testing.T) {
+	for name, value := range map[string]string{
+		"unset":   "",
+		"not hex": "zz",
+		"short":   hex.EncodeToString(make([]byte, 16)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("ENCRYPTION_KEY", value)
+			if _, err := KeyFromEnv(); err == nil {
+				t.Error("KeyFromEnv() succeeded, want an error")
+			}
+		})
+	}
+}
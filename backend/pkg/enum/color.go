@@ -0,0 +1,43 @@
+package enum
+
+// --- EventColor ---
+type EventColor string
+
+const (
+	ColorRed    EventColor = "RED"
+	ColorOrange EventColor = "ORANGE"
+	ColorYellow EventColor = "YELLOW"
+	ColorGreen  EventColor = "GREEN"
+	ColorBlue   EventColor = "BLUE"
+	ColorPurple EventColor = "PURPLE"
+	ColorPink   EventColor = "PINK"
+	ColorGray   EventColor = "GRAY"
+)
+
+// DefaultEventColor is used for events created without a color.
+const DefaultEventColor = ColorBlue
+
+func AllEventColors() []EventColor {
+	return []EventColor{
+		ColorRed,
+		ColorOrange,
+		ColorYellow,
+		ColorGreen,
+		ColorBlue,
+		ColorPurple,
+		ColorPink,
+		ColorGray,
+	}
+}
+
+func (e EventColor) String() string { return string(e) }
+func EventColorValues() []string {
+	vals := AllEventColors()
+	strs := make([]string, len(vals))
+
+	for i, v := range vals {
+		strs[i] = v.String()
+	}
+
+	return strs
+}
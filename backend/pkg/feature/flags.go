@@ -0,0 +1,44 @@
+package feature
+
+import (
+	"strings"
+
+	"github.com/fazamuttaqien/calendly/helper"
+)
+
+// FeatureFlag names a feature that can be switched on or off per deployment
+// with the FEATURE_<NAME> environment variable, e.g. FEATURE_ZOOM_INTEGRATION=true.
+type FeatureFlag string
+
+const (
+	FeatureZoomIntegration    FeatureFlag = "zoom_integration"
+	FeatureOutlookIntegration FeatureFlag = "outlook_integration"
+	FeatureWaitlist           FeatureFlag = "waitlist"
+)
+
+// defaults keeps shipped features on and unfinished integrations off until enabled explicitly
+var defaults = map[FeatureFlag]bool{
+	FeatureZoomIntegration:    false,
+	FeatureOutlookIntegration: false,
+	FeatureWaitlist:           true,
+}
+
+// EnvKey returns the environment variable that controls flag.
+func (f FeatureFlag) EnvKey() string {
+	return "FEATURE_" + strings.ToUpper(string(f))
+}
+
+// IsEnabled reports whether flag is on. The environment is read on every call,
+// unknown flags are off.
+func IsEnabled(flag FeatureFlag) bool {
+	return helper.GetEnvBool(flag.EnvKey(), defaults[flag])
+}
+
+// All returns the state of every known flag, keyed by flag name.
+func All() map[FeatureFlag]bool {
+	flags := make(map[FeatureFlag]bool, len(defaults))
+	for flag := range defaults {
+		flags[flag] = IsEnabled(flag)
+	}
+	return flags
+}
@@ -0,0 +1,98 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+const (
+	// ContentType is the MIME type used when serving encoded calendars
+	ContentType = "application/calendar"
+
+	dateTimeLayout = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// Encode writes meetings as an RFC 5545 VCALENDAR containing one VEVENT per meeting.
+// Meetings are expected to carry the joined event fields (EventTitle, EventDescription).
+func Encode(meetings []model.Meeting) ([]byte, error) {
+	var buf bytes.Buffer
+	now := time.Now().UTC().Format(dateTimeLayout)
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:-//Calendly//Meetings Export//EN")
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+
+	for _, meeting := range meetings {
+		if meeting.ID == "" {
+			return nil, fmt.Errorf("ical: meeting without ID cannot be encoded")
+		}
+
+		status := "CONFIRMED"
+		if meeting.Status == enum.Cancelled {
+			status = "CANCELLED"
+		}
+
+		summary := meeting.EventTitle
+		if meeting.GuestName != "" {
+			summary = fmt.Sprintf("%s with %s", meeting.EventTitle, meeting.GuestName)
+		}
+
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+meeting.ID)
+		writeLine(&buf, "DTSTAMP:"+now)
+		writeLine(&buf, "DTSTART:"+meeting.StartTime.UTC().Format(dateTimeLayout))
+		writeLine(&buf, "DTEND:"+meeting.EndTime.UTC().Format(dateTimeLayout))
+		writeLine(&buf, "SUMMARY:"+escapeText(summary))
+		if meeting.EventDescription != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(meeting.EventDescription))
+		}
+		if meeting.MeetLink != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(meeting.MeetLink))
+		}
+		writeLine(&buf, "STATUS:"+status)
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes(), nil
+}
+
+// escapeText escapes TEXT property values as described in RFC 5545 section 3.3.11
+func escapeText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets and terminates them with CRLF
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		// Never split inside a multi-byte UTF-8 sequence
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
@@ -1,43 +1,102 @@
-package jwt
-
-import (
-	"fmt"
-	"os"
-	"time"
-
-	"github.com/golang-jwt/jwt/v5"
-)
-
-// JWTCustomClaims defines the claims for the JWT.
-type JWTCustomClaims struct {
-	UserID string `json:"userId"`
-	jwt.RegisteredClaims
-}
-
-// SignJwtToken creates a new JWT for the given user ID.
-func SignJwtToken(userID string) (tokenString string, expirestAt time.Time, err error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	expirestAt = expirationTime
-
-	claims := &JWTCustomClaims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "calendly-app",
-		},
-	}
-
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token with secret
-	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
-
-	signedString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
-	}
-
-	return signedString, expirestAt, nil
-}
+package jwt
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// JWTCustomClaims defines the claims for the JWT.
+type JWTCustomClaims struct {
+	UserID string        `json:"userId"`
+	Role   enum.UserRole `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IsAdmin reports whether the token was issued to an admin.
+func (c *JWTCustomClaims) IsAdmin() bool {
+	return c.Role == enum.RoleAdmin
+}
+
+// SignJwtToken creates a new JWT for the given user ID and role.
+func SignJwtToken(userID string, role enum.UserRole) (tokenString string, expirestAt time.Time, err error) {
+	expirationTime := time.Now().Add(24 * time.Hour)
+	expirestAt = expirationTime
+
+	claims := &JWTCustomClaims{
+		UserID: userID,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // JTI, used to revoke the token on logout
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "calendly-app",
+		},
+	}
+
+	// Create token with claims
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	// Sign token with secret
+	jwtSecret := []byte(os.Getenv("JWT_SECRET"))
+
+	signedString, err := token.SignedString(jwtSecret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return signedString, expirestAt, nil
+}
+
+// EmailVerificationAudience keeps verification tokens from being accepted as access tokens and vice versa.
+const EmailVerificationAudience = "email-verification"
+
+// SignVerificationToken creates a short-lived token used to verify the email address of a user.
+func SignVerificationToken(userID string) (string, error) {
+	now := time.Now()
+
+	claims := &JWTCustomClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{EmailVerificationAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "calendly-app",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	signedString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign verification token: %w", err)
+	}
+
+	return signedString, nil
+}
+
+// ParseVerificationToken validates a token created by SignVerificationToken and returns its user ID.
+func ParseVerificationToken(tokenString string) (string, error) {
+	claims := &JWTCustomClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(EmailVerificationAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("invalid verification token: %w", err)
+	}
+
+	if claims.UserID == "" {
+		return "", fmt.Errorf("verification token missing user information")
+	}
+
+	return claims.UserID, nil
+}
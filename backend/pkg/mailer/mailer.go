@@ -0,0 +1,395 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/pkg/pii"
+)
+
+// Mailer sends transactional emails to users.
+type Mailer interface {
+	SendVerificationEmail(to, token string) error
+	// SendWaitlistPromotion tells a waitlisted guest that a spot opened on eventTitle.
+	SendWaitlistPromotion(to, guestName, eventTitle, bookingURL string) error
+	// SendBookingConfirmation tells the guest and the host about a new meeting,
+	// attachment is an .ics calendar file added to both emails when not empty.
+	SendBookingConfirmation(guestEmail, hostEmail string, meeting model.Meeting, event model.Event, attachment []byte) error
+	// SendCancellationNotification tells the guest and the host that a meeting was cancelled.
+	// The meeting is expected to carry the joined EventTitle.
+	SendCancellationNotification(guestEmail, hostEmail string, meeting model.Meeting) error
+	// SendDailyDigest lists a host's meetings of the next day, shown in the host's timezone.
+	SendDailyDigest(to, hostName, timezone string, meetings []model.Meeting) error
+	// SendOrganizationInvite invites to to join orgName, the invitation token is part of the link.
+	SendOrganizationInvite(to, orgName, inviterName, token string) error
+}
+
+// NewFromEnv returns an SMTP mailer when SMTP_HOST is configured,
+// otherwise a no-op mailer that only logs what would have been sent.
+func NewFromEnv() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return NoopMailer{LogTokens: helper.GetEnvBool("MAILER_LOG_TOKENS", false)}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	verifyURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verifyURL == "" {
+		verifyURL = "http://localhost:8000/api/v1/auth/verify-email"
+	}
+
+	cancelURL := os.Getenv("MEETING_CANCELLATION_URL")
+	if cancelURL == "" {
+		cancelURL = "http://localhost:5173/meeting/cancel"
+	}
+
+	inviteURL := os.Getenv("ORG_INVITATION_URL")
+	if inviteURL == "" {
+		inviteURL = "http://localhost:5173/orgs/invitations/accept"
+	}
+
+	return &SMTPMailer{
+		Host:      host,
+		Port:      port,
+		Username:  os.Getenv("SMTP_USERNAME"),
+		Password:  os.Getenv("SMTP_PASSWORD"),
+		From:      os.Getenv("SMTP_FROM"),
+		VerifyURL: verifyURL,
+		CancelURL: cancelURL,
+		InviteURL: inviteURL,
+		Timeout:   helper.GetEnvSeconds("SMTP_TIMEOUT_SECONDS", DefaultSMTPTimeout),
+	}
+}
+
+// DefaultSMTPTimeout bounds the delivery of one email, from dialing the server to QUIT
+const DefaultSMTPTimeout = 30 * time.Second
+
+// SMTPMailer sends emails through an SMTP server using PLAIN auth.
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	// VerifyURL is the verify-email endpoint, the token is appended as ?token=
+	VerifyURL string
+	// CancelURL is the page guests cancel a meeting on, the cancellation token is appended as ?token=
+	CancelURL string
+	// InviteURL is the page organization invitations are accepted on, the invitation token is appended as ?token=
+	InviteURL string
+	// Timeout bounds the delivery of one email, DefaultSMTPTimeout when zero
+	Timeout time.Duration
+}
+
+func (m *SMTPMailer) SendVerificationEmail(to, token string) error {
+	link := m.VerifyURL + "?token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf(
+		"Welcome to Calendly!\r\n\r\nPlease verify your email address by opening the link below:\r\n\r\n%s\r\n\r\nThe link expires in 24 hours.\r\n",
+		link,
+	)
+
+	return m.send(to, "Verify your email address", body, nil)
+}
+
+func (m *SMTPMailer) SendWaitlistPromotion(to, guestName, eventTitle, bookingURL string) error {
+	body := fmt.Sprintf(
+		"Hi %s,\r\n\r\nA spot just opened up for \"%s\". Book it before someone else does:\r\n\r\n%s\r\n",
+		guestName, eventTitle, bookingURL,
+	)
+
+	return m.send(to, "A spot opened up for "+eventTitle, body, nil)
+}
+
+func (m *SMTPMailer) SendBookingConfirmation(guestEmail, hostEmail string, meeting model.Meeting, event model.Event, attachment []byte) error {
+	// The guest sees the time in the timezone they booked in
+	loc, err := time.LoadLocation(meeting.Timezone)
+	if err != nil || meeting.Timezone == "" {
+		loc = time.UTC
+	}
+	when := formatMeetingTime(meeting, loc)
+
+	location := ""
+	if meeting.MeetLink != "" {
+		location = fmt.Sprintf("Join: %s\r\n", meeting.MeetLink)
+	}
+
+	cancelLink := m.CancelURL + "?token=" + url.QueryEscape(meeting.CancellationToken)
+	guestBody := fmt.Sprintf(
+		"Hi %s,\r\n\r\nYour meeting \"%s\" is confirmed.\r\n\r\nWhen: %s\r\n%s\r\nNeed to cancel? Open the link below:\r\n\r\n%s\r\n",
+		meeting.GuestName, event.Title, when, location, cancelLink,
+	)
+	if err := m.send(guestEmail, "Confirmed: "+event.Title, guestBody, attachment); err != nil {
+		return err
+	}
+
+	if hostEmail == "" {
+		return nil
+	}
+
+	// Hosts manage their meetings from the dashboard, the guest's cancellation link stays private
+	hostBody := fmt.Sprintf(
+		"%s (%s) booked \"%s\".\r\n\r\nWhen: %s\r\n%s",
+		meeting.GuestName, meeting.GuestEmail, event.Title, formatMeetingTime(meeting, time.UTC), location,
+	)
+	return m.send(hostEmail, "New booking: "+event.Title, hostBody, attachment)
+}
+
+func (m *SMTPMailer) SendCancellationNotification(guestEmail, hostEmail string, meeting model.Meeting) error {
+	loc, err := time.LoadLocation(meeting.Timezone)
+	if err != nil || meeting.Timezone == "" {
+		loc = time.UTC
+	}
+
+	guestBody := fmt.Sprintf(
+		"Hi %s,\r\n\r\nYour meeting \"%s\" has been cancelled.\r\n\r\nWhen: %s\r\n",
+		meeting.GuestName, meeting.EventTitle, formatMeetingTime(meeting, loc),
+	)
+	if err := m.send(guestEmail, "Cancelled: "+meeting.EventTitle, guestBody, nil); err != nil {
+		return err
+	}
+
+	if hostEmail == "" {
+		return nil
+	}
+
+	hostBody := fmt.Sprintf(
+		"The meeting \"%s\" with %s (%s) has been cancelled.\r\n\r\nWhen: %s\r\n",
+		meeting.EventTitle, meeting.GuestName, meeting.GuestEmail, formatMeetingTime(meeting, time.UTC),
+	)
+	return m.send(hostEmail, "Cancelled: "+meeting.EventTitle, hostBody, nil)
+}
+
+func (m *SMTPMailer) SendDailyDigest(to, hostName, timezone string, meetings []model.Meeting) error {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		loc = time.UTC
+	}
+
+	var list strings.Builder
+	for _, meeting := range meetings {
+		fmt.Fprintf(&list, "- %s with %s: %s\r\n", meeting.EventTitle, meeting.GuestName, formatMeetingTime(meeting, loc))
+		if meeting.MeetLink != "" {
+			fmt.Fprintf(&list, "  Join: %s\r\n", meeting.MeetLink)
+		}
+	}
+
+	body := fmt.Sprintf(
+		"Hi %s,\r\n\r\nYou have %d meeting(s) tomorrow:\r\n\r\n%s",
+		hostName, len(meetings), list.String(),
+	)
+
+	return m.send(to, "Your meetings for tomorrow", body, nil)
+}
+
+func (m *SMTPMailer) SendOrganizationInvite(to, orgName, inviterName, token string) error {
+	link := m.InviteURL + "?token=" + url.QueryEscape(token)
+
+	body := fmt.Sprintf(
+		"Hi,\r\n\r\n%s invited you to join %s on Calendly. Accept the invitation by opening the link below:\r\n\r\n%s\r\n\r\nThe invitation expires in 7 days.\r\n",
+		inviterName, orgName, link,
+	)
+
+	return m.send(to, "You're invited to join "+orgName, body, nil)
+}
+
+// formatMeetingTime renders the start and end of meeting in loc, e.g. "Monday, 2 January 2006 15:04 - 15:30 (Europe/Berlin)"
+func formatMeetingTime(meeting model.Meeting, loc *time.Location) string {
+	return fmt.Sprintf("%s - %s (%s)",
+		meeting.StartTime.In(loc).Format("Monday, 2 January 2006 15:04"),
+		meeting.EndTime.In(loc).Format("15:04"),
+		loc.String(),
+	)
+}
+
+func (m *SMTPMailer) send(to, subject, body string, attachment []byte) error {
+	// Guard against header injection through user supplied addresses
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+	// Subjects may contain user supplied titles
+	subject = strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+
+	headers := []string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+	}
+
+	var msg string
+	if len(attachment) == 0 {
+		msg = strings.Join(append(headers, "Content-Type: text/plain; charset=UTF-8", "", body), "\r\n")
+	} else {
+		content, contentType, err := withCalendarAttachment(body, attachment)
+		if err != nil {
+			return fmt.Errorf("failed to build email to %s: %w", to, err)
+		}
+		msg = strings.Join(append(headers, "Content-Type: "+contentType, "", content), "\r\n")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	if err := m.sendMail(auth, to, []byte(msg)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+
+	return nil
+}
+
+// sendMail delivers msg like smtp.SendMail, but under a deadline so a slow or
+// unresponsive server can't hold up the caller indefinitely.
+func (m *SMTPMailer) sendMail(auth smtp.Auth, to string, msg []byte) error {
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSMTPTimeout
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(m.Host, m.Port), timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// withCalendarAttachment builds a multipart/mixed body holding the text and an invite.ics
+// attachment, it returns the body and its Content-Type header value.
+func withCalendarAttachment(body string, ics []byte) (string, string, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=UTF-8"},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := textPart.Write([]byte(body)); err != nil {
+		return "", "", err
+	}
+
+	icsPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/calendar; charset=UTF-8; method=PUBLISH; name="invite.ics"`},
+		"Content-Disposition":       {`attachment; filename="invite.ics"`},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	// Wrap the base64 at 76 characters as required for MIME bodies
+	encoded := base64.StdEncoding.EncodeToString(ics)
+	for len(encoded) > 76 {
+		if _, err := icsPart.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return "", "", err
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := icsPart.Write([]byte(encoded + "\r\n")); err != nil {
+		return "", "", err
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", "", err
+	}
+
+	return buf.String(), "multipart/mixed; boundary=" + mw.Boundary(), nil
+}
+
+// NoopMailer discards emails, used when SMTP is not configured (e.g. local development).
+type NoopMailer struct {
+	// LogTokens logs verification tokens so local sign-ups can be verified without a mail server.
+	// Development only, anyone reading the logs could verify any address.
+	LogTokens bool
+}
+
+func (m NoopMailer) SendVerificationEmail(to, token string) error {
+	if m.LogTokens {
+		log.Printf("Mailer: SMTP not configured, skipping verification email to %s (token: %s)\n", pii.MaskEmail(to), token)
+		return nil
+	}
+	log.Printf("Mailer: SMTP not configured, skipping verification email to %s\n", pii.MaskEmail(to))
+	return nil
+}
+
+func (NoopMailer) SendWaitlistPromotion(to, guestName, eventTitle, bookingURL string) error {
+	log.Printf("Mailer: SMTP not configured, skipping waitlist promotion email to %s (event: %s)\n", pii.MaskEmail(to), eventTitle)
+	return nil
+}
+
+func (NoopMailer) SendBookingConfirmation(guestEmail, hostEmail string, meeting model.Meeting, event model.Event, attachment []byte) error {
+	log.Printf("Mailer: SMTP not configured, skipping booking confirmation to %s and %s (meeting: %s)\n", pii.MaskEmail(guestEmail), pii.MaskEmail(hostEmail), meeting.ID)
+	return nil
+}
+
+func (NoopMailer) SendCancellationNotification(guestEmail, hostEmail string, meeting model.Meeting) error {
+	log.Printf("Mailer: SMTP not configured, skipping cancellation notification to %s and %s (meeting: %s)\n", pii.MaskEmail(guestEmail), pii.MaskEmail(hostEmail), meeting.ID)
+	return nil
+}
+
+func (NoopMailer) SendDailyDigest(to, hostName, timezone string, meetings []model.Meeting) error {
+	log.Printf("Mailer: SMTP not configured, skipping daily digest to %s (%d meetings)\n", pii.MaskEmail(to), len(meetings))
+	return nil
+}
+
+func (NoopMailer) SendOrganizationInvite(to, orgName, inviterName, token string) error {
+	log.Printf("Mailer: SMTP not configured, skipping organization invite to %s (organization: %s)\n", pii.MaskEmail(to), orgName)
+	return nil
+}
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// slackMessage is the body of a Slack incoming webhook request.
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// SendSlackMessage posts message to a Slack incoming webhook URL.
+// The text may use Slack's mrkdwn formatting.
+func SendSlackMessage(webhookURL, message string) error {
+	body, err := json.Marshal(slackMessage{Text: message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	resp, err := httpClient.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned status code %d", resp.StatusCode)
+	}
+	return nil
+}
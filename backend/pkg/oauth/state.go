@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+// StateTTL bounds how long a user has to complete the provider consent screen.
+const StateTTL = 10 * time.Minute
+
+var (
+	ErrInvalidState = errors.New("invalid oauth state")
+	ErrExpiredState = errors.New("oauth state has expired")
+)
+
+// OAuthState represents the data carried in the OAuth state parameter.
+type OAuthState struct {
+	UserID    string                  `json:"userId"`
+	AppType   enum.IntegrationAppType `json:"appType"`
+	CSRFToken string                  `json:"csrfToken"`
+	ExpiresAt int64                   `json:"expiresAt"`
+}
+
+// SignState fills in a random CSRF token and expiry, then returns
+// base64url(JSON) + "." + base64url(HMAC-SHA256) signed with JWT_SECRET.
+func SignState(state OAuthState) (string, error) {
+	csrf := make([]byte, 32)
+	if _, err := rand.Read(csrf); err != nil {
+		return "", fmt.Errorf("failed to generate csrf token: %w", err)
+	}
+	state.CSRFToken = base64.RawURLEncoding.EncodeToString(csrf)
+	state.ExpiresAt = time.Now().Add(StateTTL).Unix()
+
+	jsonData, err := json.Marshal(state)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(jsonData)
+	return payload + "." + sign(payload), nil
+}
+
+// VerifyAndDecodeState checks the signature and expiry of a state produced by
+// SignState and returns the decoded data.
+func VerifyAndDecodeState(signedState string) (OAuthState, error) {
+	payload, signature, found := strings.Cut(signedState, ".")
+	if !found || payload == "" || signature == "" {
+		return OAuthState{}, ErrInvalidState
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(payload))) {
+		return OAuthState{}, ErrInvalidState
+	}
+
+	jsonData, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return OAuthState{}, fmt.Errorf("failed to decode base64 state: %w", err)
+	}
+
+	var state OAuthState
+	if err := json.Unmarshal(jsonData, &state); err != nil {
+		return OAuthState{}, fmt.Errorf("failed to unmarshal state JSON: %w", err)
+	}
+
+	if state.CSRFToken == "" {
+		return OAuthState{}, ErrInvalidState
+	}
+	if time.Now().Unix() > state.ExpiresAt {
+		return state, ErrExpiredState
+	}
+
+	return state, nil
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package pii
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// mask replaces everything after the first character of s, so logs keep a hint without the value.
+func mask(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return ""
+	}
+	return string(r) + "***"
+}
+
+// MaskEmail hides the local part of an email address, e.g. "guest@example.com" becomes "g***@example.com".
+// The domain is kept as it helps telling providers apart when debugging delivery.
+func MaskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return mask(email)
+	}
+	return mask(local) + "@" + domain
+}
+
+// MaskName hides every word of a name but its first letter, e.g. "John Doe" becomes "J*** D***".
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = mask(word)
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,76 @@
+package pubsub
+
+import "sync"
+
+// subscriberBuffer is how many payloads a slow subscriber may fall behind before new ones are dropped
+const subscriberBuffer = 8
+
+// topics maps an event key to its *topic
+var topics sync.Map
+
+// topic holds the subscribers of one event key.
+type topic struct {
+	mu          sync.Mutex
+	subscribers map[<-chan []byte]chan []byte
+}
+
+// Publish sends payload to every current subscriber of eventKey without blocking.
+// Subscribers whose buffer is full miss the payload, publishers never wait on a slow reader.
+func Publish(eventKey string, payload []byte) {
+	value, ok := topics.Load(eventKey)
+	if !ok {
+		return
+	}
+	t := value.(*topic)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ch := range t.subscribers {
+		select {
+		case ch <- payload:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the payloads published to eventKey from now on.
+// Callers must Unsubscribe once they stop reading.
+func Subscribe(eventKey string) <-chan []byte {
+	ch := make(chan []byte, subscriberBuffer)
+	for {
+		value, _ := topics.LoadOrStore(eventKey, &topic{subscribers: make(map[<-chan []byte]chan []byte)})
+		t := value.(*topic)
+
+		t.mu.Lock()
+		// The topic may have been removed by the last Unsubscribe after it was loaded
+		if current, ok := topics.Load(eventKey); !ok || current != t {
+			t.mu.Unlock()
+			continue
+		}
+		t.subscribers[ch] = ch
+		t.mu.Unlock()
+		return ch
+	}
+}
+
+// Unsubscribe stops delivery to a channel returned by Subscribe and closes it.
+func Unsubscribe(eventKey string, sub <-chan []byte) {
+	value, ok := topics.Load(eventKey)
+	if !ok {
+		return
+	}
+	t := value.(*topic)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch, ok := t.subscribers[sub]
+	if !ok {
+		return
+	}
+	delete(t.subscribers, sub)
+	close(ch)
+
+	if len(t.subscribers) == 0 {
+		topics.Delete(eventKey)
+	}
+}
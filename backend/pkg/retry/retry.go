@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// backoffFactor multiplies the backoff after every failed attempt
+const backoffFactor = 2
+
+// Do calls fn up to maxAttempts times, retrying only while it returns a transient error.
+// The wait before each retry starts at backoff and doubles, with jitter so concurrent callers
+// do not retry in lockstep. Non-transient errors and the last attempt's error are returned as is.
+func Do(ctx context.Context, maxAttempts int, backoff time.Duration, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxAttempts || !IsTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(jitter(backoff)):
+		}
+		backoff *= backoffFactor
+	}
+}
+
+// IsTransient reports whether err is a Google API error worth retrying:
+// rate limiting (429) or a server side failure (500, 502, 503, 504).
+func IsTransient(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// jitter returns a random duration between d/2 and d.
+func jitter(d time.Duration) time.Duration {
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int64N(half+1))
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/helper"
+	"github.com/fazamuttaqien/calendly/internal/model"
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+	"github.com/fazamuttaqien/calendly/pkg/mailer"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Scheduler runs a job at a fixed interval until it is stopped.
+type Scheduler struct {
+	interval time.Duration
+	job      func(ctx context.Context)
+
+	ctx    context.Context // Cancelled by Stop, passed to the running job
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a scheduler running job every interval, the first run happens one interval after Start.
+func New(interval time.Duration, job func(ctx context.Context)) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		interval: interval,
+		job:      job,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the job on every tick and blocks until Stop is called.
+func (s *Scheduler) Start() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.job(s.ctx)
+		}
+	}
+}
+
+// Stop cancels a running job and waits for Start to return, or until ctx is done.
+// Start must have been called, otherwise Stop only returns once ctx is done.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HostDigest is a host due for a digest together with their meetings of the next day.
+type HostDigest struct {
+	UserID   string `db:"id"`
+	Name     string `db:"name"`
+	Email    string `db:"email"`
+	Timezone string `db:"timezone"`
+	Meetings []model.Meeting
+}
+
+// SendDailyDigests emails every host the meetings of their next day once their local time
+// reaches DAILY_DIGEST_HOUR (default 20). Hosts are claimed before sending, so each gets
+// at most one digest per local day even with several instances or frequent runs.
+func SendDailyDigests(ctx context.Context, db *sqlx.DB, mailer mailer.Mailer) error {
+	digestHour := helper.GetEnvInt("DAILY_DIGEST_HOUR", 20)
+
+	// 1. Claim hosts whose local digest time has passed today
+	var hosts []HostDigest
+	claimQuery := `
+		UPDATE users u
+		SET last_digest_sent_on = (NOW() AT TIME ZONE u.timezone)::DATE
+		WHERE u.deleted_at IS NULL
+			AND u.digest_opt_out = FALSE
+			AND EXTRACT(HOUR FROM NOW() AT TIME ZONE u.timezone) >= $1
+			AND (u.last_digest_sent_on IS NULL OR u.last_digest_sent_on < (NOW() AT TIME ZONE u.timezone)::DATE)
+		RETURNING u.id, u.name, u.email, u.timezone;
+	`
+	if err := db.SelectContext(ctx, &hosts, claimQuery, digestHour); err != nil {
+		return fmt.Errorf("failed to claim hosts for daily digest: %w", err)
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	userIDs := make(pq.StringArray, len(hosts))
+	for i, host := range hosts {
+		userIDs[i] = host.UserID
+	}
+
+	// 2. Scheduled meetings starting on the host's next local day
+	var meetings []model.Meeting
+	meetingsQuery := `
+		SELECT m.*, e.title AS event_title
+		FROM meetings m
+		JOIN events e ON m.event_id = e.id
+		JOIN users u ON m.user_id = u.id
+		WHERE m.user_id::TEXT = ANY($1)
+			AND m.status = $2
+			AND m.start_time >= (((NOW() AT TIME ZONE u.timezone)::DATE + 1)::TIMESTAMP AT TIME ZONE u.timezone)
+			AND m.start_time < (((NOW() AT TIME ZONE u.timezone)::DATE + 2)::TIMESTAMP AT TIME ZONE u.timezone)
+		ORDER BY m.start_time;
+	`
+	if err := db.SelectContext(ctx, &meetings, meetingsQuery, userIDs, enum.Scheduled); err != nil {
+		return fmt.Errorf("failed to fetch meetings for daily digest: %w", err)
+	}
+
+	byUser := make(map[string][]model.Meeting, len(hosts))
+	for _, meeting := range meetings {
+		byUser[meeting.UserID] = append(byUser[meeting.UserID], meeting)
+	}
+
+	// 3. Hosts with a free day get no email
+	for _, host := range hosts {
+		host.Meetings = byUser[host.UserID]
+		if len(host.Meetings) == 0 {
+			continue
+		}
+		if err := mailer.SendDailyDigest(host.Email, host.Name, host.Timezone, host.Meetings); err != nil {
+			slog.Warn("Failed to send daily digest", "userId", host.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
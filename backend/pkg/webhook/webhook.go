@@ -0,0 +1,181 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/fazamuttaqien/calendly/pkg/enum"
+)
+
+// Retry configuration parameters
+const (
+	maxAttempts    = 3
+	initialBackoff = 1 * time.Second
+	backoffFactor  = 2
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body.
+const SignatureHeader = "X-Calendly-Signature"
+
+// Payload is the JSON body sent to a registered webhook URL.
+type Payload struct {
+	Event     enum.WebhookEvent `json:"event"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Data      any               `json:"data"`
+}
+
+// ErrDisallowedURL is returned for webhook URLs that are not https or point into private networks.
+var ErrDisallowedURL = errors.New("webhook url is not allowed")
+
+// blockedPrefixes are ranges not covered by the netip helpers in isAllowedAddr
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, embeds IPv4 addresses
+}
+
+// Deliveries dial through safeDialer, so a host resolving to an internal address
+// after registration (e.g. DNS rebinding) is refused at connection time as well.
+var httpClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy:               nil, // A proxy would dial on our behalf and bypass the address check
+		DialContext:         safeDialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("%w: redirect to %s", ErrDisallowedURL, req.URL.Scheme)
+		}
+		if len(via) >= 3 {
+			return errors.New("stopped after 3 redirects")
+		}
+		return nil
+	},
+}
+
+var safeDialer = &net.Dialer{
+	Timeout: 5 * time.Second,
+	Control: func(network, address string, _ syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrDisallowedURL, err)
+		}
+		if !isAllowedAddr(addrPort.Addr()) {
+			return fmt.Errorf("%w: %s is an internal address", ErrDisallowedURL, addrPort.Addr())
+		}
+		return nil
+	},
+}
+
+// ValidateURL checks that rawURL is an https URL whose host only resolves to public addresses.
+func ValidateURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDisallowedURL, err)
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: only https urls are accepted", ErrDisallowedURL)
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrDisallowedURL)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: failed to resolve %s: %v", ErrDisallowedURL, host, err)
+	}
+	for _, addr := range addrs {
+		if !isAllowedAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to an internal address", ErrDisallowedURL, host)
+		}
+	}
+	return nil
+}
+
+// isAllowedAddr reports whether addr is a public unicast address. Loopback, private,
+// link-local (which holds the 169.254.169.254 cloud metadata endpoint) and similar ranges are refused.
+func isAllowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign computes the signature of body using the webhook secret.
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDelivery POSTs the payload to url, signed with secret.
+// Failed deliveries (network errors or non-2xx responses) are retried with exponential backoff.
+func WebhookDelivery(ctx context.Context, url, secret string, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	signature := Sign(body, secret)
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err = deliver(ctx, url, signature, body)
+		if err == nil {
+			return nil
+		}
+		if attempt == maxAttempts {
+			return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= backoffFactor
+	}
+}
+
+// deliver performs a single delivery attempt.
+func deliver(ctx context.Context, url, signature string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package zoom
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Endpoint is Zoom's OAuth 2.0 endpoint.
+var Endpoint = oauth2.Endpoint{
+	AuthURL:  "https://zoom.us/oauth/authorize",
+	TokenURL: "https://zoom.us/oauth/token",
+}
+
+const (
+	defaultBaseURL = "https://api.zoom.us/v2"
+	requestTimeout = 10 * time.Second
+)
+
+// ZoomClient calls the Zoom REST API on behalf of a user.
+type ZoomClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient returns a ZoomClient using httpClient, which must add the user's
+// OAuth token to requests (e.g. one returned by oauth2.Config.Client).
+func NewClient(httpClient *http.Client) *ZoomClient {
+	if httpClient.Timeout == 0 {
+		httpClient.Timeout = requestTimeout
+	}
+	return &ZoomClient{httpClient: httpClient, baseURL: defaultBaseURL}
+}
+
+// DeleteMeeting deletes a scheduled Zoom meeting.
+// A meeting that no longer exists is treated as deleted.
+func (c *ZoomClient) DeleteMeeting(meetingID string) error {
+	endpoint := fmt.Sprintf("%s/meetings/%s", c.baseURL, url.PathEscape(meetingID))
+	req, err := http.NewRequest(http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build zoom request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete zoom meeting %s: %w", meetingID, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent, http.StatusOK, http.StatusNotFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("zoom returned status %d deleting meeting %s: %s", resp.StatusCode, meetingID, body)
+	}
+}